@@ -0,0 +1,330 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// Analyzer finds the packages described by a package manager's manifest or
+// lockfile within a directory. Analyzers must work offline, using only the
+// files on disk.
+type Analyzer interface {
+	// Name returns a short, unique name for the analyzer, such as "npm".
+	Name() string
+
+	// Detect reports whether the manifest handled by the analyzer is
+	// present in dirRoot.
+	Detect(dirRoot string) bool
+
+	// Analyze reads the manifest in dirRoot and returns the packages and
+	// relationships it describes.
+	Analyze(dirRoot string) (*AnalyzerResult, error)
+}
+
+// AnalyzerResult holds the packages and relationships found by an Analyzer.
+type AnalyzerResult struct {
+	// Packages lists every package found, including the roots.
+	Packages []*spdx.Package
+
+	// Relationships lists the dependency relationships between Packages.
+	Relationships []*spdx.Relationship
+
+	// Roots lists the IDs of the packages representing the project whose
+	// manifest was analyzed, as opposed to its dependencies. When used
+	// through Build, the package for the directory will CONTAIN each root.
+	Roots []common.ElementID
+}
+
+var (
+	analyzersMu sync.RWMutex
+	analyzers   = map[string]Analyzer{}
+)
+
+// RegisterAnalyzer adds an Analyzer to the set returned by
+// RegisteredAnalyzers. It returns an error if an analyzer with the same
+// name has already been registered.
+func RegisterAnalyzer(a Analyzer) error {
+	analyzersMu.Lock()
+	defer analyzersMu.Unlock()
+
+	if _, ok := analyzers[a.Name()]; ok {
+		return fmt.Errorf("analyzer %s is already registered", a.Name())
+	}
+	analyzers[a.Name()] = a
+	return nil
+}
+
+// RegisteredAnalyzers returns all registered analyzers, sorted by name.
+// It includes the analyzers provided by this package for go.mod,
+// package-lock.json, requirements.txt, poetry.lock, Cargo.lock, pom.xml
+// and Gemfile.lock, as well as any registered by callers.
+func RegisteredAnalyzers() []Analyzer {
+	analyzersMu.RLock()
+	defer analyzersMu.RUnlock()
+
+	as := make([]Analyzer, 0, len(analyzers))
+	for _, a := range analyzers {
+		as = append(as, a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].Name() < as[j].Name() })
+	return as
+}
+
+// GetAnalyzer returns the registered analyzer with the given name, or nil
+// if there is none.
+func GetAnalyzer(name string) Analyzer {
+	analyzersMu.RLock()
+	defer analyzersMu.RUnlock()
+
+	return analyzers[name]
+}
+
+func init() {
+	for _, a := range []Analyzer{
+		&GoModAnalyzer{},
+		&NpmAnalyzer{},
+		&PipRequirementsAnalyzer{},
+		&PoetryAnalyzer{},
+		&CargoAnalyzer{},
+		&MavenAnalyzer{},
+		&BundlerAnalyzer{},
+	} {
+		if err := RegisterAnalyzer(a); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RunAnalyzers runs each of the given analyzers whose manifest is detected
+// in dirRoot, and combines their results. Packages with the same package
+// URL found by more than one analyzer are only included once, and packages
+// whose IDs collide with those of another analyzer are given unique IDs.
+func RunAnalyzers(dirRoot string, as []Analyzer) (*AnalyzerResult, error) {
+	b := newAnalyzerResultBuilder()
+	for _, a := range as {
+		if !a.Detect(dirRoot) {
+			continue
+		}
+		result, err := a.Analyze(dirRoot)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %s: %v", a.Name(), err)
+		}
+		for _, root := range b.addResult(result) {
+			if !b.rootSeen[root] {
+				b.rootSeen[root] = true
+				b.res.Roots = append(b.res.Roots, root)
+			}
+		}
+	}
+
+	return b.result(), nil
+}
+
+// fileExists reports whether p exists and is a regular file.
+func fileExists(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// newManagedPackage creates a Package for a component identified by a
+// package URL, with its license and copyright fields set to NOASSERTION.
+// Files are not analyzed for such packages.
+func newManagedPackage(purlType string, namespace string, name string, version string) *spdx.Package {
+	pkgName := name
+	if namespace != "" {
+		pkgName = namespace + "/" + name
+	}
+	return &spdx.Package{
+		PackageName:               pkgName,
+		PackageSPDXIdentifier:     makeElementID("Package", purlType, namespace, name, version),
+		PackageVersion:            version,
+		PackageDownloadLocation:   "NOASSERTION",
+		FilesAnalyzed:             false,
+		IsFilesAnalyzedTagPresent: true,
+		PackageLicenseConcluded:   "NOASSERTION",
+		PackageLicenseDeclared:    "NOASSERTION",
+		PackageCopyrightText:      "NOASSERTION",
+		PackageExternalReferences: []*spdx.PackageExternalReference{
+			makePurlExternalRef(makePurl(purlType, namespace, name, version)),
+		},
+	}
+}
+
+// makeDependencyRelationship returns a relationship recording that the
+// package dependent depends on the package dependency. relType should be
+// DEPENDS_ON or one of the *_DEPENDENCY_OF types; for the latter, the
+// dependency is the left-hand side of the relationship.
+func makeDependencyRelationship(dependent common.ElementID, dependency common.ElementID, relType string) *spdx.Relationship {
+	if relType == common.TypeRelationshipDependsOn {
+		return &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(dependent)),
+			RefB:         common.MakeDocElementID("", string(dependency)),
+			Relationship: relType,
+		}
+	}
+	return &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", string(dependency)),
+		RefB:         common.MakeDocElementID("", string(dependent)),
+		Relationship: relType,
+	}
+}
+
+// analyzerResultBuilder accumulates an AnalyzerResult, skipping packages and
+// relationships that have already been added. Packages are identified by
+// their package URL, and each is given an ID that is unique in the result.
+type analyzerResultBuilder struct {
+	res      *AnalyzerResult
+	gen      *utils.ElementIDGenerator
+	ids      map[string]common.ElementID
+	pkgs     map[common.ElementID]bool
+	rlns     map[string]bool
+	rootSeen map[common.ElementID]bool
+}
+
+func newAnalyzerResultBuilder() *analyzerResultBuilder {
+	return &analyzerResultBuilder{
+		res: &AnalyzerResult{
+			Packages:      []*spdx.Package{},
+			Relationships: []*spdx.Relationship{},
+		},
+		gen:      utils.NewElementIDGenerator(),
+		ids:      map[string]common.ElementID{},
+		pkgs:     map[common.ElementID]bool{},
+		rlns:     map[string]bool{},
+		rootSeen: map[common.ElementID]bool{},
+	}
+}
+
+// addRoot adds pkg and records it as one of the result's roots.
+func (b *analyzerResultBuilder) addRoot(pkg *spdx.Package) {
+	b.addPackage(pkg)
+	if !b.rootSeen[pkg.PackageSPDXIdentifier] {
+		b.rootSeen[pkg.PackageSPDXIdentifier] = true
+		b.res.Roots = append(b.res.Roots, pkg.PackageSPDXIdentifier)
+	}
+}
+
+// packageKey returns the key identifying pkg among the packages of a
+// result: its package URL or, if it has none, its ID.
+func packageKey(pkg *spdx.Package) string {
+	for _, ref := range pkg.PackageExternalReferences {
+		if ref != nil && ref.RefType == common.TypePackageManagerPURL {
+			return ref.Locator
+		}
+	}
+	return "SPDXRef-" + string(pkg.PackageSPDXIdentifier)
+}
+
+// packageID returns the ID of the package identified by pkg's package URL,
+// whether or not it has been added yet. The first time a package URL is
+// seen, it is given pkg's ID, with a numeric suffix if another package
+// already has that ID.
+func (b *analyzerResultBuilder) packageID(pkg *spdx.Package) common.ElementID {
+	key := packageKey(pkg)
+	id, ok := b.ids[key]
+	if !ok {
+		id = b.gen.Generate(string(pkg.PackageSPDXIdentifier))
+		b.ids[key] = id
+	}
+	return id
+}
+
+// reserve keeps id from being given to any package of the result.
+func (b *analyzerResultBuilder) reserve(id common.ElementID) {
+	b.gen.Reserve(id)
+}
+
+// addPackage adds pkg unless a package with the same package URL was
+// already added. It sets pkg's ID, and that of its annotations, to that
+// given by packageID, and returns it.
+func (b *analyzerResultBuilder) addPackage(pkg *spdx.Package) common.ElementID {
+	id := b.packageID(pkg)
+	for i := range pkg.Annotations {
+		if ref := &pkg.Annotations[i].AnnotationSPDXIdentifier; ref.ElementRefID == pkg.PackageSPDXIdentifier && ref.DocumentRefID == "" {
+			ref.ElementRefID = id
+		}
+	}
+	pkg.PackageSPDXIdentifier = id
+	if !b.pkgs[id] {
+		b.pkgs[id] = true
+		b.res.Packages = append(b.res.Packages, pkg)
+	}
+	return id
+}
+
+// addResult adds the packages and relationships of another result, whose
+// packages may be given new IDs as by addPackage, and returns its roots
+// with their new IDs.
+func (b *analyzerResultBuilder) addResult(res *AnalyzerResult) []common.ElementID {
+	renamed := map[common.ElementID]common.ElementID{}
+	for _, pkg := range res.Packages {
+		from := pkg.PackageSPDXIdentifier
+		renamed[from] = b.addPackage(pkg)
+	}
+	rename := func(ref common.DocElementID) common.DocElementID {
+		if to, ok := renamed[ref.ElementRefID]; ok && ref.DocumentRefID == "" && ref.SpecialID == "" {
+			ref.ElementRefID = to
+		}
+		return ref
+	}
+	for _, rln := range res.Relationships {
+		copied := *rln
+		copied.RefA, copied.RefB = rename(rln.RefA), rename(rln.RefB)
+		b.addRelationship(&copied)
+	}
+	roots := []common.ElementID{}
+	for _, root := range res.Roots {
+		if to, ok := renamed[root]; ok {
+			root = to
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// addRelationship adds rln unless an identical relationship (ignoring its
+// comment) was already added. Relationships from a package to itself are
+// dropped.
+func (b *analyzerResultBuilder) addRelationship(rln *spdx.Relationship) {
+	if rln.RefA == rln.RefB {
+		return
+	}
+	key := common.RenderDocElementID(rln.RefA) + " " + rln.Relationship + " " + common.RenderDocElementID(rln.RefB)
+	if b.rlns[key] {
+		return
+	}
+	b.rlns[key] = true
+	b.res.Relationships = append(b.res.Relationships, rln)
+}
+
+func (b *analyzerResultBuilder) result() *AnalyzerResult {
+	return b.res
+}
+
+// dirBaseName returns the final element of the absolute form of dirRoot,
+// for use as a project name when a manifest does not provide one.
+func dirBaseName(dirRoot string) string {
+	if abs, err := filepath.Abs(dirRoot); err == nil {
+		dirRoot = abs
+	}
+	return filepath.Base(dirRoot)
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
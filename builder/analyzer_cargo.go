@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// CargoAnalyzer is the Analyzer for Rust projects, reading Cargo.lock.
+type CargoAnalyzer struct{}
+
+const cratesIORegistry = "registry+https://github.com/rust-lang/crates.io-index"
+
+// Name returns "cargo".
+func (a *CargoAnalyzer) Name() string { return "cargo" }
+
+// Detect reports whether dirRoot contains a Cargo.lock file.
+func (a *CargoAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "Cargo.lock"))
+}
+
+// Analyze returns every locked crate, with the crates that have no source
+// (that is, the members of the local workspace) as the roots. Each crate
+// DEPENDS_ON the crates listed in its dependencies; Cargo.lock does not
+// distinguish development or build dependencies.
+func (a *CargoAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	data, err := os.ReadFile(filepath.Join(dirRoot, "Cargo.lock"))
+	if err != nil {
+		return nil, err
+	}
+	lock, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := newAnalyzerResultBuilder()
+	crates := tomlTables(lock, "package")
+
+	// index crates by name, and by name and version, for resolving
+	// dependency entries of the forms "name", "name version" and
+	// "name version (source)"
+	byName := map[string][]common.ElementID{}
+	byNameVersion := map[string]common.ElementID{}
+	ids := make([]common.ElementID, len(crates))
+	for i, crate := range crates {
+		name, version, source := tomlString(crate, "name"), tomlString(crate, "version"), tomlString(crate, "source")
+		pkg := newCargoPackage(name, version, source, tomlString(crate, "checksum"))
+		if source == "" {
+			pkg.PackageExternalReferences = nil
+			b.addRoot(pkg)
+		} else {
+			b.addPackage(pkg)
+		}
+		ids[i] = pkg.PackageSPDXIdentifier
+		byName[name] = append(byName[name], ids[i])
+		byNameVersion[name+" "+version] = ids[i]
+	}
+
+	for i, crate := range crates {
+		deps, _ := crate["dependencies"].([]interface{})
+		for _, dep := range deps {
+			s, ok := dep.(string)
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(s)
+			var to common.ElementID
+			switch {
+			case len(fields) >= 2:
+				to = byNameVersion[fields[0]+" "+fields[1]]
+			case len(fields) == 1 && len(byName[fields[0]]) == 1:
+				to = byName[fields[0]][0]
+			}
+			if to == "" {
+				return nil, fmt.Errorf("cannot resolve dependency %q of %s", s, tomlString(crate, "name"))
+			}
+			b.addRelationship(makeDependencyRelationship(ids[i], to, common.TypeRelationshipDependsOn))
+		}
+	}
+
+	return b.result(), nil
+}
+
+// newCargoPackage creates the Package for a crate, with a pkg:cargo package
+// URL. Crates from crates.io get their download URL, and crates from git
+// their repository and commit.
+func newCargoPackage(name string, version string, source string, checksum string) *spdx.Package {
+	pkg := newManagedPackage("cargo", "", name, version)
+	switch {
+	case source == cratesIORegistry:
+		pkg.PackageDownloadLocation = fmt.Sprintf("https://crates.io/api/v1/crates/%s/%s/download", name, version)
+	case strings.HasPrefix(source, "git+"):
+		repo, commit, _ := strings.Cut(source, "#")
+		repo, _, _ = strings.Cut(repo, "?")
+		if commit != "" {
+			repo += "@" + commit
+		}
+		pkg.PackageDownloadLocation = repo
+	}
+	if checksum != "" {
+		pkg.PackageChecksums = []common.Checksum{{Algorithm: common.SHA256, Value: checksum}}
+	}
+	return pkg
+}
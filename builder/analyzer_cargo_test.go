@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Cargo analyzer tests =====
+func TestCargoAnalyzerCanAnalyze(t *testing.T) {
+	a := &CargoAnalyzer{}
+	dirRoot := "../testdata/analyzers/cargo"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected Cargo.lock to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 5 {
+		t.Fatalf("expected %d, got %d", 5, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-cargo-cargo-project-0.1.0")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+
+	rand := findPackage(result.Packages, "Package-cargo-rand-0.8.5")
+	if rand == nil {
+		t.Fatalf("expected rand package, got nil")
+	}
+	if rand.PackageDownloadLocation != "https://crates.io/api/v1/crates/rand/0.8.5/download" {
+		t.Errorf("unexpected download location %v", rand.PackageDownloadLocation)
+	}
+	if got := rand.PackageExternalReferences[0].Locator; got != "pkg:cargo/rand@0.8.5" {
+		t.Errorf("expected %v, got %v", "pkg:cargo/rand@0.8.5", got)
+	}
+	if len(rand.PackageChecksums) != 1 || rand.PackageChecksums[0].Algorithm != common.SHA256 {
+		t.Errorf("expected SHA256 checksum, got %+v", rand.PackageChecksums)
+	}
+
+	mylib := findPackage(result.Packages, "Package-cargo-mylib-0.2.0")
+	if mylib == nil {
+		t.Fatalf("expected git package, got nil")
+	}
+	if mylib.PackageDownloadLocation != "git+https://github.com/example/mylib@0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("unexpected download location %v", mylib.PackageDownloadLocation)
+	}
+
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-cargo-rand-0.8.5") {
+		t.Errorf("expected root to depend on rand 0.8.5")
+	}
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-cargo-serde-1.0.188") {
+		t.Errorf("expected root to depend on serde")
+	}
+	if !hasRelationship(rlns, "Package-cargo-rand-0.8.5", common.TypeRelationshipDependsOn, "Package-cargo-rand-0.7.3") {
+		t.Errorf("expected rand 0.8.5 to depend on rand 0.7.3")
+	}
+}
+
+func TestCargoAnalyzerFailsForAmbiguousDependency(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "Cargo.lock", `
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = ["dup"]
+
+[[package]]
+name = "dup"
+version = "1.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "dup"
+version = "2.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`)
+	if _, err := (&CargoAnalyzer{}).Analyze(dir); err == nil {
+		t.Fatalf("expected non-nil error, got nil")
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// MavenAnalyzer is the Analyzer for Maven projects, reading pom.xml. As
+// pom.xml is not a lockfile, only the project's direct dependencies are
+// reported, and versions inherited from parent POMs that are not present
+// on disk cannot be resolved.
+type MavenAnalyzer struct{}
+
+type mavenPOM struct {
+	GroupID    string          `xml:"groupId"`
+	ArtifactID string          `xml:"artifactId"`
+	Version    string          `xml:"version"`
+	Name       string          `xml:"name"`
+	URL        string          `xml:"url"`
+	Parent     mavenParent     `xml:"parent"`
+	Properties mavenProperties `xml:"properties"`
+
+	DependencyManagement struct {
+		Dependencies []mavenDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+
+	Dependencies []mavenDependency `xml:"dependencies>dependency"`
+}
+
+type mavenParent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+// mavenProperties holds the arbitrary child elements of <properties>.
+type mavenProperties map[string]string
+
+// UnmarshalXML reads each child element of <properties> as a property.
+func (p *mavenProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = mavenProperties{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+var mavenPropertyRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Name returns "maven".
+func (a *MavenAnalyzer) Name() string { return "maven" }
+
+// Detect reports whether dirRoot contains a pom.xml file.
+func (a *MavenAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "pom.xml"))
+}
+
+// Analyze returns the project as the root package, together with its
+// declared dependencies. Dependency scopes are mapped to relationship types:
+// compile and system to DEPENDS_ON, runtime to RUNTIME_DEPENDENCY_OF,
+// provided to PROVIDED_DEPENDENCY_OF and test to TEST_DEPENDENCY_OF.
+// Optional dependencies are recorded as OPTIONAL_DEPENDENCY_OF.
+func (a *MavenAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	data, err := os.ReadFile(filepath.Join(dirRoot, "pom.xml"))
+	if err != nil {
+		return nil, err
+	}
+	pom := &mavenPOM{}
+	if err := xml.Unmarshal(data, pom); err != nil {
+		return nil, err
+	}
+
+	groupID := pom.GroupID
+	if groupID == "" {
+		groupID = pom.Parent.GroupID
+	}
+	version := pom.Version
+	if version == "" {
+		version = pom.Parent.Version
+	}
+	props := map[string]string{
+		"project.groupId":        groupID,
+		"project.artifactId":     pom.ArtifactID,
+		"project.version":        version,
+		"project.parent.groupId": pom.Parent.GroupID,
+		"project.parent.version": pom.Parent.Version,
+		"pom.groupId":            groupID,
+		"pom.version":            version,
+		"version":                version,
+	}
+	for k, v := range pom.Properties {
+		props[k] = v
+	}
+	resolve := func(s string) string {
+		// properties may refer to other properties, so expand a few times
+		for i := 0; i < 10 && strings.Contains(s, "${"); i++ {
+			s = mavenPropertyRegexp.ReplaceAllStringFunc(s, func(m string) string {
+				if v, ok := props[m[2:len(m)-1]]; ok {
+					return v
+				}
+				return m
+			})
+		}
+		return strings.TrimSpace(s)
+	}
+	// resolveVersion is resolve for versions, which are left empty if they
+	// refer to a property that is not defined in pom.xml
+	resolveVersion := func(s string) string {
+		if v := resolve(s); !strings.Contains(v, "${") {
+			return v
+		}
+		return ""
+	}
+
+	b := newAnalyzerResultBuilder()
+	rootPkg := newMavenPackage(resolve(groupID), resolve(pom.ArtifactID), resolveVersion(version))
+	if rootPkg.PackageVersion == "" {
+		rootPkg.PackageComment = "version not resolvable from pom.xml"
+	}
+	if pom.URL != "" {
+		rootPkg.PackageHomePage = resolve(pom.URL)
+	}
+	b.addRoot(rootPkg)
+
+	managed := map[string]mavenDependency{}
+	for _, dep := range pom.DependencyManagement.Dependencies {
+		managed[resolve(dep.GroupID)+":"+resolve(dep.ArtifactID)] = dep
+	}
+
+	for _, dep := range pom.Dependencies {
+		g, art := resolve(dep.GroupID), resolve(dep.ArtifactID)
+		v, scope := resolveVersion(dep.Version), resolve(dep.Scope)
+		if m, ok := managed[g+":"+art]; ok {
+			if v == "" {
+				v = resolveVersion(m.Version)
+			}
+			if scope == "" {
+				scope = resolve(m.Scope)
+			}
+		}
+
+		pkg := newMavenPackage(g, art, v)
+		if v == "" {
+			pkg.PackageComment = "version not resolvable from pom.xml"
+		}
+		id := b.addPackage(pkg)
+
+		relType := common.TypeRelationshipDependsOn
+		switch {
+		case resolve(dep.Optional) == "true":
+			relType = common.TypeRelationshipOptionalDependencyOf
+		case scope == "test":
+			relType = common.TypeRelationshipTestDependencyOf
+		case scope == "provided":
+			relType = common.TypeRelationshipProvidedDependencyOf
+		case scope == "runtime":
+			relType = common.TypeRelationshipRuntimeDependencyOf
+		}
+		b.addRelationship(makeDependencyRelationship(rootPkg.PackageSPDXIdentifier, id, relType))
+	}
+
+	return b.result(), nil
+}
+
+// newMavenPackage creates the Package for a Maven artifact, named
+// "groupId:artifactId", with a pkg:maven package URL.
+func newMavenPackage(groupID string, artifactID string, version string) *spdx.Package {
+	pkg := newManagedPackage("maven", groupID, artifactID, version)
+	pkg.PackageName = groupID + ":" + artifactID
+	return pkg
+}
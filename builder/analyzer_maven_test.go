@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Maven analyzer tests =====
+func TestMavenAnalyzerCanAnalyze(t *testing.T) {
+	a := &MavenAnalyzer{}
+	dirRoot := "../testdata/analyzers/maven"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected pom.xml to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 6 {
+		t.Fatalf("expected %d, got %d", 6, len(result.Packages))
+	}
+
+	// groupId and version are inherited from the parent
+	root := common.ElementID("Package-maven-com.example-maven-project-1.2.0")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+	rootPkg := findPackage(result.Packages, root)
+	if rootPkg.PackageName != "com.example:maven-project" {
+		t.Errorf("expected %v, got %v", "com.example:maven-project", rootPkg.PackageName)
+	}
+	if rootPkg.PackageHomePage != "https://example.com/maven-project" {
+		t.Errorf("unexpected home page %v", rootPkg.PackageHomePage)
+	}
+
+	lang := findPackage(result.Packages, "Package-maven-org.apache.commons-commons-lang3-3.13.0")
+	if lang == nil {
+		t.Fatalf("expected commons-lang3 with property-resolved version, got nil")
+	}
+	if got := lang.PackageExternalReferences[0].Locator; got != "pkg:maven/org.apache.commons/commons-lang3@3.13.0" {
+		t.Errorf("expected %v, got %v", "pkg:maven/org.apache.commons/commons-lang3@3.13.0", got)
+	}
+
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, lang.PackageSPDXIdentifier) {
+		t.Errorf("expected root to depend on commons-lang3")
+	}
+	if !hasRelationship(rlns, "Package-maven-org.junit.jupiter-junit-jupiter-5.10.0", common.TypeRelationshipTestDependencyOf, root) {
+		t.Errorf("expected junit to be a test dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-maven-javax.servlet-servlet-api-2.5", common.TypeRelationshipProvidedDependencyOf, root) {
+		t.Errorf("expected servlet-api to be a provided dependency of root")
+	}
+	// version and scope from dependencyManagement
+	if !hasRelationship(rlns, "Package-maven-org.slf4j-slf4j-api-2.0.9", common.TypeRelationshipRuntimeDependencyOf, root) {
+		t.Errorf("expected slf4j-api to be a runtime dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-maven-com.example-sibling-1.2.0", common.TypeRelationshipOptionalDependencyOf, root) {
+		t.Errorf("expected sibling to be an optional dependency of root")
+	}
+}
+
+func TestMavenAnalyzerLeavesUnresolvedVersionsEmpty(t *testing.T) {
+	dirRoot := t.TempDir()
+	writeTestFile(t, dirRoot, "pom.xml", `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>${revision}</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>lib</artifactId>
+      <version>${lib.version}</version>
+    </dependency>
+  </dependencies>
+</project>`)
+
+	result, err := (&MavenAnalyzer{}).Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 2 {
+		t.Fatalf("expected %d, got %d", 2, len(result.Packages))
+	}
+	for i, want := range []string{"pkg:maven/com.example/app", "pkg:maven/com.example/lib"} {
+		pkg := result.Packages[i]
+		if pkg.PackageVersion != "" || pkg.PackageComment != "version not resolvable from pom.xml" {
+			t.Errorf("expected unresolved version to be left empty, got %q, %q", pkg.PackageVersion, pkg.PackageComment)
+		}
+		if got := pkg.PackageExternalReferences[0].Locator; got != want {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		if strings.Contains(string(pkg.PackageSPDXIdentifier), "7B") {
+			t.Errorf("expected no property in ID, got %v", pkg.PackageSPDXIdentifier)
+		}
+	}
+}
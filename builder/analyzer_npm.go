@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// NpmAnalyzer is the Analyzer for npm projects, reading package-lock.json
+// (lockfile versions 1, 2 and 3).
+type NpmAnalyzer struct{}
+
+type npmLockfile struct {
+	Name            string                          `json:"name"`
+	Version         string                          `json:"version"`
+	LockfileVersion int                             `json:"lockfileVersion"`
+	Packages        map[string]*npmLockPackage      `json:"packages"`
+	Dependencies    map[string]*npmLockDependencyV1 `json:"dependencies"`
+}
+
+// npmLockPackage is an entry in the "packages" section of lockfile
+// versions 2 and 3, keyed by its path within node_modules.
+type npmLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Optional             bool              `json:"optional"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// npmLockDependencyV1 is an entry in the nested "dependencies" section of
+// lockfile version 1.
+type npmLockDependencyV1 struct {
+	Version      string                          `json:"version"`
+	Resolved     string                          `json:"resolved"`
+	Integrity    string                          `json:"integrity"`
+	Dev          bool                            `json:"dev"`
+	Optional     bool                            `json:"optional"`
+	Requires     map[string]string               `json:"requires"`
+	Dependencies map[string]*npmLockDependencyV1 `json:"dependencies"`
+}
+
+// Name returns "npm".
+func (a *NpmAnalyzer) Name() string { return "npm" }
+
+// Detect reports whether dirRoot contains a package-lock.json file.
+func (a *NpmAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "package-lock.json"))
+}
+
+// Analyze returns the project named in the lockfile as the root package,
+// together with every installed package. Dependencies are linked with
+// DEPENDS_ON, DEV_DEPENDENCY_OF or OPTIONAL_DEPENDENCY_OF relationships.
+func (a *NpmAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	b, err := os.ReadFile(filepath.Join(dirRoot, "package-lock.json"))
+	if err != nil {
+		return nil, err
+	}
+	lock := &npmLockfile{}
+	if err := json.Unmarshal(b, lock); err != nil {
+		return nil, err
+	}
+
+	rootName, rootVersion := lock.Name, lock.Version
+	if root := lock.Packages[""]; root != nil {
+		if root.Name != "" {
+			rootName = root.Name
+		}
+		if root.Version != "" {
+			rootVersion = root.Version
+		}
+	}
+	if rootName == "" {
+		rootName = dirBaseName(dirRoot)
+	}
+
+	b2 := newAnalyzerResultBuilder()
+	rootPkg := newNpmPackage(rootName, rootVersion, "", "")
+	b2.addRoot(rootPkg)
+
+	if lock.Packages != nil {
+		analyzeNpmPackages(lock, rootPkg.PackageSPDXIdentifier, b2)
+	} else {
+		analyzeNpmDependenciesV1(lock.Dependencies, nil, rootPkg.PackageSPDXIdentifier, b2)
+	}
+
+	return b2.result(), nil
+}
+
+// analyzeNpmPackages handles the "packages" section of lockfile versions
+// 2 and 3, resolving each dependency the way node does: by looking in the
+// nearest enclosing node_modules directory that contains it.
+func analyzeNpmPackages(lock *npmLockfile, rootID common.ElementID, b *analyzerResultBuilder) {
+	ids := map[string]common.ElementID{"": rootID}
+	paths := make([]string, 0, len(lock.Packages))
+	for p, lp := range lock.Packages {
+		if p == "" || lp.Link {
+			continue
+		}
+		name := lp.Name
+		if name == "" {
+			name = npmNameFromPath(p)
+		}
+		pkg := newNpmPackage(name, lp.Version, lp.Resolved, lp.Integrity)
+		ids[p] = b.addPackage(pkg)
+		paths = append(paths, p)
+	}
+	// links (e.g. workspace members) point at another entry in the section
+	for p, lp := range lock.Packages {
+		if lp.Link {
+			if id, ok := ids[filepath.ToSlash(lp.Resolved)]; ok {
+				ids[p] = id
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	paths = append([]string{""}, paths...)
+	for _, p := range paths {
+		lp := lock.Packages[p]
+		if lp == nil {
+			continue
+		}
+		from := ids[p]
+		for _, group := range []struct {
+			deps    map[string]string
+			relType string
+		}{
+			{lp.Dependencies, common.TypeRelationshipDependsOn},
+			{lp.PeerDependencies, common.TypeRelationshipDependsOn},
+			{lp.OptionalDependencies, common.TypeRelationshipOptionalDependencyOf},
+			{lp.DevDependencies, common.TypeRelationshipDevDependencyOf},
+		} {
+			for _, dep := range sortedKeys(group.deps) {
+				if to, ok := resolveNpmPath(p, dep, ids); ok {
+					b.addRelationship(makeDependencyRelationship(from, to, group.relType))
+				}
+			}
+		}
+	}
+}
+
+// resolveNpmPath finds the package ID that dependency name resolves to
+// from the package at path p.
+func resolveNpmPath(p string, name string, ids map[string]common.ElementID) (common.ElementID, bool) {
+	for {
+		candidate := "node_modules/" + name
+		if p != "" {
+			candidate = p + "/node_modules/" + name
+		}
+		if id, ok := ids[candidate]; ok {
+			return id, true
+		}
+		if p == "" {
+			return "", false
+		}
+		i := strings.LastIndex(p, "node_modules/")
+		if i <= 0 {
+			p = ""
+		} else {
+			p = strings.TrimSuffix(p[:i], "/")
+		}
+	}
+}
+
+// analyzeNpmDependenciesV1 walks the nested "dependencies" section of
+// lockfile version 1. scopes holds the enclosing dependency maps, innermost
+// last, used to resolve "requires" entries.
+func analyzeNpmDependenciesV1(deps map[string]*npmLockDependencyV1, scopes []map[string]*npmLockDependencyV1, rootID common.ElementID, b *analyzerResultBuilder) {
+	scopes = append(scopes, deps)
+	for _, name := range sortedKeys(deps) {
+		dep := deps[name]
+		pkg := newNpmPackage(name, dep.Version, dep.Resolved, dep.Integrity)
+		b.addPackage(pkg)
+
+		// top-level entries are the ones the root project pulled in
+		if len(scopes) == 1 {
+			relType := common.TypeRelationshipDependsOn
+			if dep.Dev {
+				relType = common.TypeRelationshipDevDependencyOf
+			} else if dep.Optional {
+				relType = common.TypeRelationshipOptionalDependencyOf
+			}
+			b.addRelationship(makeDependencyRelationship(rootID, pkg.PackageSPDXIdentifier, relType))
+		}
+
+		reqScopes := scopes
+		if len(dep.Dependencies) > 0 {
+			reqScopes = append(append([]map[string]*npmLockDependencyV1{}, scopes...), dep.Dependencies)
+		}
+		for _, req := range sortedKeys(dep.Requires) {
+			for i := len(reqScopes) - 1; i >= 0; i-- {
+				if target, ok := reqScopes[i][req]; ok {
+					to := b.packageID(newNpmPackage(req, target.Version, "", ""))
+					b.addRelationship(makeDependencyRelationship(pkg.PackageSPDXIdentifier, to, common.TypeRelationshipDependsOn))
+					break
+				}
+			}
+		}
+
+		if len(dep.Dependencies) > 0 {
+			analyzeNpmDependenciesV1(dep.Dependencies, scopes, rootID, b)
+		}
+	}
+}
+
+// newNpmPackage creates the Package for an npm package, which may have an
+// "@scope/" prefix, with its download location and integrity checksum.
+func newNpmPackage(name string, version string, resolved string, integrity string) *spdx.Package {
+	namespace := ""
+	if strings.HasPrefix(name, "@") {
+		if i := strings.Index(name, "/"); i > 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	}
+	pkg := newManagedPackage("npm", namespace, name, version)
+	if strings.HasPrefix(resolved, "http://") || strings.HasPrefix(resolved, "https://") || strings.HasPrefix(resolved, "git+") {
+		pkg.PackageDownloadLocation = resolved
+	}
+	pkg.PackageChecksums = parseSRIChecksums(integrity)
+	return pkg
+}
+
+// npmNameFromPath returns the package name for a lockfile path such as
+// "node_modules/a/node_modules/@s/b".
+func npmNameFromPath(p string) string {
+	i := strings.LastIndex(p, "node_modules/")
+	if i < 0 {
+		return p
+	}
+	return p[i+len("node_modules/"):]
+}
+
+// parseSRIChecksums converts a Subresource Integrity string, such as
+// "sha512-<base64>", into SPDX checksums.
+func parseSRIChecksums(integrity string) []common.Checksum {
+	var checksums []common.Checksum
+	for _, entry := range strings.Fields(integrity) {
+		alg, b64, ok := strings.Cut(entry, "-")
+		if !ok {
+			continue
+		}
+		var algorithm common.ChecksumAlgorithm
+		switch alg {
+		case "sha1":
+			algorithm = common.SHA1
+		case "sha256":
+			algorithm = common.SHA256
+		case "sha384":
+			algorithm = common.SHA384
+		case "sha512":
+			algorithm = common.SHA512
+		default:
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			continue
+		}
+		checksums = append(checksums, common.Checksum{Algorithm: algorithm, Value: hex.EncodeToString(b)})
+	}
+	return checksums
+}
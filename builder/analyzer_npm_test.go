@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== npm analyzer tests =====
+func TestNpmAnalyzerCanAnalyzeLockfileV3(t *testing.T) {
+	a := &NpmAnalyzer{}
+	dirRoot := "../testdata/analyzers/npm"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected package-lock.json to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 6 {
+		t.Fatalf("expected %d, got %d", 6, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-npm-npm-project-1.0.0")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+
+	scoped := findPackage(result.Packages, "Package-npm--scope-lib-2.1.0")
+	if scoped == nil {
+		t.Fatalf("expected scoped package, got nil")
+	}
+	if scoped.PackageName != "@scope/lib" {
+		t.Errorf("expected %v, got %v", "@scope/lib", scoped.PackageName)
+	}
+	if got := scoped.PackageExternalReferences[0].Locator; got != "pkg:npm/%40scope/lib@2.1.0" {
+		t.Errorf("expected %v, got %v", "pkg:npm/%40scope/lib@2.1.0", got)
+	}
+	if scoped.PackageDownloadLocation != "https://registry.npmjs.org/@scope/lib/-/lib-2.1.0.tgz" {
+		t.Errorf("unexpected download location %v", scoped.PackageDownloadLocation)
+	}
+	if len(scoped.PackageChecksums) != 1 || scoped.PackageChecksums[0].Algorithm != common.SHA512 {
+		t.Errorf("expected SHA512 checksum, got %+v", scoped.PackageChecksums)
+	}
+
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-npm-left-pad-1.3.0") {
+		t.Errorf("expected root to depend on left-pad 1.3.0")
+	}
+	if !hasRelationship(rlns, "Package-npm-mocha-10.2.0", common.TypeRelationshipDevDependencyOf, root) {
+		t.Errorf("expected mocha to be a dev dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-npm-fsevents-2.3.3", common.TypeRelationshipOptionalDependencyOf, root) {
+		t.Errorf("expected fsevents to be an optional dependency of root")
+	}
+	// nested node_modules takes precedence over the top level
+	if !hasRelationship(rlns, "Package-npm--scope-lib-2.1.0", common.TypeRelationshipDependsOn, "Package-npm-left-pad-1.0.0") {
+		t.Errorf("expected @scope/lib to depend on nested left-pad 1.0.0")
+	}
+	if hasRelationship(rlns, "Package-npm--scope-lib-2.1.0", common.TypeRelationshipDependsOn, "Package-npm-left-pad-1.3.0") {
+		t.Errorf("expected @scope/lib not to depend on top-level left-pad")
+	}
+}
+
+func TestNpmAnalyzerCanAnalyzeLockfileV1(t *testing.T) {
+	result, err := (&NpmAnalyzer{}).Analyze("../testdata/analyzers/npm-v1")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 4 {
+		t.Fatalf("expected %d, got %d", 4, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-npm-npm-v1-project-0.1.0")
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-npm-a-1.0.0") {
+		t.Errorf("expected root to depend on a")
+	}
+	if !hasRelationship(rlns, "Package-npm-b-1.0.0", common.TypeRelationshipDevDependencyOf, root) {
+		t.Errorf("expected b 1.0.0 to be a dev dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-npm-a-1.0.0", common.TypeRelationshipDependsOn, "Package-npm-b-2.0.0") {
+		t.Errorf("expected a to depend on nested b 2.0.0")
+	}
+}
+
+func TestNpmAnalyzerCanParseSRIChecksums(t *testing.T) {
+	checksums := parseSRIChecksums("sha1-J2RksqJkBDHw+JSi8f3E5qIf/Ec= md5-unsupported")
+	if len(checksums) != 1 {
+		t.Fatalf("expected %d, got %d", 1, len(checksums))
+	}
+	if checksums[0].Algorithm != common.SHA1 || checksums[0].Value != "276464b2a2640431f0f894a2f1fdc4e6a21ffc47" {
+		t.Errorf("unexpected checksum %+v", checksums[0])
+	}
+}
@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// PipRequirementsAnalyzer is the Analyzer for Python projects that list
+// their dependencies in requirements.txt. Requirements in
+// requirements-dev.txt or dev-requirements.txt, if present, are recorded
+// as development dependencies.
+type PipRequirementsAnalyzer struct{}
+
+// PoetryAnalyzer is the Analyzer for Python projects managed by Poetry,
+// reading poetry.lock and, if present, pyproject.toml.
+type PoetryAnalyzer struct{}
+
+// pipRequirement is a single requirement line from a requirements file.
+type pipRequirement struct {
+	Name      string
+	Version   string
+	Specifier string
+	URL       string
+	Checksums []common.Checksum
+}
+
+var (
+	pipRequirementRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+	pipNameRunRegexp     = regexp.MustCompile(`[-_.]+`)
+)
+
+// Name returns "pip".
+func (a *PipRequirementsAnalyzer) Name() string { return "pip" }
+
+// Detect reports whether dirRoot contains a requirements.txt file.
+func (a *PipRequirementsAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "requirements.txt"))
+}
+
+// Analyze returns a root package named after dirRoot, which DEPENDS_ON
+// each requirement. Only requirements pinned with "==" or "===" are given
+// a version.
+func (a *PipRequirementsAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	b := newAnalyzerResultBuilder()
+	rootPkg := newPythonProjectPackage(dirBaseName(dirRoot), "")
+	b.addRoot(rootPkg)
+
+	for _, f := range []struct {
+		name    string
+		relType string
+	}{
+		{"requirements.txt", common.TypeRelationshipDependsOn},
+		{"requirements-dev.txt", common.TypeRelationshipDevDependencyOf},
+		{"dev-requirements.txt", common.TypeRelationshipDevDependencyOf},
+	} {
+		reqs, err := parsePipRequirementsFile(filepath.Join(dirRoot, f.name))
+		if err != nil {
+			if os.IsNotExist(err) && f.name != "requirements.txt" {
+				continue
+			}
+			return nil, err
+		}
+		for _, req := range reqs {
+			pkg := newPypiPackage(req.Name, req.Version)
+			if req.URL != "" {
+				pkg.PackageDownloadLocation = req.URL
+			}
+			pkg.PackageChecksums = req.Checksums
+			if req.Specifier != "" {
+				pkg.PackageComment = "version specifier: " + req.Specifier
+			}
+			id := b.addPackage(pkg)
+			b.addRelationship(makeDependencyRelationship(rootPkg.PackageSPDXIdentifier, id, f.relType))
+		}
+	}
+
+	return b.result(), nil
+}
+
+// parsePipRequirementsFile reads the requirements from a pip requirements
+// file. Options such as -r, -c and --index-url are skipped, as are
+// editable and bare URL requirements, which have no package name.
+func parsePipRequirementsFile(p string) ([]*pipRequirement, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reqs := []*pipRequirement{}
+	scanner := bufio.NewScanner(f)
+	logical := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, `\`) {
+			logical += strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		line = logical + line
+		logical = ""
+
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if req := parsePipRequirement(line); req != nil {
+			reqs = append(reqs, req)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// parsePipRequirement parses a single PEP 508 requirement, possibly
+// followed by pip --hash options.
+func parsePipRequirement(line string) *pipRequirement {
+	req := &pipRequirement{}
+
+	// pull out hash options
+	fields := strings.Fields(line)
+	kept := []string{}
+	for _, field := range fields {
+		if h, ok := strings.CutPrefix(field, "--hash="); ok {
+			if checksum, ok := parseAlgorithmPrefixedHash(h); ok {
+				req.Checksums = append(req.Checksums, checksum)
+			}
+			continue
+		}
+		kept = append(kept, field)
+	}
+	line = strings.Join(kept, " ")
+
+	// drop environment markers
+	line, _, _ = strings.Cut(line, ";")
+
+	m := pipRequirementRegexp.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return nil
+	}
+	req.Name = m[1]
+	rest := strings.TrimSpace(m[3])
+
+	if url, ok := strings.CutPrefix(rest, "@"); ok {
+		req.URL = strings.TrimSpace(url)
+		return req
+	}
+	rest = strings.ReplaceAll(rest, " ", "")
+	if v, ok := strings.CutPrefix(rest, "==="); ok {
+		req.Version = v
+	} else if v, ok := strings.CutPrefix(rest, "=="); ok && !strings.ContainsAny(v, ",*") {
+		req.Version = v
+	} else {
+		req.Specifier = rest
+	}
+	return req
+}
+
+// Name returns "poetry".
+func (a *PoetryAnalyzer) Name() string { return "poetry" }
+
+// Detect reports whether dirRoot contains a poetry.lock file.
+func (a *PoetryAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "poetry.lock"))
+}
+
+// Analyze returns the project from pyproject.toml as the root package,
+// together with every locked package. The root's direct dependencies are
+// taken from pyproject.toml, with dependency groups named "test" or
+// "tests" recorded as TEST_DEPENDENCY_OF and other groups as
+// DEV_DEPENDENCY_OF. Without pyproject.toml, every locked package is
+// treated as a direct dependency.
+func (a *PoetryAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	lockData, err := os.ReadFile(filepath.Join(dirRoot, "poetry.lock"))
+	if err != nil {
+		return nil, err
+	}
+	lock, err := parseTOML(string(lockData))
+	if err != nil {
+		return nil, err
+	}
+
+	var project map[string]interface{}
+	if projectData, err := os.ReadFile(filepath.Join(dirRoot, "pyproject.toml")); err == nil {
+		if project, err = parseTOML(string(projectData)); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	b := newAnalyzerResultBuilder()
+
+	rootName, rootVersion := dirBaseName(dirRoot), ""
+	poetry := tomlTable(tomlTable(project, "tool"), "poetry")
+	pep621 := tomlTable(project, "project")
+	if name := tomlString(poetry, "name"); name != "" {
+		rootName, rootVersion = name, tomlString(poetry, "version")
+	} else if name := tomlString(pep621, "name"); name != "" {
+		rootName, rootVersion = name, tomlString(pep621, "version")
+	}
+	rootPkg := newPythonProjectPackage(rootName, rootVersion)
+	b.addRoot(rootPkg)
+
+	// locked packages, indexed by normalized name
+	locked := map[string][]common.ElementID{}
+	lockedPkgs := tomlTables(lock, "package")
+	for _, lp := range lockedPkgs {
+		name := tomlString(lp, "name")
+		pkg := newPypiPackage(name, tomlString(lp, "version"))
+		if source := tomlTable(lp, "source"); tomlString(source, "url") != "" {
+			pkg.PackageDownloadLocation = tomlString(source, "url")
+		}
+		for _, file := range tomlTables(lp, "files") {
+			fileName := tomlString(file, "file")
+			if !strings.HasSuffix(fileName, ".tar.gz") && !strings.HasSuffix(fileName, ".zip") {
+				continue
+			}
+			if checksum, ok := parseAlgorithmPrefixedHash(tomlString(file, "hash")); ok {
+				pkg.PackageFileName = fileName
+				pkg.PackageChecksums = []common.Checksum{checksum}
+			}
+		}
+		id := b.addPackage(pkg)
+		key := normalizePypiName(name)
+		locked[key] = append(locked[key], id)
+	}
+
+	// dependencies between locked packages
+	for _, lp := range lockedPkgs {
+		from := b.packageID(newPypiPackage(tomlString(lp, "name"), tomlString(lp, "version")))
+		deps := tomlTable(lp, "dependencies")
+		for _, dep := range sortedKeys(deps) {
+			for _, to := range locked[normalizePypiName(dep)] {
+				b.addRelationship(makeDependencyRelationship(from, to, common.TypeRelationshipDependsOn))
+			}
+		}
+	}
+
+	// direct dependencies of the root project
+	direct := poetryDirectDependencies(poetry, pep621)
+	if project == nil {
+		direct = map[string]string{}
+		for _, lp := range lockedPkgs {
+			relType := common.TypeRelationshipDependsOn
+			if tomlString(lp, "category") == "dev" {
+				relType = common.TypeRelationshipDevDependencyOf
+			} else if optional, _ := lp["optional"].(bool); optional {
+				relType = common.TypeRelationshipOptionalDependencyOf
+			}
+			direct[normalizePypiName(tomlString(lp, "name"))] = relType
+		}
+	}
+	for _, name := range sortedKeys(direct) {
+		for _, to := range locked[name] {
+			b.addRelationship(makeDependencyRelationship(rootPkg.PackageSPDXIdentifier, to, direct[name]))
+		}
+	}
+
+	return b.result(), nil
+}
+
+// poetryDirectDependencies returns the direct dependencies declared in
+// pyproject.toml, keyed by normalized name, with the relationship type to
+// use for each.
+func poetryDirectDependencies(poetry map[string]interface{}, pep621 map[string]interface{}) map[string]string {
+	direct := map[string]string{}
+	add := func(name string, relType string) {
+		name = normalizePypiName(name)
+		if name == "python" {
+			return
+		}
+		// a main dependency takes precedence over a dev or test one
+		if existing, ok := direct[name]; ok && existing == common.TypeRelationshipDependsOn {
+			return
+		}
+		direct[name] = relType
+	}
+
+	for name, spec := range tomlTable(poetry, "dependencies") {
+		relType := common.TypeRelationshipDependsOn
+		if t, ok := spec.(map[string]interface{}); ok {
+			if optional, _ := t["optional"].(bool); optional {
+				relType = common.TypeRelationshipOptionalDependencyOf
+			}
+		}
+		add(name, relType)
+	}
+	for name := range tomlTable(poetry, "dev-dependencies") {
+		add(name, common.TypeRelationshipDevDependencyOf)
+	}
+	groups := tomlTable(poetry, "group")
+	for _, group := range sortedKeys(groups) {
+		relType := common.TypeRelationshipDevDependencyOf
+		if group == "test" || group == "tests" {
+			relType = common.TypeRelationshipTestDependencyOf
+		}
+		g, _ := groups[group].(map[string]interface{})
+		for name := range tomlTable(g, "dependencies") {
+			add(name, relType)
+		}
+	}
+
+	deps, _ := pep621["dependencies"].([]interface{})
+	for _, dep := range deps {
+		if s, ok := dep.(string); ok {
+			if req := parsePipRequirement(s); req != nil {
+				add(req.Name, common.TypeRelationshipDependsOn)
+			}
+		}
+	}
+	optional := tomlTable(pep621, "optional-dependencies")
+	for _, extra := range sortedKeys(optional) {
+		deps, _ := optional[extra].([]interface{})
+		for _, dep := range deps {
+			if s, ok := dep.(string); ok {
+				if req := parsePipRequirement(s); req != nil {
+					add(req.Name, common.TypeRelationshipOptionalDependencyOf)
+				}
+			}
+		}
+	}
+
+	return direct
+}
+
+// newPypiPackage creates the Package for a Python distribution, with a
+// pkg:pypi package URL using the normalized name.
+func newPypiPackage(name string, version string) *spdx.Package {
+	pkg := newManagedPackage("pypi", "", normalizePypiName(name), version)
+	pkg.PackageName = name
+	return pkg
+}
+
+// newPythonProjectPackage creates the root Package for a Python project.
+// As the project may not be published, no package URL is included.
+func newPythonProjectPackage(name string, version string) *spdx.Package {
+	pkg := newPypiPackage(name, version)
+	pkg.PackageExternalReferences = nil
+	return pkg
+}
+
+// normalizePypiName normalizes a Python distribution name as described in
+// PEP 503: lowercased, with runs of "-", "_" and "." replaced by "-".
+func normalizePypiName(name string) string {
+	return pipNameRunRegexp.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// parseAlgorithmPrefixedHash parses a hash such as "sha256:<hex>" into an
+// SPDX checksum.
+func parseAlgorithmPrefixedHash(h string) (common.Checksum, bool) {
+	alg, value, ok := strings.Cut(h, ":")
+	if !ok || value == "" {
+		return common.Checksum{}, false
+	}
+	algorithms := map[string]common.ChecksumAlgorithm{
+		"md5":    common.MD5,
+		"sha1":   common.SHA1,
+		"sha224": common.SHA224,
+		"sha256": common.SHA256,
+		"sha384": common.SHA384,
+		"sha512": common.SHA512,
+	}
+	algorithm, ok := algorithms[strings.ToLower(alg)]
+	if !ok {
+		return common.Checksum{}, false
+	}
+	return common.Checksum{Algorithm: algorithm, Value: strings.ToLower(value)}, true
+}
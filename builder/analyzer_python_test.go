@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Python analyzer tests =====
+func TestPipRequirementsAnalyzerCanAnalyze(t *testing.T) {
+	a := &PipRequirementsAnalyzer{}
+	dirRoot := "../testdata/analyzers/pip"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected requirements.txt to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// root, four requirements and one dev requirement
+	if len(result.Packages) != 6 {
+		t.Fatalf("expected %d, got %d", 6, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-pypi-pip")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+	if len(findPackage(result.Packages, root).PackageExternalReferences) != 0 {
+		t.Errorf("expected no purl for project root")
+	}
+
+	requests := findPackage(result.Packages, "Package-pypi-requests-2.31.0")
+	if requests == nil {
+		t.Fatalf("expected requests package, got nil")
+	}
+	if got := requests.PackageExternalReferences[0].Locator; got != "pkg:pypi/requests@2.31.0" {
+		t.Errorf("expected %v, got %v", "pkg:pypi/requests@2.31.0", got)
+	}
+	if len(requests.PackageChecksums) != 1 || requests.PackageChecksums[0].Algorithm != common.SHA256 {
+		t.Errorf("expected SHA256 checksum from --hash, got %+v", requests.PackageChecksums)
+	}
+
+	django := findPackage(result.Packages, "Package-pypi-django")
+	if django == nil {
+		t.Fatalf("expected unpinned django package, got nil")
+	}
+	if django.PackageComment != "version specifier: >=4.2,<5" {
+		t.Errorf("unexpected comment %v", django.PackageComment)
+	}
+
+	zope := findPackage(result.Packages, "Package-pypi-zope-interface-6.0")
+	if zope == nil {
+		t.Fatalf("expected zope.interface package, got nil")
+	}
+	if zope.PackageName != "zope.interface" {
+		t.Errorf("expected %v, got %v", "zope.interface", zope.PackageName)
+	}
+
+	mypkg := findPackage(result.Packages, "Package-pypi-mypkg")
+	if mypkg == nil || mypkg.PackageDownloadLocation != "https://example.com/mypkg-1.0.tar.gz" {
+		t.Errorf("expected URL requirement download location, got %+v", mypkg)
+	}
+
+	if !hasRelationship(result.Relationships, root, common.TypeRelationshipDependsOn, "Package-pypi-requests-2.31.0") {
+		t.Errorf("expected root to depend on requests")
+	}
+	if !hasRelationship(result.Relationships, "Package-pypi-pytest-7.4.0", common.TypeRelationshipDevDependencyOf, root) {
+		t.Errorf("expected pytest to be a dev dependency of root")
+	}
+}
+
+func TestPoetryAnalyzerCanAnalyze(t *testing.T) {
+	a := &PoetryAnalyzer{}
+	dirRoot := "../testdata/analyzers/poetry"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected poetry.lock to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 7 {
+		t.Fatalf("expected %d, got %d", 7, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-pypi-poetry-project-0.3.0")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+
+	black := findPackage(result.Packages, "Package-pypi-black-23.7.0")
+	if black == nil {
+		t.Fatalf("expected black package, got nil")
+	}
+	if black.PackageFileName != "black-23.7.0.tar.gz" {
+		t.Errorf("expected sdist file name, got %v", black.PackageFileName)
+	}
+	if len(black.PackageChecksums) != 1 || black.PackageChecksums[0].Value != "022a582720b0d9480ed82576c920a8c1dde97cc38ff11d8d8859b3bd6ca9eedb" {
+		t.Errorf("expected sdist checksum, got %+v", black.PackageChecksums)
+	}
+
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-pypi-requests-2.31.0") {
+		t.Errorf("expected root to depend on requests")
+	}
+	if !hasRelationship(rlns, "Package-pypi-typing-extensions-4.7.1", common.TypeRelationshipOptionalDependencyOf, root) {
+		t.Errorf("expected typing-extensions to be an optional dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-pypi-pytest-7.4.0", common.TypeRelationshipTestDependencyOf, root) {
+		t.Errorf("expected pytest to be a test dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-pypi-black-23.7.0", common.TypeRelationshipDevDependencyOf, root) {
+		t.Errorf("expected black to be a dev dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-pypi-requests-2.31.0", common.TypeRelationshipDependsOn, "Package-pypi-urllib3-2.0.4") {
+		t.Errorf("expected requests to depend on urllib3")
+	}
+	// transitive dependencies are not direct dependencies of the root
+	if hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-pypi-urllib3-2.0.4") {
+		t.Errorf("expected urllib3 not to be a direct dependency of root")
+	}
+}
+
+func TestPythonCanParsePipRequirement(t *testing.T) {
+	req := parsePipRequirement("Foo_Bar[extra1,extra2] == 1.0 ; sys_platform == 'linux'")
+	if req == nil {
+		t.Fatalf("expected requirement, got nil")
+	}
+	if req.Name != "Foo_Bar" || req.Version != "1.0" {
+		t.Errorf("unexpected requirement %+v", req)
+	}
+	if got := normalizePypiName(req.Name); got != "foo-bar" {
+		t.Errorf("expected %v, got %v", "foo-bar", got)
+	}
+
+	req = parsePipRequirement("foo==1.*")
+	if req.Version != "" || req.Specifier != "==1.*" {
+		t.Errorf("expected wildcard to be kept as a specifier, got %+v", req)
+	}
+}
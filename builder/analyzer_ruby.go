@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// BundlerAnalyzer is the Analyzer for Ruby projects, reading Gemfile.lock
+// and, if present, the Gemfile's group declarations.
+type BundlerAnalyzer struct{}
+
+// bundlerSpec is a locked gem from the specs of a GEM, GIT or PATH section.
+type bundlerSpec struct {
+	Name     string
+	Version  string
+	Platform string
+	Source   string
+	Deps     []string
+}
+
+var (
+	bundlerSpecRegexp   = regexp.MustCompile(`^ {4}([^ (]+)(?: \(([^)]*)\))?$`)
+	bundlerDepRegexp    = regexp.MustCompile(`^ {6}([^ (]+)`)
+	bundlerDirectRegexp = regexp.MustCompile(`^ {2}([^ (!]+)`)
+	gemfileGroupRegexp  = regexp.MustCompile(`^\s*group\s+(.*?)\s+do\b`)
+	gemfileGemRegexp    = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["'](.*)$`)
+	gemfileGroupsOption = regexp.MustCompile(`groups?:\s*(\[[^\]]*\]|:\w+)`)
+)
+
+// Name returns "bundler".
+func (a *BundlerAnalyzer) Name() string { return "bundler" }
+
+// Detect reports whether dirRoot contains a Gemfile.lock file.
+func (a *BundlerAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "Gemfile.lock"))
+}
+
+// Analyze returns a root package named after dirRoot, together with every
+// locked gem. The gems listed under DEPENDENCIES are the root's direct
+// dependencies; those declared only in the Gemfile's :test group are
+// recorded as TEST_DEPENDENCY_OF, and those in a :development group as
+// DEV_DEPENDENCY_OF.
+func (a *BundlerAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	specs, direct, err := parseGemfileLock(filepath.Join(dirRoot, "Gemfile.lock"))
+	if err != nil {
+		return nil, err
+	}
+	groups, err := parseGemfileGroups(filepath.Join(dirRoot, "Gemfile"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	b := newAnalyzerResultBuilder()
+	rootPkg := newGemPackage(dirBaseName(dirRoot), "")
+	rootPkg.PackageExternalReferences = nil
+	b.addRoot(rootPkg)
+
+	byName := map[string][]common.ElementID{}
+	ids := make([]common.ElementID, len(specs))
+	for i, spec := range specs {
+		pkg := newGemPackage(spec.Name, spec.Version)
+		if spec.Platform != "" {
+			pkg.PackageComment = "platform: " + spec.Platform
+		}
+		if spec.Source != "" {
+			pkg.PackageDownloadLocation = spec.Source
+		}
+		ids[i] = b.addPackage(pkg)
+		byName[spec.Name] = append(byName[spec.Name], ids[i])
+	}
+
+	for i, spec := range specs {
+		for _, dep := range spec.Deps {
+			for _, to := range byName[dep] {
+				b.addRelationship(makeDependencyRelationship(ids[i], to, common.TypeRelationshipDependsOn))
+			}
+		}
+	}
+
+	for _, name := range direct {
+		relType := common.TypeRelationshipDependsOn
+		switch gs := groups[name]; {
+		case gs["development"] || gs["dev"]:
+			relType = common.TypeRelationshipDevDependencyOf
+		case gs["test"] && !gs["default"]:
+			relType = common.TypeRelationshipTestDependencyOf
+		}
+		for _, to := range byName[name] {
+			b.addRelationship(makeDependencyRelationship(rootPkg.PackageSPDXIdentifier, to, relType))
+		}
+	}
+
+	return b.result(), nil
+}
+
+// parseGemfileLock reads the locked gems from the GEM, GIT and PATH
+// sections of a Gemfile.lock, and the names listed under DEPENDENCIES.
+func parseGemfileLock(p string) ([]*bundlerSpec, []string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	specs := []*bundlerSpec{}
+	direct := []string{}
+	section, remote, revision := "", "", ""
+	var current *bundlerSpec
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			section, remote, revision, current = line, "", "", nil
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			trimmed := strings.TrimSpace(line)
+			if v, ok := strings.CutPrefix(trimmed, "remote: "); ok && strings.HasPrefix(line, "  r") {
+				remote = v
+				continue
+			}
+			if v, ok := strings.CutPrefix(trimmed, "revision: "); ok && strings.HasPrefix(line, "  r") {
+				revision = v
+				continue
+			}
+			if m := bundlerSpecRegexp.FindStringSubmatch(line); m != nil {
+				current = &bundlerSpec{Name: m[1]}
+				current.Version, current.Platform, _ = strings.Cut(m[2], "-")
+				if section == "GIT" && remote != "" {
+					current.Source = "git+" + remote
+					if revision != "" {
+						current.Source += "@" + revision
+					}
+				}
+				specs = append(specs, current)
+				continue
+			}
+			if m := bundlerDepRegexp.FindStringSubmatch(line); m != nil && current != nil {
+				current.Deps = append(current.Deps, m[1])
+			}
+		case "DEPENDENCIES":
+			if m := bundlerDirectRegexp.FindStringSubmatch(line); m != nil {
+				direct = append(direct, m[1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return specs, direct, nil
+}
+
+// parseGemfileGroups reads the group of each gem declared in a Gemfile,
+// from both "group ... do" blocks and "group:" options. Gems declared
+// outside of any group are in the "default" group. Only the common literal
+// forms are recognized; a Gemfile is Ruby code and may compute its groups.
+func parseGemfileGroups(p string) (map[string]map[string]bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	groups := map[string]map[string]bool{}
+	stack := [][]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := gemfileGroupRegexp.FindStringSubmatch(line); m != nil {
+			stack = append(stack, parseGemfileSymbols(m[1]))
+			continue
+		}
+		if strings.TrimSpace(line) == "end" && len(stack) > 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		m := gemfileGemRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		gemGroups := []string{}
+		for _, g := range stack {
+			gemGroups = append(gemGroups, g...)
+		}
+		if opt := gemfileGroupsOption.FindStringSubmatch(m[2]); opt != nil {
+			gemGroups = append(gemGroups, parseGemfileSymbols(opt[1])...)
+		}
+		if len(gemGroups) == 0 {
+			gemGroups = []string{"default"}
+		}
+		if groups[m[1]] == nil {
+			groups[m[1]] = map[string]bool{}
+		}
+		for _, g := range gemGroups {
+			groups[m[1]][g] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// parseGemfileSymbols returns the names of the Ruby symbols in s, such as
+// "test" and "development" from "[:test, :development]".
+func parseGemfileSymbols(s string) []string {
+	names := []string{}
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '[' || r == ']' }) {
+		if name, ok := strings.CutPrefix(field, ":"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newGemPackage creates the Package for a gem, with a pkg:gem package URL.
+func newGemPackage(name string, version string) *spdx.Package {
+	return newManagedPackage("gem", "", name, version)
+}
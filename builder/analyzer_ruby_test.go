@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Bundler analyzer tests =====
+func TestBundlerAnalyzerCanAnalyze(t *testing.T) {
+	a := &BundlerAnalyzer{}
+	dirRoot := "../testdata/analyzers/bundler"
+	if !a.Detect(dirRoot) {
+		t.Fatalf("expected Gemfile.lock to be detected")
+	}
+
+	result, err := a.Analyze(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Packages) != 7 {
+		t.Fatalf("expected %d, got %d", 7, len(result.Packages))
+	}
+
+	root := common.ElementID("Package-gem-bundler")
+	if len(result.Roots) != 1 || result.Roots[0] != root {
+		t.Fatalf("expected root %v, got %v", root, result.Roots)
+	}
+
+	nokogiri := findPackage(result.Packages, "Package-gem-nokogiri-1.15.4")
+	if nokogiri == nil {
+		t.Fatalf("expected nokogiri package, got nil")
+	}
+	if nokogiri.PackageComment != "platform: x86_64-linux" {
+		t.Errorf("unexpected comment %v", nokogiri.PackageComment)
+	}
+	if got := nokogiri.PackageExternalReferences[0].Locator; got != "pkg:gem/nokogiri@1.15.4" {
+		t.Errorf("expected %v, got %v", "pkg:gem/nokogiri@1.15.4", got)
+	}
+
+	mylib := findPackage(result.Packages, "Package-gem-mylib-0.1.0")
+	if mylib == nil || mylib.PackageDownloadLocation != "git+https://github.com/example/mylib.git@0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("expected git download location, got %+v", mylib)
+	}
+
+	rlns := result.Relationships
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-gem-rails-7.0.8") {
+		t.Errorf("expected root to depend on rails")
+	}
+	if !hasRelationship(rlns, root, common.TypeRelationshipDependsOn, "Package-gem-mylib-0.1.0") {
+		t.Errorf("expected root to depend on mylib")
+	}
+	if !hasRelationship(rlns, "Package-gem-rspec-3.12.0", common.TypeRelationshipDevDependencyOf, root) {
+		t.Errorf("expected rspec to be a dev dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-gem-minitest-5.20.0", common.TypeRelationshipTestDependencyOf, root) {
+		t.Errorf("expected minitest to be a test dependency of root")
+	}
+	if !hasRelationship(rlns, "Package-gem-rails-7.0.8", common.TypeRelationshipDependsOn, "Package-gem-nokogiri-1.15.4") {
+		t.Errorf("expected rails to depend on nokogiri")
+	}
+}
@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// hasRelationship reports whether rlns contains a relationship of type
+// relType from a to b.
+func hasRelationship(rlns []*spdx.Relationship, a common.ElementID, relType string, b common.ElementID) bool {
+	for _, rln := range rlns {
+		if rln.RefA.ElementRefID == a && rln.Relationship == relType && rln.RefB.ElementRefID == b {
+			return true
+		}
+	}
+	return false
+}
+
+// findPackage returns the package in pkgs with the given ID, or nil.
+func findPackage(pkgs []*spdx.Package, id common.ElementID) *spdx.Package {
+	for _, pkg := range pkgs {
+		if pkg.PackageSPDXIdentifier == id {
+			return pkg
+		}
+	}
+	return nil
+}
+
+type testAnalyzer struct{}
+
+func (a *testAnalyzer) Name() string { return "test-analyzer" }
+
+func (a *testAnalyzer) Detect(dirRoot string) bool { return true }
+
+func (a *testAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	pkg := newManagedPackage("generic", "", "thing", "1.0")
+	return &AnalyzerResult{
+		Packages: []*spdx.Package{pkg},
+		Roots:    []common.ElementID{pkg.PackageSPDXIdentifier},
+	}, nil
+}
+
+// ===== Analyzer registry tests =====
+func TestBuilderHasDefaultAnalyzers(t *testing.T) {
+	for _, name := range []string{"bundler", "cargo", "gomod", "maven", "npm", "pip", "poetry"} {
+		if GetAnalyzer(name) == nil {
+			t.Errorf("expected analyzer %s to be registered", name)
+		}
+	}
+}
+
+func TestBuilderCanRegisterAnalyzer(t *testing.T) {
+	t.Cleanup(func() {
+		analyzersMu.Lock()
+		delete(analyzers, "test-analyzer")
+		analyzersMu.Unlock()
+	})
+	if err := RegisterAnalyzer(&testAnalyzer{}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if GetAnalyzer("test-analyzer") == nil {
+		t.Fatalf("expected registered analyzer, got nil")
+	}
+	// registering the same name again fails
+	if err := RegisterAnalyzer(&testAnalyzer{}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+
+	found := false
+	as := RegisteredAnalyzers()
+	for i, a := range as {
+		if a.Name() == "test-analyzer" {
+			found = true
+		}
+		if i > 0 && as[i-1].Name() > a.Name() {
+			t.Errorf("expected analyzers sorted by name, got %s before %s", as[i-1].Name(), a.Name())
+		}
+	}
+	if !found {
+		t.Errorf("expected test-analyzer in RegisteredAnalyzers")
+	}
+}
+
+func TestBuilderRunAnalyzersSkipsUndetected(t *testing.T) {
+	result, err := RunAnalyzers("../testdata/analyzers/cargo", []Analyzer{&NpmAnalyzer{}, &CargoAnalyzer{}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(result.Roots) != 1 || result.Roots[0] != "Package-cargo-cargo-project-0.1.0" {
+		t.Errorf("expected cargo root only, got %v", result.Roots)
+	}
+}
+
+// fixedAnalyzer returns the packages and relationships it was given.
+type fixedAnalyzer struct {
+	name   string
+	result func() *AnalyzerResult
+}
+
+func (a *fixedAnalyzer) Name() string { return a.name }
+
+func (a *fixedAnalyzer) Detect(dirRoot string) bool { return true }
+
+func (a *fixedAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	return a.result(), nil
+}
+
+func TestBuilderRunAnalyzersGivesCollidingPackagesUniqueIDs(t *testing.T) {
+	analyzer := func(name string, namespace string, depName string) Analyzer {
+		return &fixedAnalyzer{name: name, result: func() *AnalyzerResult {
+			root := newManagedPackage("generic", "", name, "")
+			dep := newManagedPackage("golang", namespace, depName, "v1.0.0")
+			return &AnalyzerResult{
+				Packages:      []*spdx.Package{root, dep},
+				Relationships: []*spdx.Relationship{makeDependencyRelationship(root.PackageSPDXIdentifier, dep.PackageSPDXIdentifier, common.TypeRelationshipDependsOn)},
+				Roots:         []common.ElementID{root.PackageSPDXIdentifier},
+			}
+		}}
+	}
+	result, err := RunAnalyzers("", []Analyzer{
+		analyzer("a", "github.com/foo", "bar-baz"),
+		analyzer("b", "github.com/foo-bar", "baz"),
+		// the same module as analyzer a found, which is only included once
+		analyzer("c", "github.com/foo", "bar-baz"),
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(result.Packages) != 5 {
+		t.Fatalf("expected %d, got %d", 5, len(result.Packages))
+	}
+	first, second := common.ElementID("Package-golang-github.com-foo-bar-baz-v1.0.0"), common.ElementID("Package-golang-github.com-foo-bar-baz-v1.0.0-2")
+	if pkg := findPackage(result.Packages, second); pkg == nil || pkg.PackageExternalReferences[0].Locator != "pkg:golang/github.com/foo-bar/baz@v1.0.0" {
+		t.Errorf("expected %v to be github.com/foo-bar/baz, got %+v", second, pkg)
+	}
+	for _, want := range []struct {
+		from common.ElementID
+		to   common.ElementID
+	}{
+		{"Package-generic-a", first},
+		{"Package-generic-b", second},
+		{"Package-generic-c", first},
+	} {
+		if !hasRelationship(result.Relationships, want.from, common.TypeRelationshipDependsOn, want.to) {
+			t.Errorf("expected %v DEPENDS_ON %v", want.from, want.to)
+		}
+	}
+}
+
+func TestBuildCanRunAnalyzers(t *testing.T) {
+	config := &Config{
+		NamespacePrefix: "https://example.com/analyzers-",
+		CreatorType:     "Person",
+		Creator:         "John Doe",
+		Analyzers:       []Analyzer{&GoModAnalyzer{}},
+		TestValues:      map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+
+	doc, err := Build("gomod1", "../testdata/gomod1/", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	// directory package plus the packages found by the analyzer
+	if len(doc.Packages) != 10 {
+		t.Fatalf("expected %d, got %d", 10, len(doc.Packages))
+	}
+	if !hasRelationship(doc.Relationships, "Package-gomod1", common.TypeRelationshipContains, "Package-golang-example.com-gomod1") {
+		t.Errorf("expected directory package to contain main module")
+	}
+	if !hasRelationship(doc.Relationships, "Package-golang-example.com-gomod1", common.TypeRelationshipDependsOn, "Package-golang-github.com-google-go-cmp-v0.7.0") {
+		t.Errorf("expected main module to depend on go-cmp")
+	}
+}
@@ -5,10 +5,11 @@
 package builder
 
 import (
-	"fmt"
+	"path/filepath"
 
 	"github.com/spdx/tools-golang/spdx"
 	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
 )
 
 // Config is a collection of configuration settings for builder.
@@ -18,7 +19,9 @@ type Config struct {
 	// NamespacePrefix should be a URI representing a prefix for the
 	// namespace with which the SPDX Document will be associated.
 	// It will be used in the DocumentNamespace field in the CreationInfo
-	// section, followed by the per-Document package name and a random UUID.
+	// section, followed by the per-Document package name (escaped for use
+	// in a URI) and the package verification code or, if
+	// UUIDNamespace is set, a random UUID.
 	NamespacePrefix string
 
 	// CreatorType should be one of "Person", "Organization" or "Tool".
@@ -35,6 +38,83 @@ type Config struct {
 	// directory, regardless of where it is in the file tree.
 	PathsIgnored []string
 
+	// Analyzers lists the package-manager analyzers to run on the
+	// directory. Packages found by each analyzer whose manifest is present
+	// are added to the document, and the directory's package will CONTAIN
+	// each analyzer's root packages. Use RegisteredAnalyzers to run every
+	// registered analyzer, or leave nil to run none.
+	Analyzers []Analyzer
+
+	// PackageRoots maps subdirectories of the directory, relative to its
+	// dirRoot, to the names of the packages describing them. Each such
+	// subdirectory becomes a separate Package containing the files within
+	// it, with its own verification code, and the enclosing package will
+	// CONTAIN it. An empty name uses the subdirectory's path as the name.
+	// Packages whose names give the same identifier are told apart by a
+	// numeric suffix.
+	PackageRoots map[string]string
+
+	// DetectPackageRoots adds a package root, named after its path, for
+	// each subdirectory containing a LICENSE, LICENCE or COPYING file or a
+	// manifest detected by one of Analyzers (or, if Analyzers is nil, by
+	// any registered analyzer).
+	DetectPackageRoots bool
+
+	// GitMetadata reads the git repository containing the directory, if
+	// any, directly from its .git directory. Each package's download
+	// location is set to its remote, commit and subdirectory, with a
+	// PERSISTENT-ID reference to the SWHID of its directory in that
+	// commit, and the top-level package's version is set from an exact tag
+	// or the nearest tag, as by "git describe --tags".
+	GitMetadata bool
+
+	// ExcludeGitIgnored omits untracked files matched by the rules in the
+	// repository's .gitignore files and .git/info/exclude.
+	ExcludeGitIgnored bool
+
+	// ExcludeGitUntracked omits all files not tracked in the repository's
+	// index.
+	ExcludeGitUntracked bool
+
+	// PersistentIDs computes the gitoids and SWHID of each file, recorded
+	// as annotations on the file, and the SWHID of each package's
+	// directory, recorded as a PERSISTENT-ID external reference. For
+	// BuildGoBinaryDocument, the executable's gitoids and SWHID are
+	// recorded as external references of its package.
+	PersistentIDs bool
+
+	// LinkageAnalysis reads the ELF, PE and Mach-O binaries among the
+	// files, giving them the BINARY file type, and adds a DYNAMIC_LINK
+	// relationship from each binary to each shared library it needs. A
+	// library is resolved to a file by its SONAME or install name, or by
+	// its file name; libraries not found among the files are recorded as
+	// NOASSERTION, with the library's name in the relationship comment.
+	LinkageAnalysis bool
+
+	// FileIDs selects how the identifiers of Files are generated; see
+	// FileIDScheme. The default numbers them in path order.
+	FileIDs FileIDScheme
+
+	// UUIDNamespace ends the DocumentNamespace with a random UUID, rather
+	// than with the package verification code (or, for
+	// BuildGoBinaryDocument and BuildImageDocument, the SHA1 of the
+	// executable or the image digest). Each build of the same contents
+	// then has a different namespace.
+	UUIDNamespace bool
+
+	// Package holds metadata for the package described by the document,
+	// such as its supplier and declared license, which is validated and
+	// applied to the package after it is built.
+	Package PackageMetadata
+
+	// Symlinks selects how symbolic links within the directory are handled:
+	// skipped (the default), followed as the files and directories they
+	// resolve to, or recorded as files, with a COPY_OF relationship to the
+	// file each resolves to, or an OTHER relationship with NOASSERTION if
+	// that is not in the document. Links are never followed outside of the
+	// directory. See utils.SymlinkPolicy.
+	Symlinks utils.SymlinkPolicy
+
 	// TestValues is used to pass fixed values for testing purposes
 	// only, and should be set to nil for production use. It is only
 	// exported so that it will be accessible within builder.
@@ -47,18 +127,61 @@ type Config struct {
 //   - dirRoot: path to directory to be analyzed
 //   - config: Config object
 func Build(packageName string, dirRoot string, config *Config) (*spdx.Document, error) {
+	if err := config.Package.validate(); err != nil {
+		return nil, err
+	}
+
 	// build Package section first -- will include Files and make the
 	// package verification code available
-	pkg, err := BuildPackageSection(packageName, dirRoot, config.PathsIgnored)
+	shortPaths, links, err := utils.GetAllFilePathsWithSymlinks(dirRoot, config.PathsIgnored, config.Symlinks)
+	if err != nil {
+		return nil, err
+	}
+	shortPaths, symlinks := mergeSymlinkPaths(shortPaths, links)
+	var repo *gitRepo
+	if config.GitMetadata || config.ExcludeGitIgnored || config.ExcludeGitUntracked {
+		if repo, err = findGitRepo(dirRoot); err != nil {
+			return nil, err
+		}
+		if repo != nil {
+			shortPaths, err = filterGitPaths(repo, dirRoot, shortPaths, config.ExcludeGitIgnored, config.ExcludeGitUntracked)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	roots, err := resolvePackageRoots(dirRoot, shortPaths, config)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, containsRlns, err := buildComponentPackages(packageName, dirRoot, shortPaths, roots, symlinks)
 	if err != nil {
 		return nil, err
 	}
+	assignFileIDs(pkgs, config.FileIDs)
+	dirs := []string{dirRoot}
+	for _, r := range roots {
+		dirs = append(dirs, filepath.Join(dirRoot, filepath.FromSlash(r.Path)))
+	}
+	if config.GitMetadata && repo != nil {
+		if err := applyGitMetadata(repo, pkgs, dirs); err != nil {
+			return nil, err
+		}
+	}
+	pkg := pkgs[0]
+	config.Package.apply(pkg)
 
 	ci, err := BuildCreationInfoSection(config.CreatorType, config.Creator, config.TestValues)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.PersistentIDs {
+		if err := addPersistentIDs(dirRoot, pkgs, roots, symlinks, ci.Created); err != nil {
+			return nil, err
+		}
+	}
+
 	rln, err := BuildRelationshipSection(packageName)
 	if err != nil {
 		return nil, err
@@ -69,16 +192,54 @@ func Build(packageName string, dirRoot string, config *Config) (*spdx.Document,
 	if pkg.PackageVerificationCode != nil {
 		packageVerificationCode = *pkg.PackageVerificationCode
 	}
+	namespace, err := makeDocumentNamespace(config, packageName, packageVerificationCode.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	rlns := append([]*spdx.Relationship{rln}, containsRlns...)
+	rlns = append(rlns, buildSymlinkRelationships(pkgs, symlinks)...)
+	if config.LinkageAnalysis {
+		linkRlns, err := addLinkageRelationships(dirRoot, pkgs, symlinks)
+		if err != nil {
+			return nil, err
+		}
+		rlns = append(rlns, linkRlns...)
+	}
+	if len(config.Analyzers) > 0 {
+		// run the analyzers on the directory of each package, so that each
+		// package CONTAINS the roots found within it
+		b := newAnalyzerResultBuilder()
+		for _, pkg := range pkgs {
+			b.reserve(pkg.PackageSPDXIdentifier)
+		}
+		for i, dir := range dirs {
+			result, err := RunAnalyzers(dir, config.Analyzers)
+			if err != nil {
+				return nil, err
+			}
+			for _, root := range b.addResult(result) {
+				b.addRelationship(&spdx.Relationship{
+					RefA:         common.MakeDocElementID("", string(pkgs[i].PackageSPDXIdentifier)),
+					RefB:         common.MakeDocElementID("", string(root)),
+					Relationship: common.TypeRelationshipContains,
+				})
+			}
+		}
+		result := b.result()
+		pkgs = append(pkgs, result.Packages...)
+		rlns = append(rlns, result.Relationships...)
+	}
 
 	doc := &spdx.Document{
 		SPDXVersion:       spdx.Version,
 		DataLicense:       spdx.DataLicense,
 		SPDXIdentifier:    common.ElementID("DOCUMENT"),
 		DocumentName:      packageName,
-		DocumentNamespace: fmt.Sprintf("%s%s-%s", config.NamespacePrefix, packageName, packageVerificationCode),
+		DocumentNamespace: namespace,
 		CreationInfo:      ci,
-		Packages:          []*spdx.Package{pkg},
-		Relationships:     []*spdx.Relationship{rln},
+		Packages:          pkgs,
+		Relationships:     rlns,
 	}
 
 	return doc, nil
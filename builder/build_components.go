@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// packageRoot is a subdirectory of the analyzed directory that is
+// described by its own Package.
+type packageRoot struct {
+	// Path is the subdirectory, relative to dirRoot, using forward slashes
+	// and without leading or trailing slashes.
+	Path string
+	Name string
+	// Parent is the index of the nearest enclosing packageRoot, or -1 if
+	// the subdirectory is only enclosed by the top-level package.
+	Parent int
+}
+
+// licenseFilePrefixes are the file name prefixes that mark a directory as
+// a separate component when detecting package roots.
+var licenseFilePrefixes = []string{"LICENSE", "LICENCE", "COPYING"}
+
+// resolvePackageRoots returns the package roots configured in
+// config.PackageRoots, together with those detected if
+// config.DetectPackageRoots is set, sorted by path. shortPaths are the
+// paths of the files included in the document, as returned by
+// utils.GetAllFilePaths.
+func resolvePackageRoots(dirRoot string, shortPaths []string, config *Config) ([]packageRoot, error) {
+	names := map[string]string{}
+	for sub, name := range config.PackageRoots {
+		p, err := cleanPackageRootPath(sub)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[p]; ok {
+			return nil, fmt.Errorf("package root %s listed more than once", p)
+		}
+		fi, err := os.Stat(filepath.Join(dirRoot, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("package root %s is not a directory", p)
+		}
+		if name == "" {
+			name = p
+		}
+		names[p] = name
+	}
+
+	if config.DetectPackageRoots {
+		as := config.Analyzers
+		if len(as) == 0 {
+			as = RegisteredAnalyzers()
+		}
+		for _, p := range detectPackageRoots(dirRoot, shortPaths, as) {
+			if _, ok := names[p]; !ok {
+				names[p] = p
+			}
+		}
+	}
+
+	roots := make([]packageRoot, 0, len(names))
+	for _, p := range sortedKeys(names) {
+		roots = append(roots, packageRoot{Path: p, Name: names[p], Parent: -1})
+	}
+	// as roots are sorted, any enclosing root precedes the roots it encloses
+	for i := range roots {
+		for j := i - 1; j >= 0; j-- {
+			if strings.HasPrefix(roots[i].Path, roots[j].Path+"/") {
+				roots[i].Parent = j
+				break
+			}
+		}
+	}
+
+	return roots, nil
+}
+
+// cleanPackageRootPath normalizes a configured package root, which must
+// be a subdirectory of the analyzed directory.
+func cleanPackageRootPath(sub string) (string, error) {
+	p := path.Clean(filepath.ToSlash(sub))
+	p = strings.TrimPrefix(p, "/")
+	if p == "." || p == "" || p == ".." || strings.HasPrefix(p, "../") || filepath.IsAbs(sub) {
+		return "", fmt.Errorf("package root %q is not a subdirectory", sub)
+	}
+	return p, nil
+}
+
+// detectPackageRoots returns the subdirectories of dirRoot containing an
+// included file whose name starts with LICENSE, LICENCE or COPYING, or a
+// manifest detected by one of the analyzers.
+func detectPackageRoots(dirRoot string, shortPaths []string, as []Analyzer) []string {
+	// group the files by parent directory, noting which directories
+	// directly contain a license file
+	dirs := []string{}
+	licensed := map[string]bool{}
+	for _, shortPath := range shortPaths {
+		p := strings.TrimPrefix(shortPath, "/")
+		dir := path.Dir(p)
+		if dir == "." {
+			continue
+		}
+		if _, ok := licensed[dir]; !ok {
+			licensed[dir] = false
+			dirs = append(dirs, dir)
+		}
+		if isLicenseFile(path.Base(p)) {
+			licensed[dir] = true
+		}
+	}
+
+	found := []string{}
+	for _, dir := range dirs {
+		if licensed[dir] {
+			found = append(found, dir)
+			continue
+		}
+		for _, a := range as {
+			if a.Detect(filepath.Join(dirRoot, filepath.FromSlash(dir))) {
+				found = append(found, dir)
+				break
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// isLicenseFile reports whether a file named name is a license file.
+func isLicenseFile(name string) bool {
+	base := strings.ToUpper(name)
+	for _, prefix := range licenseFilePrefixes {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPackageRoot returns the index of the innermost package root
+// containing shortPath, or -1 if it belongs to the top-level package.
+func findPackageRoot(shortPath string, roots []packageRoot) int {
+	p := strings.TrimPrefix(shortPath, "/")
+	found := -1
+	for i, r := range roots {
+		if strings.HasPrefix(p, r.Path+"/") {
+			found = i
+		}
+	}
+	return found
+}
+
+// buildComponentPackages creates the top-level Package for dirRoot and one
+// Package for each of roots, distributing the files in shortPaths between
+// them so that each file is in the innermost package containing it. File
+// names remain relative to dirRoot, and file identifiers are numbered
+// across the whole document in path order. The returned relationships
+// record that each package CONTAINS the packages of its nested roots.
+// Package identifiers are derived from the package names, with a numeric
+// suffix for a root whose identifier is already taken, such as by the
+// top-level package or by root "a-b" for root "a/b".
+// Paths in symlinks are recorded symbolic links, which are not read.
+func buildComponentPackages(packageName string, dirRoot string, shortPaths []string, roots []packageRoot, symlinks map[string]utils.Symlink) ([]*spdx.Package, []*spdx.Relationship, error) {
+	files := make([][]*spdx.File, len(roots)+1)
+	for fileNumber, shortPath := range shortPaths {
+		var newFile *spdx.File
+		if link, ok := symlinks[shortPath]; ok {
+			newFile = buildSymlinkFileSection("."+shortPath, link, fileNumber)
+		} else {
+			var err error
+			if newFile, err = BuildFileSection("."+shortPath, dirRoot, fileNumber); err != nil {
+				return nil, nil, err
+			}
+		}
+		i := findPackageRoot(shortPath, roots) + 1
+		files[i] = append(files[i], newFile)
+	}
+
+	gen := utils.NewElementIDGenerator()
+	pkgs := make([]*spdx.Package, 0, len(roots)+1)
+	top, err := buildFilesPackage(packageName, gen.Generate("Package", packageName), files[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgs = append(pkgs, top)
+
+	rlns := []*spdx.Relationship{}
+	for i, r := range roots {
+		pkg, err := buildFilesPackage(r.Name, gen.Generate("Package", r.Name), files[i+1])
+		if err != nil {
+			return nil, nil, err
+		}
+		pkgs = append(pkgs, pkg)
+
+		parent := top
+		if r.Parent >= 0 {
+			parent = pkgs[r.Parent+1]
+		}
+		rlns = append(rlns, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(parent.PackageSPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipContains,
+		})
+	}
+
+	return pkgs, rlns, nil
+}
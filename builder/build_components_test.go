@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Multi-package builder tests =====
+func TestBuildCanCreatePackagesForConfiguredRoots(t *testing.T) {
+	config := &Config{
+		NamespacePrefix: "https://github.com/swinslow/spdx-docs/spdx-go/testdata-",
+		CreatorType:     "Person",
+		Creator:         "John Doe",
+		PackageRoots: map[string]string{
+			"libs/alpha":           "alpha",
+			"libs/alpha/internal/": "",
+			"./libs/beta":          "beta",
+		},
+		TestValues: map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+
+	doc, err := Build("monorepo", "../testdata/monorepo", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(doc.Packages) != 4 {
+		t.Fatalf("expected %d, got %d", 4, len(doc.Packages))
+	}
+
+	want := []struct {
+		name  string
+		id    common.ElementID
+		files []string
+	}{
+		{"monorepo", "Package-monorepo", []string{"./README.md", "./tools/build.sh"}},
+		{"alpha", "Package-alpha", []string{"./libs/alpha/LICENSE", "./libs/alpha/alpha.go"}},
+		{"libs/alpha/internal", "Package-libs-alpha-internal", []string{"./libs/alpha/internal/internal.go"}},
+		{"beta", "Package-beta", []string{"./libs/beta/beta.go", "./libs/beta/go.mod"}},
+	}
+	codes := map[string]bool{}
+	for i, w := range want {
+		pkg := doc.Packages[i]
+		if pkg.PackageName != w.name {
+			t.Errorf("expected %v, got %v", w.name, pkg.PackageName)
+		}
+		if pkg.PackageSPDXIdentifier != w.id {
+			t.Errorf("expected %v, got %v", w.id, pkg.PackageSPDXIdentifier)
+		}
+		if len(pkg.Files) != len(w.files) {
+			t.Fatalf("expected %d files in %s, got %d", len(w.files), w.name, len(pkg.Files))
+		}
+		for j, f := range pkg.Files {
+			if f.FileName != w.files[j] {
+				t.Errorf("expected %v, got %v", w.files[j], f.FileName)
+			}
+		}
+		if pkg.PackageVerificationCode == nil {
+			t.Fatalf("expected non-nil verification code for %s", w.name)
+		}
+		codes[pkg.PackageVerificationCode.Value] = true
+	}
+	if len(codes) != len(want) {
+		t.Errorf("expected each package to have its own verification code, got %v", codes)
+	}
+
+	// file identifiers are unique across the document, in path order
+	if doc.Packages[0].Files[1].FileSPDXIdentifier != "File6" {
+		t.Errorf("expected %v, got %v", "File6", doc.Packages[0].Files[1].FileSPDXIdentifier)
+	}
+	if doc.Packages[3].Files[0].FileSPDXIdentifier != "File4" {
+		t.Errorf("expected %v, got %v", "File4", doc.Packages[3].Files[0].FileSPDXIdentifier)
+	}
+
+	wantNamespace := fmt.Sprintf("https://github.com/swinslow/spdx-docs/spdx-go/testdata-monorepo-%s", doc.Packages[0].PackageVerificationCode.Value)
+	if doc.DocumentNamespace != wantNamespace {
+		t.Errorf("expected %s, got %s", wantNamespace, doc.DocumentNamespace)
+	}
+
+	if len(doc.Relationships) != 4 {
+		t.Fatalf("expected %d, got %d", 4, len(doc.Relationships))
+	}
+	rlns := doc.Relationships
+	if !hasRelationship(rlns, "DOCUMENT", common.TypeRelationshipDescribe, "Package-monorepo") {
+		t.Errorf("expected DOCUMENT to describe Package-monorepo")
+	}
+	if !hasRelationship(rlns, "Package-monorepo", common.TypeRelationshipContains, "Package-alpha") {
+		t.Errorf("expected Package-monorepo to contain Package-alpha")
+	}
+	if !hasRelationship(rlns, "Package-alpha", common.TypeRelationshipContains, "Package-libs-alpha-internal") {
+		t.Errorf("expected Package-alpha to contain Package-libs-alpha-internal")
+	}
+	if !hasRelationship(rlns, "Package-monorepo", common.TypeRelationshipContains, "Package-beta") {
+		t.Errorf("expected Package-monorepo to contain Package-beta")
+	}
+}
+
+func TestBuildCanDetectPackageRoots(t *testing.T) {
+	config := &Config{
+		NamespacePrefix:    "https://github.com/swinslow/spdx-docs/spdx-go/testdata-",
+		CreatorType:        "Person",
+		Creator:            "John Doe",
+		DetectPackageRoots: true,
+		Analyzers:          []Analyzer{&GoModAnalyzer{}},
+		TestValues:         map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+
+	doc, err := Build("monorepo", "../testdata/monorepo", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	// monorepo, libs/alpha (LICENSE), libs/beta (go.mod) and the
+	// example.com/beta module found by the analyzer
+	if len(doc.Packages) != 4 {
+		t.Fatalf("expected %d, got %d", 4, len(doc.Packages))
+	}
+	alpha := findPackage(doc.Packages, "Package-libs-alpha")
+	if alpha == nil {
+		t.Fatalf("expected Package-libs-alpha, got nil")
+	}
+	if len(alpha.Files) != 3 {
+		t.Errorf("expected %d, got %d", 3, len(alpha.Files))
+	}
+	if findPackage(doc.Packages, "Package-libs-beta") == nil {
+		t.Fatalf("expected Package-libs-beta, got nil")
+	}
+
+	rlns := doc.Relationships
+	if !hasRelationship(rlns, "Package-monorepo", common.TypeRelationshipContains, "Package-libs-alpha") {
+		t.Errorf("expected Package-monorepo to contain Package-libs-alpha")
+	}
+	if !hasRelationship(rlns, "Package-libs-beta", common.TypeRelationshipContains, "Package-golang-example.com-beta") {
+		t.Errorf("expected Package-libs-beta to contain the Go module found in it")
+	}
+}
+
+func TestBuildGivesCollidingPackageRootsUniqueIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/b/LICENSE", "a-b/LICENSE", "top/LICENSE", "main.go"} {
+		writeTestFile(t, dir, p, p)
+	}
+	config := &Config{
+		NamespacePrefix:    "https://example.com/test-",
+		CreatorType:        "Person",
+		Creator:            "John Doe",
+		DetectPackageRoots: true,
+		// a configured root named like the top-level package
+		PackageRoots: map[string]string{"top": "top"},
+		TestValues:   map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+
+	doc, err := Build("top", dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := map[common.ElementID]string{
+		"Package-top":   "./main.go",
+		"Package-a-b":   "./a-b/LICENSE",
+		"Package-a-b-2": "./a/b/LICENSE",
+		"Package-top-2": "./top/LICENSE",
+	}
+	if len(doc.Packages) != len(want) {
+		t.Fatalf("expected %d, got %d", len(want), len(doc.Packages))
+	}
+	for id, fileName := range want {
+		pkg := findPackage(doc.Packages, id)
+		if pkg == nil {
+			t.Errorf("expected %v, got nil", id)
+			continue
+		}
+		if len(pkg.Files) != 1 || pkg.Files[0].FileName != fileName {
+			t.Errorf("%v: expected file %v, got %+v", id, fileName, pkg.Files)
+		}
+	}
+	if !hasRelationship(doc.Relationships, "DOCUMENT", common.TypeRelationshipDescribe, "Package-top") {
+		t.Errorf("expected document to describe Package-top")
+	}
+}
+
+func TestBuildPackageRootsFailsForInvalidRoots(t *testing.T) {
+	for _, roots := range []map[string]string{
+		{"../project1": ""},
+		{"/libs/alpha/../..": ""},
+		{".": ""},
+		{"libs/gamma": ""},
+		{"README.md": ""},
+		{"libs/alpha": "", "libs/alpha/": ""},
+	} {
+		config := &Config{PackageRoots: roots}
+		if _, err := Build("monorepo", "../testdata/monorepo", config); err == nil {
+			t.Errorf("expected non-nil error for %v, got nil", roots)
+		}
+	}
+}
+
+func TestBuildComponentPackagesWithoutRootsMatchesPackageSection(t *testing.T) {
+	pkg, err := BuildPackageSection("project1", "../testdata/project1/", nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	config := &Config{DetectPackageRoots: true}
+	doc, err := Build("project1", "../testdata/project1/", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := doc.Packages[0]
+	if got.PackageVerificationCode.Value != pkg.PackageVerificationCode.Value {
+		t.Errorf("expected %v, got %v", pkg.PackageVerificationCode.Value, got.PackageVerificationCode.Value)
+	}
+	if len(got.Files) != len(pkg.Files) {
+		t.Errorf("expected %d, got %d", len(pkg.Files), len(got.Files))
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// gitWorkTreePrefix returns the slash-separated path of dirRoot relative
+// to the working tree of r, or "" if dirRoot is the top of the working tree.
+func gitWorkTreePrefix(r *gitRepo, dirRoot string) (string, error) {
+	abs, err := filepath.Abs(dirRoot)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(r.workTree, abs)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return "", nil
+	}
+	return rel, nil
+}
+
+// filterGitPaths removes from shortPaths, as returned by
+// utils.GetAllFilePaths for dirRoot, the files in the .git directory and,
+// depending on excludeIgnored and excludeUntracked, the files that git
+// would ignore or that are not tracked in the index. As in git, ignore
+// rules do not apply to tracked files.
+func filterGitPaths(r *gitRepo, dirRoot string, shortPaths []string, excludeIgnored bool, excludeUntracked bool) ([]string, error) {
+	prefix, err := gitWorkTreePrefix(r, dirRoot)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := r.trackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	inSubmodule := func(p string) bool {
+		for d := path.Dir(p); d != "."; d = path.Dir(d) {
+			if tracked[d] == gitModeSubmodule {
+				return true
+			}
+		}
+		return false
+	}
+
+	workPaths := make([]string, len(shortPaths))
+	for i, shortPath := range shortPaths {
+		workPaths[i] = path.Join(prefix, strings.TrimPrefix(shortPath, "/"))
+	}
+	var ignore *gitIgnore
+	if excludeIgnored {
+		if ignore, err = loadGitIgnore(r, workPaths); err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := []string{}
+	for i, shortPath := range shortPaths {
+		p := workPaths[i]
+		if p == ".git" || strings.HasPrefix(p, ".git/") || strings.Contains(p, "/.git/") || strings.HasSuffix(p, "/.git") {
+			continue
+		}
+		if _, ok := tracked[p]; !ok && !inSubmodule(p) {
+			if excludeUntracked || (ignore != nil && ignore.ignored(p)) {
+				continue
+			}
+		}
+		filtered = append(filtered, shortPath)
+	}
+	return filtered, nil
+}
+
+// applyGitMetadata records the state of the repository r in pkgs, where
+// pkgs[i] describes the directory dirs[i] within r's working tree. Each
+// package gets a download location of the form git+<remote>@<commit>#<path>
+// and a PERSISTENT-ID reference to the SWHID of its directory in the HEAD
+// commit. The first package, describing the top-level directory, also gets
+// the SWHID of the commit, and a version from the tags on the commit or
+// its ancestors. Nothing is recorded for a repository without commits.
+func applyGitMetadata(r *gitRepo, pkgs []*spdx.Package, dirs []string) error {
+	head, err := r.resolveRef("HEAD")
+	if errors.Is(err, errGitRefNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	remote, err := r.remoteURL()
+	if err != nil {
+		return err
+	}
+	tree, err := r.commitTree(head)
+	if err != nil {
+		return err
+	}
+
+	for i, pkg := range pkgs {
+		sub, err := gitWorkTreePrefix(r, dirs[i])
+		if err != nil {
+			return err
+		}
+		if loc := gitDownloadLocation(remote, head, sub); loc != "" {
+			pkg.PackageDownloadLocation = loc
+		}
+		// a directory not yet committed has no tree to refer to
+		if subtree, err := r.subtree(tree, sub); err == nil {
+			pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &spdx.PackageExternalReference{
+				Category:           common.CategoryPersistentId,
+				RefType:            common.TypePersistentIdSwh,
+				Locator:            "swh:1:dir:" + subtree,
+				ExternalRefComment: "directory as of git commit " + head,
+			})
+		}
+	}
+
+	top := pkgs[0]
+	top.PackageExternalReferences = append(top.PackageExternalReferences, &spdx.PackageExternalReference{
+		Category: common.CategoryPersistentId,
+		RefType:  common.TypePersistentIdSwh,
+		Locator:  "swh:1:rev:" + head,
+	})
+	version, err := r.describe(head)
+	if err != nil {
+		return err
+	}
+	if version != "" {
+		top.PackageVersion = version
+	}
+	return nil
+}
+
+// gitDownloadLocation returns the SPDX download location for the
+// directory sub of commit in the repository at remote, or "" if remote is
+// empty or a local path. scp-like remotes ("git@host:path") are rewritten
+// as ssh URLs, and passwords and HTTP user names are removed.
+func gitDownloadLocation(remote string, commit string, sub string) string {
+	if remote == "" {
+		return ""
+	}
+	remote = strings.TrimPrefix(remote, "git+")
+	if !strings.Contains(remote, "://") {
+		host, p, ok := strings.Cut(remote, ":")
+		if !ok || host == "" || strings.Contains(host, "/") {
+			return ""
+		}
+		remote = "ssh://" + host + "/" + strings.TrimPrefix(p, "/")
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme == "file" {
+		return ""
+	}
+	if u.User != nil {
+		if u.Scheme == "http" || u.Scheme == "https" {
+			u.User = nil
+		} else {
+			u.User = url.User(u.User.Username())
+		}
+	}
+
+	loc := "git+" + u.String() + "@" + commit
+	if sub != "" {
+		loc += "#" + sub
+	}
+	return loc
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// fileNames returns the names of pkg's files.
+func fileNames(pkg *spdx.Package) []string {
+	names := []string{}
+	for _, f := range pkg.Files {
+		names = append(names, f.FileName)
+	}
+	return names
+}
+
+// ===== Git builder tests =====
+func TestBuildCanRecordGitMetadata(t *testing.T) {
+	dir := newTestGitRepo(t)
+	head := runGit(t, dir, "rev-parse", "HEAD")
+
+	config := &Config{
+		NamespacePrefix:   "https://example.com/",
+		GitMetadata:       true,
+		ExcludeGitIgnored: true,
+		PackageRoots:      map[string]string{"src": "src"},
+	}
+	doc, err := Build("repo", dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	top, src := doc.Packages[0], doc.Packages[1]
+
+	want := []string{"./.gitignore", "./README.md", "./keep.log", "./new.txt"}
+	if got := fileNames(top); len(got) != len(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("expected %v, got %v", want[i], got[i])
+			}
+		}
+	}
+
+	if want := "git+https://example.com/org/repo.git@" + head; top.PackageDownloadLocation != want {
+		t.Errorf("expected %v, got %v", want, top.PackageDownloadLocation)
+	}
+	if want := "git+https://example.com/org/repo.git@" + head + "#src"; src.PackageDownloadLocation != want {
+		t.Errorf("expected %v, got %v", want, src.PackageDownloadLocation)
+	}
+	if want := runGit(t, dir, "describe", "--tags", "--abbrev=7"); top.PackageVersion != want {
+		t.Errorf("expected %v, got %v", want, top.PackageVersion)
+	}
+	if src.PackageVersion != "" {
+		t.Errorf("expected empty version for component, got %v", src.PackageVersion)
+	}
+
+	if len(top.PackageExternalReferences) != 2 {
+		t.Fatalf("expected %d, got %d", 2, len(top.PackageExternalReferences))
+	}
+	dirRef, revRef := top.PackageExternalReferences[0], top.PackageExternalReferences[1]
+	if dirRef.Category != common.CategoryPersistentId || dirRef.RefType != common.TypePersistentIdSwh {
+		t.Errorf("unexpected reference %+v", dirRef)
+	}
+	if want := "swh:1:dir:" + runGit(t, dir, "rev-parse", "HEAD^{tree}"); dirRef.Locator != want {
+		t.Errorf("expected %v, got %v", want, dirRef.Locator)
+	}
+	if want := "swh:1:rev:" + head; revRef.Locator != want {
+		t.Errorf("expected %v, got %v", want, revRef.Locator)
+	}
+	if want := "swh:1:dir:" + runGit(t, dir, "rev-parse", "HEAD:src"); src.PackageExternalReferences[0].Locator != want {
+		t.Errorf("expected %v, got %v", want, src.PackageExternalReferences[0].Locator)
+	}
+}
+
+func TestBuildCanExcludeGitUntrackedFiles(t *testing.T) {
+	dir := newTestGitRepo(t)
+
+	config := &Config{ExcludeGitUntracked: true}
+	doc, err := Build("src", filepath.Join(dir, "src"), config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got := fileNames(doc.Packages[0]); len(got) != 2 {
+		t.Errorf("expected 2 tracked files, got %v", got)
+	}
+
+	writeTestFile(t, dir, "src/c.go", "package src\n")
+	doc, err = Build("repo", dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	got := fileNames(doc.Packages[0])
+	want := []string{"./.gitignore", "./README.md", "./src/a.go", "./src/b.go"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], got[i])
+		}
+	}
+	// without GitMetadata, the download location is left alone
+	if doc.Packages[0].PackageDownloadLocation != "NOASSERTION" {
+		t.Errorf("expected NOASSERTION, got %v", doc.Packages[0].PackageDownloadLocation)
+	}
+}
+
+func TestBuildGitMetadataIgnoresRepositoryWithoutCommits(t *testing.T) {
+	dir := newTestGitRepo(t)
+	empty := filepath.Join(dir, "empty")
+	writeTestFile(t, empty, "file.txt", "text\n")
+	runGit(t, empty, "init", "-q")
+
+	doc, err := Build("empty", empty, &Config{GitMetadata: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	pkg := doc.Packages[0]
+	if pkg.PackageDownloadLocation != "NOASSERTION" || len(pkg.PackageExternalReferences) != 0 {
+		t.Errorf("expected no git metadata, got %v %v", pkg.PackageDownloadLocation, pkg.PackageExternalReferences)
+	}
+	if got := fileNames(pkg); len(got) != 1 {
+		t.Errorf("expected .git directory to be omitted, got %v", got)
+	}
+}
+
+func TestGitDownloadLocation(t *testing.T) {
+	for _, tc := range []struct {
+		remote, sub, want string
+	}{
+		{"https://github.com/spdx/tools-golang.git", "", "git+https://github.com/spdx/tools-golang.git@abc"},
+		{"https://token@github.com/spdx/tools-golang", "builder", "git+https://github.com/spdx/tools-golang@abc#builder"},
+		{"git@github.com:spdx/tools-golang.git", "", "git+ssh://git@github.com/spdx/tools-golang.git@abc"},
+		{"ssh://git:pw@example.com:2222/repo.git", "", "git+ssh://git@example.com:2222/repo.git@abc"},
+		{"git+https://example.com/repo", "", "git+https://example.com/repo@abc"},
+		{"/srv/git/repo.git", "", ""},
+		{"file:///srv/git/repo.git", "", ""},
+		{"", "", ""},
+	} {
+		if got := gitDownloadLocation(tc.remote, "abc", tc.sub); got != tc.want {
+			t.Errorf("%q: expected %v, got %v", tc.remote, tc.want, got)
+		}
+	}
+}
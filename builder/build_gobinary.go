@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// BuildGoBinaryDocument creates an SPDX Document describing a compiled Go
+// executable, using the module and build information that the Go toolchain
+// embeds in the binary. It returns that document or error if any is
+// encountered. Arguments:
+//   - binaryPath: path to the Go executable
+//   - config: Config object
+//
+// The executable is the described package. The main module and every
+// dependency module become packages with purl external references, and are
+// joined to the executable with STATIC_LINK relationships. Build settings
+// such as GOOS, GOARCH, CGO_ENABLED and vcs.revision are recorded in the
+// executable package's comment.
+func BuildGoBinaryDocument(binaryPath string, config *Config) (*spdx.Document, error) {
+	if err := config.Package.validate(); err != nil {
+		return nil, err
+	}
+
+	info, err := buildinfo.ReadFile(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	binPkg, err := BuildGoBinaryPackageSection(binaryPath, info)
+	if err != nil {
+		return nil, err
+	}
+	if config.PersistentIDs {
+		blob, err := utils.GetGitBlobForFilePath(binaryPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, locator := range makeGitoidLocators(blob) {
+			binPkg.PackageExternalReferences = append(binPkg.PackageExternalReferences, makePersistentIDExternalRef(locator))
+		}
+	}
+
+	config.Package.apply(binPkg)
+
+	pkgs := []*spdx.Package{binPkg}
+	rlns := []*spdx.Relationship{
+		{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(binPkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipDescribe,
+		},
+	}
+
+	modPkgs, modRlns := linkGoBinaryModulePackages(binPkg, buildGoBinaryModulePackages(info))
+	pkgs = append(pkgs, modPkgs...)
+	rlns = append(rlns, modRlns...)
+
+	ci, err := BuildCreationInfoSection(config.CreatorType, config.Creator, config.TestValues)
+	if err != nil {
+		return nil, err
+	}
+
+	sha1 := ""
+	for _, checksum := range binPkg.PackageChecksums {
+		if checksum.Algorithm == common.SHA1 {
+			sha1 = checksum.Value
+		}
+	}
+	namespace, err := makeDocumentNamespace(config, binPkg.PackageName, sha1)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    common.ElementID("DOCUMENT"),
+		DocumentName:      binPkg.PackageName,
+		DocumentNamespace: namespace,
+		CreationInfo:      ci,
+		Packages:          pkgs,
+		Relationships:     rlns,
+	}
+
+	return doc, nil
+}
+
+// BuildGoBinaryPackageSection creates the SPDX Package for a Go executable,
+// with its checksums and a comment listing the embedded build settings.
+// Arguments:
+//   - binaryPath: path to the Go executable
+//   - info: build information read from the executable
+func BuildGoBinaryPackageSection(binaryPath string, info *buildinfo.BuildInfo) (*spdx.Package, error) {
+	ssha1, ssha256, smd5, err := utils.GetHashesForFilePath(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := filepath.Base(binaryPath)
+	version := ""
+	if info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+
+	comments := []string{fmt.Sprintf("go version: %s", info.GoVersion)}
+	if info.Path != "" {
+		comments = append(comments, fmt.Sprintf("main package: %s", info.Path))
+	}
+	for _, setting := range info.Settings {
+		comments = append(comments, fmt.Sprintf("%s=%s", setting.Key, setting.Value))
+	}
+
+	pkg := &spdx.Package{
+		PackageName:               fileName,
+		PackageSPDXIdentifier:     makeElementID("Package", fileName),
+		PackageVersion:            version,
+		PackageFileName:           fileName,
+		PackageDownloadLocation:   "NOASSERTION",
+		FilesAnalyzed:             false,
+		IsFilesAnalyzedTagPresent: true,
+		PackageChecksums: []common.Checksum{
+			{Algorithm: common.SHA1, Value: ssha1},
+			{Algorithm: common.SHA256, Value: ssha256},
+			{Algorithm: common.MD5, Value: smd5},
+		},
+		PackageLicenseConcluded: "NOASSERTION",
+		PackageLicenseDeclared:  "NOASSERTION",
+		PackageCopyrightText:    "NOASSERTION",
+		PackageComment:          strings.Join(comments, "\n"),
+		PrimaryPackagePurpose:   "APPLICATION",
+	}
+
+	return pkg, nil
+}
+
+// buildGoBinaryModulePackages creates a Package for the main module and for
+// each dependency module recorded in the build information.
+func buildGoBinaryModulePackages(info *buildinfo.BuildInfo) []*spdx.Package {
+	pkgs := []*spdx.Package{}
+
+	if info.Main.Path != "" {
+		version := info.Main.Version
+		if version == "(devel)" {
+			version = ""
+		}
+		sums := map[string]string{}
+		if info.Main.Sum != "" {
+			sums[info.Main.Path+" "+version] = info.Main.Sum
+		}
+		pkg := buildGoModulePackage(&goModule{Path: info.Main.Path, Version: version}, nil, sums)
+		pkg.PrimaryPackagePurpose = ""
+		pkgs = append(pkgs, pkg)
+	}
+
+	for _, dep := range info.Deps {
+		m := &goModule{Path: dep.Path, Version: dep.Version}
+		var rep *goModReplace
+		sums := map[string]string{}
+		if dep.Replace != nil {
+			rep = &goModReplace{
+				OldPath:    dep.Path,
+				OldVersion: dep.Version,
+				NewPath:    dep.Replace.Path,
+				NewVersion: dep.Replace.Version,
+			}
+			if dep.Replace.Sum != "" {
+				sums[dep.Replace.Path+" "+dep.Replace.Version] = dep.Replace.Sum
+			}
+		} else if dep.Sum != "" {
+			sums[dep.Path+" "+dep.Version] = dep.Sum
+		}
+		pkgs = append(pkgs, buildGoModulePackage(m, rep, sums))
+	}
+
+	return pkgs
+}
+
+// linkGoBinaryModulePackages returns the module packages, with a
+// STATIC_LINK relationship from the executable's package to each. Modules
+// are identified by their package URL, so that two modules whose IDs
+// collide, such as example.com/a/b-c and example.com/a-b/c, are given
+// distinct IDs, while a module listed twice is only included once.
+func linkGoBinaryModulePackages(binPkg *spdx.Package, modPkgs []*spdx.Package) ([]*spdx.Package, []*spdx.Relationship) {
+	b := newAnalyzerResultBuilder()
+	b.reserve(binPkg.PackageSPDXIdentifier)
+	for _, modPkg := range modPkgs {
+		id := b.addPackage(modPkg)
+		b.addRelationship(&spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(binPkg.PackageSPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(id)),
+			Relationship: common.TypeRelationshipStaticLink,
+		})
+	}
+	result := b.result()
+	return result.Packages, result.Relationships
+}
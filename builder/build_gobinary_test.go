@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"debug/buildinfo"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Go binary builder tests =====
+func TestBuilderCanBuildGoBinaryDocument(t *testing.T) {
+	// the running test binary is itself a Go executable with build info
+	binaryPath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to find test executable: %v", err)
+	}
+	info, err := buildinfo.ReadFile(binaryPath)
+	if err != nil {
+		t.Skipf("test executable has no build info: %v", err)
+	}
+
+	config := &Config{
+		NamespacePrefix: "https://example.com/gobinary-",
+		CreatorType:     "Person",
+		Creator:         "John Doe",
+		TestValues:      map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+
+	doc, err := BuildGoBinaryDocument(binaryPath, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	binPkg := doc.Packages[0]
+	if binPkg.PackageName != filepath.Base(binaryPath) {
+		t.Errorf("expected %v, got %v", filepath.Base(binaryPath), binPkg.PackageName)
+	}
+	if len(binPkg.PackageChecksums) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(binPkg.PackageChecksums))
+	}
+	if !strings.Contains(binPkg.PackageComment, "go version: "+info.GoVersion) {
+		t.Errorf("expected go version in comment, got %v", binPkg.PackageComment)
+	}
+	if !strings.Contains(binPkg.PackageComment, "GOOS=") || !strings.Contains(binPkg.PackageComment, "GOARCH=") {
+		t.Errorf("expected build settings in comment, got %v", binPkg.PackageComment)
+	}
+	if !strings.HasSuffix(doc.DocumentNamespace, binPkg.PackageChecksums[0].Value) {
+		t.Errorf("expected namespace to end with SHA1, got %v", doc.DocumentNamespace)
+	}
+
+	// main module plus each dependency
+	if len(doc.Packages) != len(info.Deps)+2 {
+		t.Fatalf("expected %d, got %d", len(info.Deps)+2, len(doc.Packages))
+	}
+	mainPkg := doc.Packages[1]
+	if mainPkg.PackageName != "github.com/spdx/tools-golang" {
+		t.Errorf("expected %v, got %v", "github.com/spdx/tools-golang", mainPkg.PackageName)
+	}
+
+	if doc.Relationships[0].Relationship != common.TypeRelationshipDescribe {
+		t.Errorf("expected %v, got %v", common.TypeRelationshipDescribe, doc.Relationships[0].Relationship)
+	}
+	for _, rln := range doc.Relationships[1:] {
+		if rln.RefA.ElementRefID != binPkg.PackageSPDXIdentifier {
+			t.Errorf("expected %v, got %v", binPkg.PackageSPDXIdentifier, rln.RefA.ElementRefID)
+		}
+		if rln.Relationship != common.TypeRelationshipStaticLink {
+			t.Errorf("expected %v, got %v", common.TypeRelationshipStaticLink, rln.Relationship)
+		}
+	}
+}
+
+func TestBuilderGoBinaryDocumentFailsForNonGoFile(t *testing.T) {
+	config := &Config{}
+	_, err := BuildGoBinaryDocument("../testdata/project1/file1.testdata.txt", config)
+	if err == nil {
+		t.Fatalf("expected non-nil error, got nil")
+	}
+}
+
+func TestBuilderCanBuildGoBinaryModulePackagesWithReplace(t *testing.T) {
+	info := &buildinfo.BuildInfo{}
+	info.Main.Path = "example.com/app"
+	info.Main.Version = "(devel)"
+	info.Deps = append(info.Deps, &debug.Module{
+		Path:    "example.com/old",
+		Version: "v1.0.0",
+		Replace: &debug.Module{
+			Path:    "example.com/new",
+			Version: "v1.2.0",
+			Sum:     "h1:wk8382ETsv4JYUZwIsn6YpYiWiBsYLSJiTsyBybVuN8=",
+		},
+	})
+
+	pkgs := buildGoBinaryModulePackages(info)
+	if len(pkgs) != 2 {
+		t.Fatalf("expected %d, got %d", 2, len(pkgs))
+	}
+	if pkgs[0].PackageVersion != "" {
+		t.Errorf("expected empty version for (devel) main module, got %v", pkgs[0].PackageVersion)
+	}
+	if pkgs[0].PackageComment != "" || len(pkgs[0].Annotations) != 0 {
+		t.Errorf("expected no go.sum hash for unsummed main module, got %q, %+v", pkgs[0].PackageComment, pkgs[0].Annotations)
+	}
+	if pkgs[1].PackageName != "example.com/new" || pkgs[1].PackageVersion != "v1.2.0" {
+		t.Errorf("expected replacement module, got %v %v", pkgs[1].PackageName, pkgs[1].PackageVersion)
+	}
+	if pkgs[1].PackageComment != "replaces example.com/old@v1.0.0" {
+		t.Errorf("expected replacement module comment, got %v", pkgs[1].PackageComment)
+	}
+	if len(pkgs[1].Annotations) != 1 || pkgs[1].Annotations[0].AnnotationComment != "go.sum h1:wk8382ETsv4JYUZwIsn6YpYiWiBsYLSJiTsyBybVuN8=" {
+		t.Errorf("expected replacement module go.sum hash, got %+v", pkgs[1].Annotations)
+	}
+}
+
+func TestBuilderGoBinaryModulePackagesGetUniqueIDs(t *testing.T) {
+	info := &buildinfo.BuildInfo{}
+	info.Main.Path = "example.com/app"
+	info.Deps = []*debug.Module{
+		{Path: "example.com/a/b-c", Version: "v1.0.0"},
+		{Path: "example.com/a-b/c", Version: "v1.0.0", Sum: "h1:wk8382ETsv4JYUZwIsn6YpYiWiBsYLSJiTsyBybVuN8="},
+		{Path: "example.com/a/b-c", Version: "v1.0.0"},
+	}
+	binPkg := &spdx.Package{PackageName: "app", PackageSPDXIdentifier: "Package-app"}
+
+	pkgs, rlns := linkGoBinaryModulePackages(binPkg, buildGoBinaryModulePackages(info))
+	if len(pkgs) != 3 || len(rlns) != 3 {
+		t.Fatalf("expected 3 packages and relationships, got %d and %d", len(pkgs), len(rlns))
+	}
+	first, second := pkgs[1], pkgs[2]
+	if first.PackageSPDXIdentifier == second.PackageSPDXIdentifier {
+		t.Errorf("expected distinct IDs, got %v", first.PackageSPDXIdentifier)
+	}
+	if second.PackageExternalReferences[0].Locator != "pkg:golang/example.com/a-b/c@v1.0.0" {
+		t.Errorf("expected example.com/a-b/c, got %v", second.PackageExternalReferences[0].Locator)
+	}
+	if got := second.Annotations[0].AnnotationSPDXIdentifier.ElementRefID; got != second.PackageSPDXIdentifier {
+		t.Errorf("expected go.sum annotation on %v, got %v", second.PackageSPDXIdentifier, got)
+	}
+	for _, pkg := range pkgs {
+		if !hasRelationship(rlns, "Package-app", common.TypeRelationshipStaticLink, pkg.PackageSPDXIdentifier) {
+			t.Errorf("expected Package-app STATIC_LINK %v", pkg.PackageSPDXIdentifier)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// goModule is a single module requirement, either from a go.mod require
+// directive or from a vendor/modules.txt module line.
+type goModule struct {
+	Path     string
+	Version  string
+	Indirect bool
+	// VendorOnly is set for modules that appear in vendor/modules.txt
+	// but are not listed in go.mod
+	VendorOnly bool
+}
+
+// goModReplace is a single go.mod replace directive. OldVersion is empty
+// if the directive applies to every version of OldPath; NewVersion is empty
+// if the replacement is a local directory.
+type goModReplace struct {
+	OldPath    string
+	OldVersion string
+	NewPath    string
+	NewVersion string
+}
+
+// goModFile holds the parts of a go.mod file that are relevant to
+// building an SPDX document.
+type goModFile struct {
+	Module    string
+	GoVersion string
+	Requires  []*goModule
+	Replaces  []*goModReplace
+}
+
+// BuildGoModuleSection reads the go.mod file in dirRoot, along with go.sum
+// and vendor/modules.txt if present, and creates one SPDX Package for the
+// main module and one for each module it requires. It returns those
+// packages, with the main module first, and DEPENDS_ON relationships from
+// the main module to each requirement, or error if any is encountered.
+// No network access is performed. Arguments:
+//   - dirRoot: path to directory containing the go.mod file
+func BuildGoModuleSection(dirRoot string) ([]*spdx.Package, []*spdx.Relationship, error) {
+	mf, err := parseGoModFile(filepath.Join(dirRoot, "go.mod"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sums, err := parseGoSumFile(filepath.Join(dirRoot, "go.sum"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	vendored, err := parseGoVendorModulesFile(filepath.Join(dirRoot, "vendor", "modules.txt"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	// modules vendored without being listed in go.mod (as happens for
+	// modules that predate go 1.17 module graph pruning) are still part
+	// of the build, so record them as indirect requirements
+	required := map[string]bool{}
+	for _, m := range mf.Requires {
+		required[m.Path] = true
+	}
+	for _, m := range vendored {
+		if !required[m.Path] {
+			mf.Requires = append(mf.Requires, &goModule{
+				Path:       m.Path,
+				Version:    m.Version,
+				Indirect:   true,
+				VendorOnly: true,
+			})
+			required[m.Path] = true
+		}
+	}
+
+	mainPkg := newGoModulePackage(mf.Module, "")
+	if mf.GoVersion != "" {
+		mainPkg.PackageComment = fmt.Sprintf("go directive: %s", mf.GoVersion)
+	}
+
+	// modules are identified by their package URL, so that two modules
+	// whose IDs collide, such as example.com/a/b-c and example.com/a-b/c,
+	// are given distinct IDs, while a module required twice, such as
+	// through two replace directives, is only included once
+	b := newAnalyzerResultBuilder()
+	b.addPackage(mainPkg)
+	for _, m := range mf.Requires {
+		pkg := buildGoModulePackage(m, mf.findReplace(m.Path, m.Version), sums)
+		id := b.addPackage(pkg)
+
+		rln := &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(mainPkg.PackageSPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(id)),
+			Relationship: common.TypeRelationshipDependsOn,
+		}
+		if m.VendorOnly {
+			rln.RelationshipComment = "indirect; listed only in vendor/modules.txt"
+		} else if m.Indirect {
+			rln.RelationshipComment = "indirect"
+		}
+		b.addRelationship(rln)
+	}
+
+	result := b.result()
+	return result.Packages, result.Relationships, nil
+}
+
+// buildGoModulePackage creates the SPDX Package for a required module,
+// taking into account any replace directive that applies to it.
+func buildGoModulePackage(m *goModule, rep *goModReplace, sums map[string]string) *spdx.Package {
+	path, version := m.Path, m.Version
+	comments := []string{}
+	local := false
+	if rep != nil {
+		if rep.NewVersion == "" {
+			local = true
+			version = ""
+			comments = append(comments, fmt.Sprintf("replaced by local directory %s", rep.NewPath))
+		} else {
+			path, version = rep.NewPath, rep.NewVersion
+			comments = append(comments, fmt.Sprintf("replaces %s@%s", m.Path, m.Version))
+		}
+	}
+	if m.VendorOnly {
+		comments = append(comments, "vendored")
+	}
+
+	pkg := newGoModulePackage(path, version)
+	pkg.PrimaryPackagePurpose = "LIBRARY"
+
+	// the go.sum hash is a hash of the module's file tree (a "dirhash"),
+	// not of any single file, so it is not a package checksum
+	if !local {
+		if h1, ok := sums[path+" "+version]; ok {
+			pkg.Annotations = append(pkg.Annotations, makeGoSumAnnotation(pkg, h1))
+		}
+	}
+
+	pkg.PackageComment = strings.Join(comments, "; ")
+	return pkg
+}
+
+// makeGoSumAnnotation creates the annotation recording the go.sum hash of
+// a module's package, such as "go.sum h1:...".
+func makeGoSumAnnotation(pkg *spdx.Package, h1 string) spdx.Annotation {
+	return spdx.Annotation{
+		Annotator: common.Annotator{
+			Annotator:     "github.com/spdx/tools-golang/builder",
+			AnnotatorType: "Tool",
+		},
+		AnnotationDate:           time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		AnnotationType:           "OTHER",
+		AnnotationSPDXIdentifier: common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+		AnnotationComment:        "go.sum " + h1,
+	}
+}
+
+// newGoModulePackage creates the Package for a module path and (optional)
+// version, with a pkg:golang package URL.
+func newGoModulePackage(modulePath string, version string) *spdx.Package {
+	namespace, name := splitGoModulePath(modulePath)
+	return newManagedPackage("golang", namespace, name, version)
+}
+
+// splitGoModulePath splits a module path into the purl namespace (all but
+// the last element) and name (the last element).
+func splitGoModulePath(modulePath string) (string, string) {
+	if i := strings.LastIndex(modulePath, "/"); i >= 0 {
+		return modulePath[:i], modulePath[i+1:]
+	}
+	return "", modulePath
+}
+
+// findReplace returns the replace directive applying to the given module
+// version, if any. As in the go command, a directive naming a specific
+// version takes precedence over one applying to all versions.
+func (mf *goModFile) findReplace(path string, version string) *goModReplace {
+	var anyVersion *goModReplace
+	for _, r := range mf.Replaces {
+		if r.OldPath != path {
+			continue
+		}
+		if r.OldVersion == version {
+			return r
+		}
+		if r.OldVersion == "" {
+			anyVersion = r
+		}
+	}
+	return anyVersion
+}
+
+// parseGoModFile parses the module, go, require and replace directives of
+// a go.mod file. Other directives are ignored.
+func parseGoModFile(p string) (*goModFile, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mf := &goModFile{}
+	block := ""
+	lineNum := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNum++
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields, err := splitGoModFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", p, lineNum, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		verb := block
+		if block == "" {
+			verb = fields[0]
+			fields = fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				block = verb
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		switch verb {
+		case "module":
+			if len(fields) != 1 {
+				return nil, fmt.Errorf("%s:%d: invalid module directive", p, lineNum)
+			}
+			mf.Module = fields[0]
+		case "go":
+			if len(fields) == 1 {
+				mf.GoVersion = fields[0]
+			}
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s:%d: invalid require directive", p, lineNum)
+			}
+			mf.Requires = append(mf.Requires, &goModule{
+				Path:     fields[0],
+				Version:  fields[1],
+				Indirect: isGoModIndirectComment(comment),
+			})
+		case "replace":
+			r, err := parseGoModReplace(fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", p, lineNum, err)
+			}
+			mf.Replaces = append(mf.Replaces, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if mf.Module == "" {
+		return nil, fmt.Errorf("%s: no module directive found", p)
+	}
+	return mf, nil
+}
+
+// parseGoModReplace parses the arguments of a replace directive, in one of
+// the forms "old [v] => new v" or "old [v] => ./local/dir".
+func parseGoModReplace(fields []string) (*goModReplace, error) {
+	arrow := -1
+	for i, f := range fields {
+		if f == "=>" {
+			arrow = i
+			break
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+		return nil, fmt.Errorf("invalid replace directive")
+	}
+
+	r := &goModReplace{OldPath: fields[0], NewPath: fields[arrow+1]}
+	if arrow == 2 {
+		r.OldVersion = fields[1]
+	}
+	if len(fields)-arrow == 3 {
+		r.NewVersion = fields[arrow+2]
+	}
+	return r, nil
+}
+
+// splitGoModFields splits a go.mod line into its whitespace-separated
+// fields, unquoting any quoted strings.
+func splitGoModFields(line string) ([]string, error) {
+	fields := strings.Fields(line)
+	for i, f := range fields {
+		if strings.HasPrefix(f, `"`) || strings.HasPrefix(f, "`") {
+			s, err := strconv.Unquote(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s", f)
+			}
+			fields[i] = s
+		}
+	}
+	return fields, nil
+}
+
+// isGoModIndirectComment reports whether a go.mod line comment marks the
+// requirement as indirect.
+func isGoModIndirectComment(comment string) bool {
+	comment = strings.TrimSpace(comment)
+	return comment == "indirect" || strings.HasPrefix(comment, "indirect;")
+}
+
+// parseGoSumFile reads a go.sum file and returns a map from "path version"
+// to the module's h1: hash. Hashes of go.mod files alone are skipped.
+func parseGoSumFile(p string) (map[string]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// parseGoVendorModulesFile reads the module lines ("# path version") of a
+// vendor/modules.txt file, sorted by module path.
+func parseGoVendorModulesFile(p string) ([]*goModule, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mods := []*goModule{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "# ")
+		if !ok {
+			continue
+		}
+		// drop any "=> replacement" portion; replacements are read from go.mod
+		line, _, _ = strings.Cut(line, "=>")
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		mods = append(mods, &goModule{Path: fields[0], Version: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	return mods, nil
+}
+
+// GoModAnalyzer is the Analyzer for Go modules, reading go.mod, go.sum
+// and vendor/modules.txt. See BuildGoModuleSection.
+type GoModAnalyzer struct{}
+
+// Name returns "gomod".
+func (a *GoModAnalyzer) Name() string { return "gomod" }
+
+// Detect reports whether dirRoot contains a go.mod file.
+func (a *GoModAnalyzer) Detect(dirRoot string) bool {
+	return fileExists(filepath.Join(dirRoot, "go.mod"))
+}
+
+// Analyze returns the main module as the root package, together with its
+// requirements.
+func (a *GoModAnalyzer) Analyze(dirRoot string) (*AnalyzerResult, error) {
+	pkgs, rlns, err := BuildGoModuleSection(dirRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &AnalyzerResult{
+		Packages:      pkgs,
+		Relationships: rlns,
+		Roots:         []common.ElementID{pkgs[0].PackageSPDXIdentifier},
+	}, nil
+}
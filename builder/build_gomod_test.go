@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Go module section builder tests =====
+func TestBuilderCanBuildGoModuleSection(t *testing.T) {
+	dirRoot := "../testdata/gomod1/"
+
+	pkgs, rlns, err := BuildGoModuleSection(dirRoot)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	// main module, seven go.mod requirements and one vendor-only module
+	if len(pkgs) != 9 {
+		t.Fatalf("expected %d, got %d", 9, len(pkgs))
+	}
+	if len(rlns) != 8 {
+		t.Fatalf("expected %d, got %d", 8, len(rlns))
+	}
+
+	mainPkg := pkgs[0]
+	if mainPkg.PackageName != "example.com/gomod1" {
+		t.Errorf("expected %v, got %v", "example.com/gomod1", mainPkg.PackageName)
+	}
+	if mainPkg.PackageSPDXIdentifier != "Package-golang-example.com-gomod1" {
+		t.Errorf("expected %v, got %v", "Package-golang-example.com-gomod1", mainPkg.PackageSPDXIdentifier)
+	}
+	if got := mainPkg.PackageExternalReferences[0].Locator; got != "pkg:golang/example.com/gomod1" {
+		t.Errorf("expected %v, got %v", "pkg:golang/example.com/gomod1", got)
+	}
+
+	byName := map[string]*spdx.Package{}
+	for _, p := range pkgs {
+		byName[p.PackageName] = p
+	}
+
+	cmp := byName["github.com/google/go-cmp"]
+	if cmp == nil {
+		t.Fatalf("expected go-cmp package, got nil")
+	}
+	if cmp.PackageVersion != "v0.7.0" {
+		t.Errorf("expected %v, got %v", "v0.7.0", cmp.PackageVersion)
+	}
+	ref := cmp.PackageExternalReferences[0]
+	if ref.Category != common.CategoryPackageManager || ref.RefType != common.TypePackageManagerPURL {
+		t.Errorf("expected purl external ref, got %+v", ref)
+	}
+	if ref.Locator != "pkg:golang/github.com/google/go-cmp@v0.7.0" {
+		t.Errorf("expected %v, got %v", "pkg:golang/github.com/google/go-cmp@v0.7.0", ref.Locator)
+	}
+	// the go.sum hash is a dirhash, not a checksum of the package
+	if len(cmp.PackageChecksums) != 0 {
+		t.Errorf("expected no checksums, got %+v", cmp.PackageChecksums)
+	}
+	if cmp.PackageComment != "" {
+		t.Errorf("unexpected comment %v", cmp.PackageComment)
+	}
+	if len(cmp.Annotations) != 1 || cmp.Annotations[0].AnnotationType != "OTHER" ||
+		cmp.Annotations[0].AnnotationComment != "go.sum h1:wk8382ETsv4JYUZwIsn6YpYiWiBsYLSJiTsyBybVuN8=" ||
+		cmp.Annotations[0].AnnotationSPDXIdentifier != common.MakeDocElementID("", string(cmp.PackageSPDXIdentifier)) {
+		t.Errorf("expected go.sum hash annotation, got %+v", cmp.Annotations)
+	}
+
+	// module replaced by another module
+	if byName["example.com/replaced"] != nil {
+		t.Errorf("expected replaced module to be omitted")
+	}
+	fork := byName["example.com/fork"]
+	if fork == nil {
+		t.Fatalf("expected replacement module package, got nil")
+	}
+	if fork.PackageVersion != "v1.0.1" {
+		t.Errorf("expected %v, got %v", "v1.0.1", fork.PackageVersion)
+	}
+	if fork.PackageComment != "replaces example.com/replaced@v1.0.0" {
+		t.Errorf("unexpected comment %v", fork.PackageComment)
+	}
+	if len(fork.Annotations) != 1 || fork.Annotations[0].AnnotationComment != "go.sum h1:PM2g2M6wF3x4Jv5cnTjJtJYm1KzG3H6/0Jx8YZ2zqaI=" {
+		t.Errorf("expected go.sum hash annotation, got %+v", fork.Annotations)
+	}
+
+	// module replaced by a local directory
+	local := byName["example.com/local"]
+	if local == nil {
+		t.Fatalf("expected locally-replaced module package, got nil")
+	}
+	if local.PackageVersion != "" {
+		t.Errorf("expected empty version, got %v", local.PackageVersion)
+	}
+	if local.PackageComment != "replaced by local directory ../local" {
+		t.Errorf("unexpected comment %v", local.PackageComment)
+	}
+	if len(local.PackageChecksums) != 0 {
+		t.Errorf("expected no checksums, got %+v", local.PackageChecksums)
+	}
+
+	// relationships should all come from the main module, with indirect
+	// requirements marked
+	comments := map[common.ElementID]string{}
+	for _, rln := range rlns {
+		if rln.RefA.ElementRefID != mainPkg.PackageSPDXIdentifier {
+			t.Errorf("expected %v, got %v", mainPkg.PackageSPDXIdentifier, rln.RefA.ElementRefID)
+		}
+		if rln.Relationship != common.TypeRelationshipDependsOn {
+			t.Errorf("expected %v, got %v", common.TypeRelationshipDependsOn, rln.Relationship)
+		}
+		comments[rln.RefB.ElementRefID] = rln.RelationshipComment
+	}
+	if got := comments["Package-golang-github.com-google-go-cmp-v0.7.0"]; got != "" {
+		t.Errorf("expected empty comment for direct dependency, got %v", got)
+	}
+	if got := comments["Package-golang-gopkg.in-yaml.v3-v3.0.1"]; got != "indirect" {
+		t.Errorf("expected %v, got %v", "indirect", got)
+	}
+	if got := comments["Package-golang-golang.org-x-text-v0.14.0"]; got != "indirect; listed only in vendor/modules.txt" {
+		t.Errorf("expected vendor-only comment, got %v", got)
+	}
+}
+
+func TestBuilderGoModuleSectionGivesCollidingModulesUniqueIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go.mod", `module example.com/app
+
+require (
+	github.com/foo/bar-baz v1.0.0
+	github.com/foo-bar/baz v1.0.0
+)
+`)
+
+	pkgs, rlns, err := BuildGoModuleSection(dir)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(pkgs) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(pkgs))
+	}
+	want := []common.ElementID{
+		"Package-golang-example.com-app",
+		"Package-golang-github.com-foo-bar-baz-v1.0.0",
+		"Package-golang-github.com-foo-bar-baz-v1.0.0-2",
+	}
+	for i := range want {
+		if pkgs[i].PackageSPDXIdentifier != want[i] {
+			t.Errorf("expected %v, got %v", want[i], pkgs[i].PackageSPDXIdentifier)
+		}
+	}
+	if got := pkgs[2].PackageExternalReferences[0].Locator; got != "pkg:golang/github.com/foo-bar/baz@v1.0.0" {
+		t.Errorf("expected %v, got %v", "pkg:golang/github.com/foo-bar/baz@v1.0.0", got)
+	}
+	for _, id := range want[1:] {
+		if !hasRelationship(rlns, want[0], common.TypeRelationshipDependsOn, id) {
+			t.Errorf("expected %v DEPENDS_ON %v", want[0], id)
+		}
+	}
+}
+
+func TestBuilderGoModuleSectionFailsWithoutGoMod(t *testing.T) {
+	_, _, err := BuildGoModuleSection("../testdata/project1/")
+	if err == nil {
+		t.Fatalf("expected non-nil error, got nil")
+	}
+}
+
+func TestBuilderCanParseGoModReplaceForms(t *testing.T) {
+	r, err := parseGoModReplace([]string{"a.com/x", "=>", "../x"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if r.OldVersion != "" || r.NewPath != "../x" || r.NewVersion != "" {
+		t.Errorf("unexpected replace %+v", r)
+	}
+
+	r, err = parseGoModReplace([]string{"a.com/x", "v1.0.0", "=>", "b.com/x", "v1.1.0"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if r.OldVersion != "v1.0.0" || r.NewPath != "b.com/x" || r.NewVersion != "v1.1.0" {
+		t.Errorf("unexpected replace %+v", r)
+	}
+
+	if _, err = parseGoModReplace([]string{"a.com/x", "b.com/x"}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
+
+func TestBuilderMakesEscapedPurls(t *testing.T) {
+	got := makePurl("npm", "@angular", "core", "1.0.0")
+	if got != "pkg:npm/%40angular/core@1.0.0" {
+		t.Errorf("expected %v, got %v", "pkg:npm/%40angular/core@1.0.0", got)
+	}
+	got = newGoModulePackage("github.com/a/b", "v2.0.0+incompatible").PackageExternalReferences[0].Locator
+	if got != "pkg:golang/github.com/a/b@v2.0.0%2Bincompatible" {
+		t.Errorf("expected %v, got %v", "pkg:golang/github.com/a/b@v2.0.0%2Bincompatible", got)
+	}
+}
@@ -0,0 +1,452 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// imageFile is a regular file in an image's filesystem.
+type imageFile struct {
+	// Layer is the index of the layer that last wrote the file.
+	Layer     int
+	Checksums []common.Checksum
+	// Data holds the contents of the files read by the builder, such as
+	// package databases; it is nil for other files.
+	Data []byte
+}
+
+// imageLinkTarget is a hard link within a layer, resolved once the
+// layer's files are known.
+type imageLinkTarget struct {
+	path   string
+	target string
+}
+
+// imageDataPaths reports whether the contents of the file at p are needed
+// to find the image's OS packages.
+func imageDataPaths(p string) bool {
+	switch p {
+	case "/etc/os-release", "/usr/lib/os-release", "/var/lib/dpkg/status", "/lib/apk/db/installed":
+		return true
+	}
+	return strings.HasPrefix(p, "/var/lib/dpkg/status.d/")
+}
+
+// BuildImageDocument creates an SPDX Document describing a container image
+// from an OCI image layout directory, or from an uncompressed tarball as
+// written by "docker save" (or an OCI image layout in tar form), without
+// accessing the network. The layers are applied in order, including
+// whiteouts, to reconstruct the image's final filesystem. Arguments:
+//   - imagePath: path to the image layout directory or tarball
+//   - config: Config object; PathsIgnored applies to paths in the image's
+//     filesystem
+//
+// The image package is described by the document, and CONTAINS a package
+// for each layer, with the layer's digest as its SHA256 checksum. Each
+// file of the final filesystem belongs to the package of the layer that
+// last wrote it. Packages installed according to a dpkg status database
+// or an apk installed database are added, also CONTAINED by the image.
+func BuildImageDocument(imagePath string, config *Config) (*spdx.Document, error) {
+	if err := config.Package.validate(); err != nil {
+		return nil, err
+	}
+
+	src, err := openImageSource(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.close()
+
+	info, err := readImageInfo(src)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*imageFile{}
+	for i := range info.Layers {
+		if err := readImageLayer(src, &info.Layers[i], i, files); err != nil {
+			return nil, err
+		}
+	}
+
+	imagePkg := buildImagePackage(info)
+	config.Package.apply(imagePkg)
+	pkgs := []*spdx.Package{imagePkg}
+	rlns := []*spdx.Relationship{
+		{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(imagePkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipDescribe,
+		},
+	}
+
+	// distribute the files to their layers, numbered in path order
+	layerFiles := make([][]*spdx.File, len(info.Layers))
+	paths := sortedKeys(files)
+	fileNumber := 0
+	for _, p := range paths {
+		if utils.ShouldIgnore(p, config.PathsIgnored) {
+			continue
+		}
+		f := files[p]
+		layerFiles[f.Layer] = append(layerFiles[f.Layer], &spdx.File{
+			FileName:           "." + p,
+			FileSPDXIdentifier: common.ElementID(fmt.Sprintf("File%d", fileNumber)),
+			Checksums:          f.Checksums,
+			LicenseConcluded:   "NOASSERTION",
+			LicenseInfoInFiles: []string{"NOASSERTION"},
+			FileCopyrightText:  "NOASSERTION",
+		})
+		fileNumber++
+	}
+
+	for i, layer := range info.Layers {
+		layerPkg, err := buildImageLayerPackage(info, i, layer, layerFiles[i])
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, layerPkg)
+		rlns = append(rlns, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(imagePkg.PackageSPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(layerPkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipContains,
+		})
+	}
+	assignFileIDs(pkgs, config.FileIDs)
+
+	osResult := buildImageOSPackages(files)
+	pkgs = append(pkgs, osResult.Packages...)
+	for _, pkg := range osResult.Packages {
+		rlns = append(rlns, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(imagePkg.PackageSPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipContains,
+		})
+	}
+	rlns = append(rlns, osResult.Relationships...)
+
+	ci, err := BuildCreationInfoSection(config.CreatorType, config.Creator, config.TestValues)
+	if err != nil {
+		return nil, err
+	}
+
+	_, digestHex, _ := strings.Cut(info.Digest, ":")
+	namespace, err := makeDocumentNamespace(config, imagePkg.PackageName, digestHex)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    common.ElementID("DOCUMENT"),
+		DocumentName:      imagePkg.PackageName,
+		DocumentNamespace: namespace,
+		CreationInfo:      ci,
+		Packages:          pkgs,
+		Relationships:     rlns,
+	}
+
+	return doc, nil
+}
+
+// buildImagePackage creates the Package for the image itself, with a
+// pkg:oci package URL.
+func buildImagePackage(info *imageInfo) *spdx.Package {
+	name := info.Name
+	if name == "" {
+		name = info.Digest
+	}
+	alg, digestHex, _ := strings.Cut(info.Digest, ":")
+
+	pkg := &spdx.Package{
+		PackageName:               name,
+		PackageSPDXIdentifier:     makeElementID("Package", "image", name),
+		PackageVersion:            info.Tag,
+		PackageDownloadLocation:   "NOASSERTION",
+		FilesAnalyzed:             false,
+		IsFilesAnalyzedTagPresent: true,
+		PackageLicenseConcluded:   "NOASSERTION",
+		PackageLicenseDeclared:    "NOASSERTION",
+		PackageCopyrightText:      "NOASSERTION",
+		PrimaryPackagePurpose:     "CONTAINER",
+	}
+	if alg == "sha256" {
+		pkg.PackageChecksums = []common.Checksum{{Algorithm: common.SHA256, Value: digestHex}}
+	}
+	if info.OS != "" {
+		pkg.PackageComment = "platform: " + info.OS + "/" + info.Architecture
+	}
+	if info.Name != "" {
+		// the purl name is the last segment of the repository, which is
+		// given in full by repository_url
+		purl := appendPurlQualifiers(makePurl("oci", "", path.Base(info.Name), info.Digest), map[string]string{
+			"repository_url": info.Name,
+			"tag":            info.Tag,
+		})
+		pkg.PackageExternalReferences = []*spdx.PackageExternalReference{makePurlExternalRef(purl)}
+	}
+	return pkg
+}
+
+// buildImageLayerPackage creates the Package for the layer at index i,
+// containing files.
+func buildImageLayerPackage(info *imageInfo, i int, layer imageLayer, files []*spdx.File) (*spdx.Package, error) {
+	alg, digestHex, _ := strings.Cut(layer.Digest, ":")
+	comments := []string{}
+	if layer.MediaType != "" {
+		comments = append(comments, "media type: "+layer.MediaType)
+	}
+	if layer.DiffID != "" && layer.DiffID != layer.Digest {
+		comments = append(comments, "diff ID: "+layer.DiffID)
+	}
+	if layer.CreatedBy != "" {
+		comments = append(comments, "created by: "+layer.CreatedBy)
+	}
+
+	pkg := &spdx.Package{
+		PackageName:               layer.Digest,
+		PackageSPDXIdentifier:     makeElementID("Package", "layer", strconv.Itoa(i)),
+		PackageDownloadLocation:   "NOASSERTION",
+		IsFilesAnalyzedTagPresent: true,
+		PackageLicenseConcluded:   "NOASSERTION",
+		PackageLicenseDeclared:    "NOASSERTION",
+		PackageCopyrightText:      "NOASSERTION",
+		PackageComment:            strings.Join(comments, "\n"),
+		PrimaryPackagePurpose:     "ARCHIVE",
+	}
+	if alg == "sha256" {
+		pkg.PackageChecksums = []common.Checksum{{Algorithm: common.SHA256, Value: digestHex}}
+	}
+	if len(files) > 0 {
+		code, err := utils.GetVerificationCode(files, "")
+		if err != nil {
+			return nil, err
+		}
+		pkg.FilesAnalyzed = true
+		pkg.PackageVerificationCode = &code
+		pkg.PackageLicenseInfoFromFiles = []string{}
+		pkg.Files = files
+	}
+	return pkg, nil
+}
+
+// readImageLayer reads the layer at index i, verifying its digest (or
+// recording it, if not given by the manifest), and applies it to files.
+// Whiteouts and opaque directory markers in the layer remove files written
+// by lower layers, as does any entry other than a directory, which replaces
+// what was at its path. A directory entry only replaces a file at its own
+// path, since layers repeat the entries of parent directories.
+func readImageLayer(src imageSource, layer *imageLayer, i int, files map[string]*imageFile) error {
+	rc, err := src.open(layer.Path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	digest := sha256.New()
+	br := bufio.NewReader(io.TeeReader(rc, digest))
+	magic, _ := br.Peek(4)
+	var r io.Reader = br
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("layer %s: %v", layer.Path, err)
+		}
+		defer zr.Close()
+		r = zr
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return fmt.Errorf("layer %s: zstd compression is not supported", layer.Path)
+	}
+
+	added := map[string]*imageFile{}
+	links := []imageLinkTarget{}
+	removed := []string{}
+	opaque := []string{}
+	dirs := []string{}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("layer %s: %v", layer.Path, err)
+		}
+		p := path.Clean("/" + hdr.Name)
+		dir, base := path.Split(p)
+		if base == ".wh..wh..opq" {
+			opaque = append(opaque, strings.TrimSuffix(dir, "/"))
+			continue
+		}
+		if name, ok := strings.CutPrefix(base, ".wh."); ok {
+			removed = append(removed, dir+name)
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			f, err := readImageFile(tr, i, imageDataPaths(p))
+			if err != nil {
+				return fmt.Errorf("layer %s: %s: %v", layer.Path, p, err)
+			}
+			added[p] = f
+			removed = append(removed, p)
+		case tar.TypeLink:
+			links = append(links, imageLinkTarget{path: p, target: path.Clean("/" + hdr.Linkname)})
+			removed = append(removed, p)
+		case tar.TypeDir:
+			dirs = append(dirs, p)
+		default:
+			// a symbolic link or other entry replaces a file or a whole
+			// directory at the same path
+			removed = append(removed, p)
+		}
+	}
+
+	// read to the end, so the digest covers the whole blob
+	if _, err := io.Copy(io.Discard, br); err != nil {
+		return err
+	}
+	sum := "sha256:" + hex.EncodeToString(digest.Sum(nil))
+	if layer.Digest == "" {
+		layer.Digest = sum
+	} else if strings.HasPrefix(layer.Digest, "sha256:") && layer.Digest != sum {
+		return fmt.Errorf("layer %s: digest %s does not match %s", layer.Path, sum, layer.Digest)
+	}
+
+	// files of lower layers are found under a removed directory through
+	// their sorted paths, rather than by checking every file for each
+	// removal
+	var paths []string
+	removeImageFiles := func(p string, keepDir bool) {
+		if !keepDir {
+			delete(files, p)
+		}
+		if paths == nil {
+			paths = make([]string, 0, len(files))
+			for existing := range files {
+				paths = append(paths, existing)
+			}
+			sort.Strings(paths)
+		}
+		prefix := strings.TrimSuffix(p, "/") + "/"
+		for j := sort.SearchStrings(paths, prefix); j < len(paths) && strings.HasPrefix(paths[j], prefix); j++ {
+			delete(files, paths[j])
+		}
+	}
+	for _, d := range opaque {
+		removeImageFiles(d, true)
+	}
+	for _, p := range removed {
+		removeImageFiles(p, false)
+	}
+	for _, d := range dirs {
+		delete(files, d)
+	}
+
+	// hard links may refer to files in this layer or in lower ones
+	for _, l := range links {
+		target := added[l.target]
+		if target == nil {
+			target = files[l.target]
+		}
+		if target == nil {
+			continue
+		}
+		f := *target
+		f.Layer = i
+		added[l.path] = &f
+	}
+	for p, f := range added {
+		files[p] = f
+	}
+	return nil
+}
+
+// readImageFile hashes the contents of a file in layer i, keeping them if
+// keep is set.
+func readImageFile(r io.Reader, i int, keep bool) (*imageFile, error) {
+	hashes := []hash.Hash{sha1.New(), sha256.New(), md5.New()}
+	writers := []io.Writer{hashes[0], hashes[1], hashes[2]}
+	var buf *bytes.Buffer
+	if keep {
+		buf = &bytes.Buffer{}
+		writers = append(writers, buf)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+
+	f := &imageFile{
+		Layer: i,
+		Checksums: []common.Checksum{
+			{Algorithm: common.SHA1, Value: hex.EncodeToString(hashes[0].Sum(nil))},
+			{Algorithm: common.SHA256, Value: hex.EncodeToString(hashes[1].Sum(nil))},
+			{Algorithm: common.MD5, Value: hex.EncodeToString(hashes[2].Sum(nil))},
+		},
+	}
+	if buf != nil {
+		f.Data = buf.Bytes()
+	}
+	return f, nil
+}
+
+// buildImageOSPackages returns the packages listed in the dpkg and apk
+// databases of the image's final filesystem.
+func buildImageOSPackages(files map[string]*imageFile) *AnalyzerResult {
+	osRelease := map[string]string{}
+	for _, p := range []string{"/usr/lib/os-release", "/etc/os-release"} {
+		if f := files[p]; f != nil {
+			osRelease = parseOSRelease(f.Data)
+		}
+	}
+
+	combined := &AnalyzerResult{
+		Packages:      []*spdx.Package{},
+		Relationships: []*spdx.Relationship{},
+	}
+	debPkgs := []*osPackage{}
+	statusPaths := []string{}
+	for p := range files {
+		if p == "/var/lib/dpkg/status" || strings.HasPrefix(p, "/var/lib/dpkg/status.d/") {
+			statusPaths = append(statusPaths, p)
+		}
+	}
+	sort.Strings(statusPaths)
+	for _, p := range statusPaths {
+		debPkgs = append(debPkgs, parseDpkgStatus(files[p].Data)...)
+	}
+	if len(debPkgs) > 0 {
+		result := buildOSPackages("deb", debPkgs, osRelease)
+		combined.Packages = append(combined.Packages, result.Packages...)
+		combined.Relationships = append(combined.Relationships, result.Relationships...)
+	}
+	if f := files["/lib/apk/db/installed"]; f != nil {
+		result := buildOSPackages("apk", parseApkInstalled(f.Data), osRelease)
+		combined.Packages = append(combined.Packages, result.Packages...)
+		combined.Relationships = append(combined.Relationships, result.Relationships...)
+	}
+	return combined
+}
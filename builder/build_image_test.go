@@ -0,0 +1,363 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// testTarEntry is an entry of a tarball written by makeTestTar; a Link
+// makes it a hard link, and a name ending in "/" a directory.
+type testTarEntry struct {
+	Name     string
+	Contents string
+	Link     string
+}
+
+func makeTestTar(t *testing.T, entries []testTarEntry) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.Name, Mode: 0o644, Size: int64(len(e.Contents)), Typeflag: tar.TypeReg}
+		switch {
+		case e.Link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e.Link, 0
+		case strings.HasSuffix(e.Name, "/"):
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(e.Contents)); err != nil {
+			t.Fatalf("failed to write tar entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func gzipTestData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func mustMarshalJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return data
+}
+
+var testImageLayers = [][]testTarEntry{
+	{
+		{Name: "etc/"},
+		{Name: "etc/os-release", Contents: "ID=debian\nVERSION_ID=\"12\"\n"},
+		{Name: "var/lib/dpkg/status", Contents: "" +
+			"Package: libc6\nStatus: install ok installed\nVersion: 2.36-9\nArchitecture: amd64\n" +
+			"Maintainer: GNU Libc Maintainers <debian-glibc@lists.debian.org>\nSource: glibc\n\n" +
+			"Package: bash\nStatus: install ok installed\nVersion: 5.2.15-2\nArchitecture: amd64\n" +
+			"Pre-Depends: libc6 (>= 2.36), libtinfo6 (>= 6)\nHomepage: https://tiswww.case.edu/php/chet/bash/bashtop.html\n\n" +
+			"Package: removed\nStatus: deinstall ok config-files\nVersion: 1.0\n"},
+		{Name: "bin/bash", Contents: "bash"},
+		{Name: "tmp/cache/a", Contents: "a"},
+		{Name: "opt/app/old", Contents: "old"},
+		{Name: "opt/data", Contents: "data"},
+		{Name: "etc/passwd", Contents: "root:x:0:0::/root:/bin/bash\n"},
+		{Name: "usr/bin/ls", Contents: "ls"},
+	},
+	{
+		// layers repeat the entries of parent directories
+		{Name: "etc/"},
+		{Name: "etc/hosts", Contents: "127.0.0.1 localhost\n"},
+		{Name: "usr/"},
+		{Name: "usr/bin/"},
+		{Name: "usr/bin/app", Contents: "app"},
+		{Name: "opt/data/"},
+		{Name: "tmp/.wh.cache"},
+		{Name: "opt/app/.wh..wh..opq"},
+		{Name: "opt/app/new", Contents: "new"},
+		{Name: "bin/sh", Link: "bin/bash"},
+		{Name: "etc/os-release", Contents: "ID=debian\nVERSION_ID=\"12\"\nPRETTY_NAME=\"Debian 12\"\n"},
+	},
+}
+
+// makeTestOCILayout writes an OCI image layout directory with the test
+// image layers, and returns its path and manifest digest.
+func makeTestOCILayout(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	writeBlob := func(data []byte) string {
+		digest := testDigest(data)
+		writeTestFile(t, dir, filepath.Join("blobs", "sha256", strings.TrimPrefix(digest, "sha256:")), string(data))
+		return digest
+	}
+
+	layers := []map[string]interface{}{}
+	diffIDs := []string{}
+	for _, entries := range testImageLayers {
+		tarData := makeTestTar(t, entries)
+		diffIDs = append(diffIDs, testDigest(tarData))
+		blob := gzipTestData(t, tarData)
+		layers = append(layers, map[string]interface{}{
+			"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+			"digest":    writeBlob(blob),
+			"size":      len(blob),
+		})
+	}
+	config := writeBlob(mustMarshalJSON(t, map[string]interface{}{
+		"os":           "linux",
+		"architecture": "amd64",
+		"rootfs":       map[string]interface{}{"type": "layers", "diff_ids": diffIDs},
+		"history": []map[string]interface{}{
+			{"created_by": "ADD rootfs.tar /"},
+			{"created_by": "ENV X=1", "empty_layer": true},
+			{"created_by": "RUN cleanup"},
+		},
+	}))
+	manifest := writeBlob(mustMarshalJSON(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": config},
+		"layers":        layers,
+	}))
+	writeTestFile(t, dir, "oci-layout", `{"imageLayoutVersion":"1.0.0"}`)
+	writeTestFile(t, dir, "index.json", string(mustMarshalJSON(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests": []map[string]interface{}{{
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"digest":    manifest,
+			"annotations": map[string]string{
+				"io.containerd.image.name":          "docker.io/library/example:1.0",
+				"org.opencontainers.image.ref.name": "1.0",
+			},
+		}},
+	})))
+	return dir, manifest
+}
+
+func findImageFile(doc *spdx.Document, name string) (*spdx.Package, *spdx.File) {
+	for _, pkg := range doc.Packages {
+		for _, f := range pkg.Files {
+			if f.FileName == name {
+				return pkg, f
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ===== Image builder tests =====
+func TestBuildImageDocumentFromOCILayout(t *testing.T) {
+	dir, manifest := makeTestOCILayout(t)
+	config := &Config{
+		NamespacePrefix: "https://example.com/test/",
+		CreatorType:     "Tool",
+		Creator:         "github.com/spdx/tools-golang/builder",
+		TestValues:      map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+	doc, err := BuildImageDocument(dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if doc.DocumentNamespace != "https://example.com/test/docker.io/library/example-"+strings.TrimPrefix(manifest, "sha256:") {
+		t.Errorf("unexpected namespace %v", doc.DocumentNamespace)
+	}
+
+	image := doc.Packages[0]
+	if image.PackageName != "docker.io/library/example" || image.PackageVersion != "1.0" {
+		t.Errorf("unexpected image package %v %v", image.PackageName, image.PackageVersion)
+	}
+	if image.PrimaryPackagePurpose != "CONTAINER" {
+		t.Errorf("expected %v, got %v", "CONTAINER", image.PrimaryPackagePurpose)
+	}
+	wantPurl := "pkg:oci/example@sha256%3A" + strings.TrimPrefix(manifest, "sha256:") + "?repository_url=docker.io/library/example&tag=1.0"
+	if image.PackageExternalReferences[0].Locator != wantPurl {
+		t.Errorf("expected %v, got %v", wantPurl, image.PackageExternalReferences[0].Locator)
+	}
+	if !hasRelationship(doc.Relationships, "DOCUMENT", common.TypeRelationshipDescribe, image.PackageSPDXIdentifier) {
+		t.Errorf("expected document to describe image")
+	}
+
+	layer0, layer1 := doc.Packages[1], doc.Packages[2]
+	if layer1.PackageComment != "media type: application/vnd.oci.image.layer.v1.tar+gzip\ndiff ID: "+testDigest(makeTestTar(t, testImageLayers[1]))+"\ncreated by: RUN cleanup" {
+		t.Errorf("unexpected layer comment %q", layer1.PackageComment)
+	}
+	for _, layer := range []*spdx.Package{layer0, layer1} {
+		if !hasRelationship(doc.Relationships, image.PackageSPDXIdentifier, common.TypeRelationshipContains, layer.PackageSPDXIdentifier) {
+			t.Errorf("expected image to contain %v", layer.PackageSPDXIdentifier)
+		}
+	}
+
+	// files are attributed to the last layer writing them, and whiteouts
+	// remove files of lower layers, while repeated directory entries only
+	// replace a file at the same path
+	want := map[string]*spdx.Package{
+		"./bin/bash":            layer0,
+		"./bin/sh":              layer1,
+		"./etc/os-release":      layer1,
+		"./etc/passwd":          layer0,
+		"./etc/hosts":           layer1,
+		"./usr/bin/ls":          layer0,
+		"./usr/bin/app":         layer1,
+		"./opt/app/new":         layer1,
+		"./var/lib/dpkg/status": layer0,
+		"./tmp/cache/a":         nil,
+		"./opt/app/old":         nil,
+		"./opt/data":            nil,
+	}
+	for name, wantPkg := range want {
+		if pkg, _ := findImageFile(doc, name); pkg != wantPkg {
+			t.Errorf("%s: expected package %v, got %v", name, wantPkg, pkg)
+		}
+	}
+	_, bashFile := findImageFile(doc, "./bin/bash")
+	_, shFile := findImageFile(doc, "./bin/sh")
+	if shFile.Checksums[0] != bashFile.Checksums[0] {
+		t.Errorf("expected hard link to have its target's checksums")
+	}
+	if len(layer0.Files)+len(layer1.Files) != 9 {
+		t.Errorf("expected %d files, got %d", 9, len(layer0.Files)+len(layer1.Files))
+	}
+	if layer0.Files[0].FileSPDXIdentifier != "File0" || layer0.PackageVerificationCode == nil {
+		t.Errorf("unexpected layer files %+v", layer0.Files[0])
+	}
+
+	// OS packages
+	libc := findPackage(doc.Packages, "Package-deb-debian-libc6-2.36-9")
+	bash := findPackage(doc.Packages, "Package-deb-debian-bash-5.2.15-2")
+	if libc == nil || bash == nil {
+		t.Fatalf("expected deb packages, got %d packages", len(doc.Packages))
+	}
+	if len(doc.Packages) != 5 {
+		t.Errorf("expected %d packages, got %d", 5, len(doc.Packages))
+	}
+	if want := "pkg:deb/debian/libc6@2.36-9?arch=amd64&distro=debian-12&upstream=glibc"; libc.PackageExternalReferences[0].Locator != want {
+		t.Errorf("expected %v, got %v", want, libc.PackageExternalReferences[0].Locator)
+	}
+	if libc.PackageSupplier == nil || libc.PackageSupplier.Supplier != "GNU Libc Maintainers (debian-glibc@lists.debian.org)" {
+		t.Errorf("unexpected supplier %+v", libc.PackageSupplier)
+	}
+	if !hasRelationship(doc.Relationships, image.PackageSPDXIdentifier, common.TypeRelationshipContains, bash.PackageSPDXIdentifier) {
+		t.Errorf("expected image to contain bash")
+	}
+	if !hasRelationship(doc.Relationships, bash.PackageSPDXIdentifier, common.TypeRelationshipDependsOn, libc.PackageSPDXIdentifier) {
+		t.Errorf("expected bash to depend on libc6")
+	}
+}
+
+func TestBuildImageDocumentFromDockerSaveTarball(t *testing.T) {
+	layer := makeTestTar(t, []testTarEntry{
+		{Name: "lib/apk/db/installed", Contents: "" +
+			"P:musl\nV:1.2.4-r2\nA:x86_64\nL:MIT\no:musl\np:so:libc.musl-x86_64.so.1=1\n\n" +
+			"P:busybox\nV:1.36.1-r5\nA:x86_64\nL:GPL-2.0-only\nD:so:libc.musl-x86_64.so.1\n\n"},
+		{Name: "etc/os-release", Contents: "ID=alpine\nVERSION_ID=3.18.4\n"},
+	})
+	config := mustMarshalJSON(t, map[string]interface{}{"os": "linux", "architecture": "amd64"})
+	configHex := strings.TrimPrefix(testDigest(config), "sha256:")
+	tarball := makeTestTar(t, []testTarEntry{
+		{Name: "manifest.json", Contents: string(mustMarshalJSON(t, []map[string]interface{}{{
+			"Config":   configHex + ".json",
+			"RepoTags": []string{"alpine:3.18"},
+			"Layers":   []string{"abc/layer.tar"},
+		}}))},
+		{Name: configHex + ".json", Contents: string(config)},
+		{Name: "abc/"},
+		{Name: "abc/layer.tar", Contents: string(layer)},
+	})
+	p := filepath.Join(t.TempDir(), "image.tar")
+	if err := os.WriteFile(p, tarball, 0o644); err != nil {
+		t.Fatalf("failed to write tarball: %v", err)
+	}
+
+	doc, err := BuildImageDocument(p, &Config{PathsIgnored: []string{"/etc/"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	image, l := doc.Packages[0], doc.Packages[1]
+	if image.PackageName != "alpine" || image.PackageVersion != "3.18" || image.PackageChecksums[0].Value != configHex {
+		t.Errorf("unexpected image package %+v", image)
+	}
+	if l.PackageName != testDigest(layer) {
+		t.Errorf("expected computed layer digest %v, got %v", testDigest(layer), l.PackageName)
+	}
+	if len(l.Files) != 1 || l.Files[0].FileName != "./lib/apk/db/installed" {
+		t.Errorf("expected ignored paths to be excluded, got %+v", l.Files)
+	}
+
+	musl := findPackage(doc.Packages, "Package-apk-alpine-musl-1.2.4-r2")
+	busybox := findPackage(doc.Packages, "Package-apk-alpine-busybox-1.36.1-r5")
+	if musl == nil || busybox == nil {
+		t.Fatalf("expected apk packages")
+	}
+	if musl.PackageLicenseDeclared != "MIT" {
+		t.Errorf("expected %v, got %v", "MIT", musl.PackageLicenseDeclared)
+	}
+	if want := "pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64&distro=alpine-3.18.4&upstream=musl"; musl.PackageExternalReferences[0].Locator != want {
+		t.Errorf("expected %v, got %v", want, musl.PackageExternalReferences[0].Locator)
+	}
+	if !hasRelationship(doc.Relationships, busybox.PackageSPDXIdentifier, common.TypeRelationshipDependsOn, musl.PackageSPDXIdentifier) {
+		t.Errorf("expected busybox to depend on musl through its provided library")
+	}
+}
+
+func TestBuildImageDocumentFailsWithMismatchedLayerDigest(t *testing.T) {
+	dir, _ := makeTestOCILayout(t)
+	// corrupt the first layer blob without renaming it
+	blobs, err := filepath.Glob(filepath.Join(dir, "blobs", "sha256", "*"))
+	if err != nil {
+		t.Fatalf("failed to list blobs: %v", err)
+	}
+	for _, b := range blobs {
+		data, err := os.ReadFile(b)
+		if err != nil {
+			t.Fatalf("failed to read blob: %v", err)
+		}
+		if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			if err := os.WriteFile(b, append(data, 0), 0o644); err != nil {
+				t.Fatalf("failed to write blob: %v", err)
+			}
+		}
+	}
+	if _, err := BuildImageDocument(dir, &Config{}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected digest mismatch error, got %v", err)
+	}
+}
+
+func TestBuildImageDocumentFailsWithoutImage(t *testing.T) {
+	if _, err := BuildImageDocument(t.TempDir(), &Config{}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+	if _, err := BuildImageDocument("./oops/nonexistent", &Config{}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// binaryInfo is the dynamic linkage information of an executable or
+// shared library.
+type binaryInfo struct {
+	// Format is "elf", "pe" or "macho"; libraries are only resolved to
+	// binaries of the same format.
+	Format string
+	// Names are the names, other than its file name, by which other
+	// binaries may link to this one: an ELF SONAME or a Mach-O install name.
+	Names []string
+	// Needed lists the libraries the binary links to, in the order given:
+	// ELF DT_NEEDED entries, PE imported (and delay-loaded) DLLs, or Mach-O
+	// dylib install names.
+	Needed []string
+}
+
+// Mach-O load commands referring to a dylib, which debug/macho only
+// partly decodes.
+const (
+	machoLoadDylib       = 0xc
+	machoIDDylib         = 0xd
+	machoLoadWeakDylib   = 0x80000018
+	machoReexportDylib   = 0x8000001f
+	machoLazyLoadDylib   = 0x20
+	machoLoadUpwardDylib = 0x80000023
+)
+
+// readBinaryInfo reads the linkage information of the file at p. It
+// returns nil, without error, if the file is not an ELF, PE or Mach-O
+// binary or cannot be parsed as one.
+func readBinaryInfo(p string) (*binaryInfo, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		// too short to be a binary
+		return nil, nil
+	}
+
+	var info *binaryInfo
+	switch {
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		info = readELFInfo(f)
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		info = readPEInfo(f)
+	default:
+		be, le := binary.BigEndian.Uint32(magic), binary.LittleEndian.Uint32(magic)
+		switch {
+		case be == macho.Magic32 || be == macho.Magic64 || le == macho.Magic32 || le == macho.Magic64:
+			if mf, err := macho.NewFile(f); err == nil {
+				info = readMachOInfo([]*macho.File{mf})
+			}
+		case be == macho.MagicFat:
+			// Java class files share this magic number, and fail to parse
+			if ff, err := macho.NewFatFile(f); err == nil {
+				files := []*macho.File{}
+				for _, arch := range ff.Arches {
+					files = append(files, arch.File)
+				}
+				info = readMachOInfo(files)
+			}
+		}
+	}
+	return info, nil
+}
+
+func readELFInfo(r io.ReaderAt) *binaryInfo {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return nil
+	}
+	info := &binaryInfo{Format: "elf"}
+	// files without a dynamic section, such as static executables and
+	// object files, have no DT_ entries
+	info.Names, _ = f.DynString(elf.DT_SONAME)
+	info.Needed, _ = f.DynString(elf.DT_NEEDED)
+	return info
+}
+
+func readPEInfo(r io.ReaderAt) *binaryInfo {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return nil
+	}
+	// pe.File.ImportedLibraries is not implemented, and ImportedSymbols
+	// skips DLLs imported by ordinal, so read the import directories
+	var dirs []pe.DataDirectory
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = oh.DataDirectory[:min(int(oh.NumberOfRvaAndSizes), len(oh.DataDirectory))]
+	case *pe.OptionalHeader64:
+		dirs = oh.DataDirectory[:min(int(oh.NumberOfRvaAndSizes), len(oh.DataDirectory))]
+	}
+
+	info := &binaryInfo{Format: "pe"}
+	// import descriptors are 20 bytes, with the DLL name's RVA at offset
+	// 12; delay-load descriptors are 32 bytes, with the name at offset 4
+	for _, d := range []struct {
+		entry, size, nameOffset int
+	}{
+		{pe.IMAGE_DIRECTORY_ENTRY_IMPORT, 20, 12},
+		{pe.IMAGE_DIRECTORY_ENTRY_DELAY_IMPORT, 32, 4},
+	} {
+		if d.entry >= len(dirs) || dirs[d.entry].VirtualAddress == 0 {
+			continue
+		}
+		data := peDataAt(f, dirs[d.entry].VirtualAddress)
+		for len(data) >= d.size {
+			desc := data[:d.size]
+			data = data[d.size:]
+			if bytes.Equal(desc, make([]byte, d.size)) {
+				break
+			}
+			name := peDataAt(f, binary.LittleEndian.Uint32(desc[d.nameOffset:]))
+			if i := bytes.IndexByte(name, 0); i > 0 {
+				info.Needed = append(info.Needed, string(name[:i]))
+			}
+		}
+	}
+	return info
+}
+
+// peDataAt returns the contents of the section of f containing the
+// relative virtual address rva, from that address on.
+func peDataAt(f *pe.File, rva uint32) []byte {
+	for _, s := range f.Sections {
+		if rva < s.VirtualAddress || rva >= s.VirtualAddress+max(s.VirtualSize, s.Size) {
+			continue
+		}
+		data, err := s.Data()
+		if err != nil || int(rva-s.VirtualAddress) >= len(data) {
+			return nil
+		}
+		return data[rva-s.VirtualAddress:]
+	}
+	return nil
+}
+
+// readMachOInfo reads the dylib load commands of the architectures of a
+// Mach-O binary, all of which are merged.
+func readMachOInfo(files []*macho.File) *binaryInfo {
+	info := &binaryInfo{Format: "macho"}
+	seen := map[string]bool{}
+	for _, f := range files {
+		for _, l := range f.Loads {
+			raw := l.Raw()
+			if len(raw) < 12 {
+				continue
+			}
+			cmd := f.ByteOrder.Uint32(raw)
+			offset := f.ByteOrder.Uint32(raw[8:])
+			if offset >= uint32(len(raw)) {
+				continue
+			}
+			name := raw[offset:]
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+			switch cmd {
+			case machoIDDylib:
+				if !seen["id:"+string(name)] {
+					seen["id:"+string(name)] = true
+					info.Names = append(info.Names, string(name))
+				}
+			case machoLoadDylib, machoLoadWeakDylib, machoReexportDylib, machoLazyLoadDylib, machoLoadUpwardDylib:
+				if !seen[string(name)] {
+					seen[string(name)] = true
+					info.Needed = append(info.Needed, string(name))
+				}
+			}
+		}
+	}
+	return info
+}
+
+// binaryLinkKey returns the key used to match a library name to a
+// binary of the given format. DLL names are not case sensitive.
+func binaryLinkKey(format string, name string) string {
+	if format == "pe" {
+		return format + ":" + strings.ToLower(name)
+	}
+	return format + ":" + name
+}
+
+// addLinkageRelationships reads the linkage information of the files of
+// pkgs, relative to dirRoot, marking each binary with the BINARY file type,
+// and returns a DYNAMIC_LINK relationship from each binary to each library
+// it links to. A library is resolved to the binary of the same format with
+// that SONAME or install name, or failing that with that file name; if none
+// is included, the relationship's target is NOASSERTION, with the library's
+// name in its comment. Recorded symbolic links, given by symlinks, are not
+// read.
+func addLinkageRelationships(dirRoot string, pkgs []*spdx.Package, symlinks map[string]utils.Symlink) ([]*spdx.Relationship, error) {
+	type binaryFile struct {
+		file *spdx.File
+		info *binaryInfo
+	}
+	binaries := []binaryFile{}
+	byName := map[string]common.ElementID{}
+	byFileName := map[string]common.ElementID{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			if _, ok := symlinks[strings.TrimPrefix(f.FileName, ".")]; ok {
+				continue
+			}
+			info, err := readBinaryInfo(filepath.Join(dirRoot, filepath.FromSlash(f.FileName)))
+			if err != nil {
+				return nil, err
+			}
+			if info == nil {
+				continue
+			}
+			if !containsString(f.FileTypes, "BINARY") {
+				f.FileTypes = append(f.FileTypes, "BINARY")
+			}
+			binaries = append(binaries, binaryFile{file: f, info: info})
+			// the first file in document order wins, if several provide
+			// the same name
+			for _, name := range info.Names {
+				if key := binaryLinkKey(info.Format, name); byName[key] == "" {
+					byName[key] = f.FileSPDXIdentifier
+				}
+			}
+			if key := binaryLinkKey(info.Format, path.Base(f.FileName)); byFileName[key] == "" {
+				byFileName[key] = f.FileSPDXIdentifier
+			}
+		}
+	}
+
+	rlns := []*spdx.Relationship{}
+	for _, b := range binaries {
+		seen := map[string]bool{}
+		for _, lib := range b.info.Needed {
+			if seen[lib] {
+				continue
+			}
+			seen[lib] = true
+
+			id := byName[binaryLinkKey(b.info.Format, lib)]
+			if id == "" {
+				// Mach-O install names and Windows paths are resolved
+				// by their last element
+				base := lib[strings.LastIndexAny(lib, `/\`)+1:]
+				id = byFileName[binaryLinkKey(b.info.Format, base)]
+			}
+			rln := &spdx.Relationship{
+				RefA:         common.MakeDocElementID("", string(b.file.FileSPDXIdentifier)),
+				Relationship: common.TypeRelationshipDynamicLink,
+			}
+			if id == "" || id == b.file.FileSPDXIdentifier {
+				rln.RefB = common.MakeDocElementSpecial("NOASSERTION")
+				rln.RelationshipComment = fmt.Sprintf("library %s not found", lib)
+			} else {
+				rln.RefB = common.MakeDocElementID("", string(id))
+			}
+			rlns = append(rlns, rln)
+		}
+	}
+	return rlns, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// findLinks returns the targets of the DYNAMIC_LINK relationships from a,
+// with the comments of unresolved targets in place of their IDs.
+func findLinks(rlns []*spdx.Relationship, a common.ElementID) []string {
+	targets := []string{}
+	for _, rln := range rlns {
+		if rln.Relationship != common.TypeRelationshipDynamicLink || rln.RefA.ElementRefID != a {
+			continue
+		}
+		if rln.RefB.SpecialID != "" {
+			targets = append(targets, rln.RefB.SpecialID+": "+rln.RelationshipComment)
+		} else {
+			targets = append(targets, string(rln.RefB.ElementRefID))
+		}
+	}
+	return targets
+}
+
+// ===== Linkage analysis tests =====
+func TestReadBinaryInfo(t *testing.T) {
+	for p, want := range map[string]*binaryInfo{
+		"../testdata/binaries/elf/bin/hello":       {Format: "elf", Needed: []string{"libgreet.so.1", "libmissing.so.2"}},
+		"../testdata/binaries/elf/lib/libgreet.so": {Format: "elf", Names: []string{"libgreet.so.1"}},
+		"../testdata/binaries/pe/app.exe":          {Format: "pe", Needed: []string{"GREET.DLL", "KERNEL32.dll", "delayed.dll"}},
+		"../testdata/binaries/macho/libgreet.dylib": {
+			Format: "macho",
+			Names:  []string{"@rpath/libgreet.1.dylib"},
+			Needed: []string{"/usr/lib/libSystem.B.dylib"},
+		},
+		"../testdata/binaries/elf/bin/main.c": nil,
+	} {
+		got, err := readBinaryInfo(p)
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", p, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", p, want, got)
+		}
+	}
+}
+
+func TestBuildCanRecordDynamicLinks(t *testing.T) {
+	config := &Config{
+		LinkageAnalysis: true,
+		TestValues:      map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+	doc, err := Build("binaries", "../testdata/binaries", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	ids := map[string]common.ElementID{}
+	for _, f := range doc.Packages[0].Files {
+		ids[f.FileName] = f.FileSPDXIdentifier
+		wantTypes := []string{"BINARY"}
+		if f.FileName == "./elf/bin/main.c" {
+			wantTypes = nil
+		}
+		if !reflect.DeepEqual(f.FileTypes, wantTypes) {
+			t.Errorf("%s: expected %v, got %v", f.FileName, wantTypes, f.FileTypes)
+		}
+	}
+
+	for name, want := range map[string][]string{
+		"./elf/bin/hello": {string(ids["./elf/lib/libgreet.so"]), "NOASSERTION: library libmissing.so.2 not found"},
+		"./pe/app.exe": {
+			string(ids["./pe/greet.dll"]),
+			"NOASSERTION: library KERNEL32.dll not found",
+			"NOASSERTION: library delayed.dll not found",
+		},
+		"./macho/app": {string(ids["./macho/libgreet.dylib"]), "NOASSERTION: library /usr/lib/libSystem.B.dylib not found"},
+	} {
+		if got := findLinks(doc.Relationships, ids[name]); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestBuildOmitsDynamicLinksByDefault(t *testing.T) {
+	doc, err := Build("binaries", "../testdata/binaries", &Config{TestValues: map[string]string{"Created": "2018-10-19T04:38:00Z"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, f := range doc.Packages[0].Files {
+		if f.FileTypes != nil {
+			t.Errorf("%s: expected no file types, got %v", f.FileName, f.FileTypes)
+		}
+	}
+	if len(doc.Relationships) != 1 {
+		t.Errorf("expected %d, got %d", 1, len(doc.Relationships))
+	}
+}
@@ -3,8 +3,6 @@
 package builder
 
 import (
-	"fmt"
-
 	"github.com/spdx/tools-golang/spdx"
 	"github.com/spdx/tools-golang/spdx/v2/common"
 	"github.com/spdx/tools-golang/utils"
@@ -38,6 +36,16 @@ func BuildPackageSection(packageName string, dirRoot string, pathsIgnore []strin
 		fileNumber++
 	}
 
+	return buildFilesPackage(packageName, makeElementID("Package", packageName), files)
+}
+
+// buildFilesPackage creates a Package with the given name and identifier
+// containing files, with its verification code computed from those files.
+func buildFilesPackage(packageName string, id common.ElementID, files []*spdx.File) (*spdx.Package, error) {
+	if files == nil {
+		files = []*spdx.File{}
+	}
+
 	// get the verification code
 	code, err := utils.GetVerificationCode(files, "")
 	if err != nil {
@@ -47,7 +55,7 @@ func BuildPackageSection(packageName string, dirRoot string, pathsIgnore []strin
 	// now build the package section
 	pkg := &spdx.Package{
 		PackageName:                 packageName,
-		PackageSPDXIdentifier:       common.ElementID(fmt.Sprintf("Package-%s", packageName)),
+		PackageSPDXIdentifier:       id,
 		PackageDownloadLocation:     "NOASSERTION",
 		FilesAnalyzed:               true,
 		IsFilesAnalyzedTagPresent:   true,
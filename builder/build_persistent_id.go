@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// emptyGitTree is the hash of the git tree with no entries.
+const emptyGitTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// makeGitoidLocators returns the gitoid locators for the SHA-1 and SHA-256
+// forms of blob, followed by its SWHID.
+func makeGitoidLocators(blob utils.GitBlob) []string {
+	return []string{
+		"gitoid:blob:sha1:" + blob.SHA1,
+		"gitoid:blob:sha256:" + blob.SHA256,
+		"swh:1:cnt:" + blob.SHA1,
+	}
+}
+
+// makePersistentIDExternalRef returns a PERSISTENT-ID external reference
+// for a gitoid or SWHID locator.
+func makePersistentIDExternalRef(locator string) *spdx.PackageExternalReference {
+	refType := common.TypePersistentIdSwh
+	if strings.HasPrefix(locator, "gitoid:") {
+		refType = common.TypePersistentIdGitoid
+	}
+	return &spdx.PackageExternalReference{
+		Category: common.CategoryPersistentId,
+		RefType:  refType,
+		Locator:  locator,
+	}
+}
+
+// hasExternalRefLocator reports whether pkg already has an external
+// reference with the given locator.
+func hasExternalRefLocator(pkg *spdx.Package, locator string) bool {
+	for _, ref := range pkg.PackageExternalReferences {
+		if ref.Locator == locator {
+			return true
+		}
+	}
+	return false
+}
+
+// addPersistentIDs computes the gitoids and SWHID of each file in pkgs,
+// recording them as annotations dated created, and the SWHID of the
+// directory of each package, recording it as a PERSISTENT-ID external
+// reference. pkgs[0] describes dirRoot itself and pkgs[i] the directory
+// roots[i-1]; each directory's SWHID covers all the included files within
+// it, including those in nested packages. Recorded symbolic links, given
+// by symlinks, are hashed as links, as git does.
+func addPersistentIDs(dirRoot string, pkgs []*spdx.Package, roots []packageRoot, symlinks map[string]utils.Symlink, created string) error {
+	blobs := map[string]utils.GitBlob{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			var blob utils.GitBlob
+			if link, ok := symlinks[strings.TrimPrefix(f.FileName, ".")]; ok {
+				blob = utils.GetGitBlobForSymlink(link.Target)
+			} else {
+				var err error
+				if blob, err = utils.GetGitBlobForFilePath(filepath.Join(dirRoot, f.FileName)); err != nil {
+					return err
+				}
+			}
+			blobs[strings.TrimPrefix(f.FileName, ".")] = blob
+
+			for _, locator := range makeGitoidLocators(blob) {
+				f.Annotations = append(f.Annotations, spdx.Annotation{
+					Annotator: common.Annotator{
+						Annotator:     "github.com/spdx/tools-golang/builder",
+						AnnotatorType: "Tool",
+					},
+					AnnotationDate:           created,
+					AnnotationType:           "OTHER",
+					AnnotationSPDXIdentifier: common.MakeDocElementID("", string(f.FileSPDXIdentifier)),
+					AnnotationComment:        locator,
+				})
+			}
+		}
+	}
+
+	trees, err := utils.GetGitTreeHashes(blobs)
+	if err != nil {
+		return err
+	}
+	for i, pkg := range pkgs {
+		dir := ""
+		if i > 0 {
+			dir = roots[i-1].Path
+		}
+		tree, ok := trees[dir]
+		if !ok {
+			tree = emptyGitTree
+		}
+		locator := "swh:1:dir:" + tree
+		if !hasExternalRefLocator(pkg, locator) {
+			pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, makePersistentIDExternalRef(locator))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Persistent identifier builder tests =====
+func TestBuildCanRecordPersistentIDs(t *testing.T) {
+	dir := newTestGitRepo(t)
+
+	config := &Config{
+		GitMetadata:         true,
+		ExcludeGitUntracked: true,
+		PersistentIDs:       true,
+		PackageRoots:        map[string]string{"src": "src"},
+		TestValues:          map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+	doc, err := Build("repo", dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	top, src := doc.Packages[0], doc.Packages[1]
+
+	// the tracked files are unchanged, so the SWHIDs computed from them are
+	// those of the HEAD commit's trees and are not repeated
+	if len(top.PackageExternalReferences) != 2 {
+		t.Fatalf("expected %d, got %+v", 2, top.PackageExternalReferences)
+	}
+	if len(src.PackageExternalReferences) != 1 {
+		t.Fatalf("expected %d, got %+v", 1, src.PackageExternalReferences)
+	}
+	if want := "swh:1:dir:" + runGit(t, dir, "rev-parse", "HEAD:src"); src.PackageExternalReferences[0].Locator != want {
+		t.Errorf("expected %v, got %v", want, src.PackageExternalReferences[0].Locator)
+	}
+
+	// a modified file changes the computed SWHID of its directory
+	writeTestFile(t, dir, "src/a.go", "package src\n\nconst A = 1\n")
+	doc, err = Build("repo", dir, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	src = doc.Packages[1]
+	if len(src.PackageExternalReferences) != 2 {
+		t.Fatalf("expected %d, got %+v", 2, src.PackageExternalReferences)
+	}
+	ref := src.PackageExternalReferences[1]
+	if ref.Category != common.CategoryPersistentId || ref.RefType != common.TypePersistentIdSwh {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+	if want := "swh:1:dir:" + runGit(t, dir, "rev-parse", "HEAD:src"); ref.Locator == want {
+		t.Errorf("expected SWHID of modified directory, got committed tree %v", ref.Locator)
+	}
+
+	f := src.Files[0]
+	if f.FileName != "./src/a.go" {
+		t.Fatalf("expected %v, got %v", "./src/a.go", f.FileName)
+	}
+	if len(f.Annotations) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(f.Annotations))
+	}
+	blob := runGit(t, dir, "hash-object", "src/a.go")
+	want := []string{"gitoid:blob:sha1:" + blob, "", "swh:1:cnt:" + blob}
+	for i, ann := range f.Annotations {
+		if want[i] != "" && ann.AnnotationComment != want[i] {
+			t.Errorf("expected %v, got %v", want[i], ann.AnnotationComment)
+		}
+		if ann.AnnotationSPDXIdentifier.ElementRefID != f.FileSPDXIdentifier {
+			t.Errorf("expected %v, got %v", f.FileSPDXIdentifier, ann.AnnotationSPDXIdentifier)
+		}
+		if ann.AnnotationDate != "2018-10-19T04:38:00Z" || ann.AnnotationType != "OTHER" {
+			t.Errorf("unexpected annotation %+v", ann)
+		}
+	}
+}
+
+func TestBuildGoBinaryDocumentCanRecordPersistentIDs(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("cannot locate test executable: %v", err)
+	}
+	doc, err := BuildGoBinaryDocument(exe, &Config{PersistentIDs: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	refs := doc.Packages[0].PackageExternalReferences
+	types := []string{}
+	for _, ref := range refs {
+		if ref.Category == common.CategoryPersistentId {
+			types = append(types, ref.RefType)
+		}
+	}
+	if len(types) != 3 || types[0] != common.TypePersistentIdGitoid || types[1] != common.TypePersistentIdGitoid || types[2] != common.TypePersistentIdSwh {
+		t.Errorf("expected two gitoids and a SWHID, got %v", types)
+	}
+}
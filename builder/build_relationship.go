@@ -3,8 +3,6 @@
 package builder
 
 import (
-	"fmt"
-
 	"github.com/spdx/tools-golang/spdx"
 	"github.com/spdx/tools-golang/spdx/v2/common"
 )
@@ -16,7 +14,7 @@ import (
 func BuildRelationshipSection(packageName string) (*spdx.Relationship, error) {
 	rln := &spdx.Relationship{
 		RefA:         common.MakeDocElementID("", "DOCUMENT"),
-		RefB:         common.MakeDocElementID("", fmt.Sprintf("Package-%s", packageName)),
+		RefB:         common.MakeDocElementID("", string(makeElementID("Package", packageName))),
 		Relationship: "DESCRIBES",
 	}
 
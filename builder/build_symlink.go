@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// buildSymlinkFileSection creates the File for a symbolic link recorded by
+// the utils.SymlinksRecord policy. As in git, the file's contents are taken
+// to be the link's target, from which its checksums are computed.
+func buildSymlinkFileSection(filePath string, link utils.Symlink, fileNumber int) *spdx.File {
+	target := []byte(link.Target)
+	return &spdx.File{
+		FileName:           filePath,
+		FileSPDXIdentifier: common.ElementID(fmt.Sprintf("File%d", fileNumber)),
+		Checksums: []common.Checksum{
+			{Algorithm: common.SHA1, Value: fmt.Sprintf("%x", sha1.Sum(target))},
+			{Algorithm: common.SHA256, Value: fmt.Sprintf("%x", sha256.Sum256(target))},
+			{Algorithm: common.MD5, Value: fmt.Sprintf("%x", md5.Sum(target))},
+		},
+		LicenseConcluded:   "NOASSERTION",
+		LicenseInfoInFiles: []string{"NOASSERTION"},
+		FileCopyrightText:  "NOASSERTION",
+		FileComment:        "symbolic link to " + link.Target,
+	}
+}
+
+// mergeSymlinkPaths adds the paths of links to shortPaths, keeping the
+// order in which utils.GetAllFilePaths walks the directory, and returns
+// the links by path.
+func mergeSymlinkPaths(shortPaths []string, links []utils.Symlink) ([]string, map[string]utils.Symlink) {
+	if len(links) == 0 {
+		return shortPaths, nil
+	}
+	symlinks := map[string]utils.Symlink{}
+	merged := append([]string{}, shortPaths...)
+	for _, link := range links {
+		symlinks[link.Path] = link
+		merged = append(merged, link.Path)
+	}
+	// the directory is walked depth first, with each directory's entries
+	// in name order
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := strings.Split(merged[i], "/"), strings.Split(merged[j], "/")
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return merged, symlinks
+}
+
+// buildSymlinkRelationships returns a relationship for each recorded
+// symbolic link in pkgs: COPY_OF the file it resolves to, if that file is
+// in the document, and otherwise OTHER, with NOASSERTION as its target.
+// The link's target is given in the relationship comment.
+func buildSymlinkRelationships(pkgs []*spdx.Package, symlinks map[string]utils.Symlink) []*spdx.Relationship {
+	rlns := []*spdx.Relationship{}
+	if len(symlinks) == 0 {
+		return rlns
+	}
+	ids := map[string]common.ElementID{}
+	linkFiles := []*spdx.File{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			ids[f.FileName] = f.FileSPDXIdentifier
+			if _, ok := symlinks[strings.TrimPrefix(f.FileName, ".")]; ok {
+				linkFiles = append(linkFiles, f)
+			}
+		}
+	}
+	sort.SliceStable(linkFiles, func(i, j int) bool {
+		return linkFiles[i].FileName < linkFiles[j].FileName
+	})
+
+	for _, f := range linkFiles {
+		link := symlinks[strings.TrimPrefix(f.FileName, ".")]
+		rln := &spdx.Relationship{
+			RefA:                common.MakeDocElementID("", string(f.FileSPDXIdentifier)),
+			RelationshipComment: "symbolic link to " + link.Target,
+		}
+		target, ok := ids["."+link.Resolved]
+		if link.Resolved != "" && !link.IsDir && ok {
+			rln.RefB = common.MakeDocElementID("", string(target))
+			rln.Relationship = common.TypeRelationshipCopyOf
+		} else {
+			rln.RefB = common.MakeDocElementSpecial("NOASSERTION")
+			rln.Relationship = common.TypeRelationshipOther
+		}
+		rlns = append(rlns, rln)
+	}
+	return rlns
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// makeSymlinkTestTree writes a tree with symbolic links to a file, to a
+// directory and to a file outside of the tree, returning its path.
+func makeSymlinkTestTree(t *testing.T) string {
+	t.Helper()
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "root")
+	writeTestFile(t, parent, "outside.txt", "outside\n")
+	writeTestFile(t, dir, "a.txt", "a\n")
+	writeTestFile(t, dir, "sub/b.txt", "b\n")
+	for link, target := range map[string]string{
+		"a-link":       "a.txt",
+		"sub-link":     "sub",
+		"outside-link": "../outside.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skipf("cannot create symbolic links: %v", err)
+		}
+	}
+	return dir
+}
+
+func findFile(doc *spdx.Document, name string) *spdx.File {
+	for _, pkg := range doc.Packages {
+		for _, f := range pkg.Files {
+			if f.FileName == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// ===== Symbolic link builder tests =====
+func TestBuildCanRecordSymlinks(t *testing.T) {
+	dir := makeSymlinkTestTree(t)
+	doc, err := Build("root", dir, &Config{Symlinks: utils.SymlinksRecord})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	names := []string{}
+	for _, f := range doc.Packages[0].Files {
+		names = append(names, fmt.Sprintf("%s=%s", f.FileName, f.FileSPDXIdentifier))
+	}
+	want := "[./a-link=File0 ./a.txt=File1 ./outside-link=File2 ./sub/b.txt=File3 ./sub-link=File4]"
+	if fmt.Sprint(names) != want {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+
+	link := findFile(doc, "./a-link")
+	if want := fmt.Sprintf("%x", sha1.Sum([]byte("a.txt"))); link.Checksums[0].Value != want {
+		t.Errorf("expected checksum of link target %v, got %v", want, link.Checksums[0].Value)
+	}
+	if link.FileComment != "symbolic link to a.txt" {
+		t.Errorf("expected %v, got %v", "symbolic link to a.txt", link.FileComment)
+	}
+
+	rlns := map[common.ElementID]*spdx.Relationship{}
+	for _, rln := range doc.Relationships {
+		if rln.Relationship == common.TypeRelationshipCopyOf || rln.Relationship == common.TypeRelationshipOther {
+			rlns[rln.RefA.ElementRefID] = rln
+		}
+	}
+	if len(rlns) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(rlns))
+	}
+	if rln := rlns["File0"]; rln.Relationship != common.TypeRelationshipCopyOf || rln.RefB.ElementRefID != "File1" {
+		t.Errorf("expected a-link to be COPY_OF a.txt, got %+v", rln)
+	}
+	for id, target := range map[common.ElementID]string{"File2": "../outside.txt", "File4": "sub"} {
+		rln := rlns[id]
+		if rln.Relationship != common.TypeRelationshipOther || rln.RefB.SpecialID != "NOASSERTION" || rln.RelationshipComment != "symbolic link to "+target {
+			t.Errorf("%s: unexpected relationship %+v", id, rln)
+		}
+	}
+}
+
+func TestBuildCanFollowSymlinks(t *testing.T) {
+	dir := makeSymlinkTestTree(t)
+	doc, err := Build("root", dir, &Config{Symlinks: utils.SymlinksFollow})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if f := findFile(doc, "./sub-link/b.txt"); f == nil || f.Checksums[0] != findFile(doc, "./sub/b.txt").Checksums[0] {
+		t.Errorf("expected file within followed directory, got %+v", f)
+	}
+	if findFile(doc, "./outside-link") != nil {
+		t.Errorf("expected link outside of root not to be followed")
+	}
+	if len(doc.Relationships) != 1 {
+		t.Errorf("expected %d, got %d", 1, len(doc.Relationships))
+	}
+}
+
+func TestBuildHashesRecordedSymlinksAsGitDoes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := makeSymlinkTestTree(t)
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "links")
+
+	doc, err := Build("root", dir, &Config{Symlinks: utils.SymlinksRecord, PersistentIDs: true, PathsIgnored: []string{"/.git/"}})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := "swh:1:dir:" + runGit(t, dir, "rev-parse", "HEAD^{tree}")
+	if !hasExternalRefLocator(doc.Packages[0], want) {
+		t.Errorf("expected %v, got %+v", want, doc.Packages[0].PackageExternalReferences)
+	}
+	if got := findFile(doc, "./a-link").Annotations[2].AnnotationComment; got != "swh:1:cnt:"+runGit(t, dir, "rev-parse", "HEAD:a-link") {
+		t.Errorf("unexpected SWHID %v", got)
+	}
+}
@@ -47,7 +47,7 @@ func TestBuildCreatesDocument(t *testing.T) {
 	if doc.DocumentName != "project1" {
 		t.Errorf("expected %s, got %s", "project1", doc.DocumentName)
 	}
-	wantNamespace := fmt.Sprintf("https://github.com/swinslow/spdx-docs/spdx-go/testdata-project1-%s", wantVerificationCode)
+	wantNamespace := fmt.Sprintf("https://github.com/swinslow/spdx-docs/spdx-go/testdata-project1-%s", wantVerificationCode.Value)
 	if doc.DocumentNamespace != wantNamespace {
 		t.Errorf("expected %s, got %s", wantNamespace, doc.DocumentNamespace)
 	}
@@ -0,0 +1,730 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gitRepo reads a local git repository directly from its .git directory,
+// without running git or accessing the network. Only repositories using
+// SHA-1 object names are supported.
+type gitRepo struct {
+	// workTree is the absolute path of the working tree.
+	workTree string
+	// gitDir holds HEAD and the index; for a linked worktree it differs
+	// from commonDir, which holds the objects, refs and config.
+	gitDir    string
+	commonDir string
+
+	packs       []*gitPack
+	packsLoaded bool
+	packedRefs  map[string]string
+}
+
+var errGitRefNotFound = errors.New("ref not found")
+
+// gitTag is a tag pointing (possibly through an annotated tag object) to a
+// commit.
+type gitTag struct {
+	Name      string
+	Commit    string
+	Annotated bool
+}
+
+// findGitRepo looks for the git repository whose working tree contains
+// dir, searching dir and its parents. It returns nil and no error if dir
+// is not within a git working tree.
+func findGitRepo(dir string) (*gitRepo, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		dotGit := filepath.Join(d, ".git")
+		fi, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !fi.IsDir() {
+				// a linked worktree or submodule, where .git is a file
+				// containing "gitdir: <path>"
+				data, err := os.ReadFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+				p, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return nil, fmt.Errorf("invalid .git file %s", dotGit)
+				}
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(d, p)
+				}
+				gitDir = p
+			}
+			return openGitRepo(d, gitDir)
+		}
+		if filepath.Dir(d) == d {
+			return nil, nil
+		}
+	}
+}
+
+// openGitRepo opens the repository with the given working tree and git
+// directory.
+func openGitRepo(workTree string, gitDir string) (*gitRepo, error) {
+	r := &gitRepo{workTree: workTree, gitDir: gitDir, commonDir: gitDir}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		p := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(gitDir, p)
+		}
+		r.commonDir = p
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, fmt.Errorf("invalid git directory %s: %v", gitDir, err)
+	}
+
+	cfg, err := r.config()
+	if err != nil {
+		return nil, err
+	}
+	if f := cfg["extensions.objectformat"]; len(f) > 0 && f[len(f)-1] != "sha1" {
+		return nil, fmt.Errorf("git object format %s is not supported", f[len(f)-1])
+	}
+	return r, nil
+}
+
+// config parses the repository's config file into a map from lower-cased
+// "section.subsection.key" names to their values, in order. Subsection
+// names keep their case, as in git.
+func (r *gitRepo) config() (map[string][]string, error) {
+	cfg := map[string][]string{}
+	f, err := os.Open(filepath.Join(r.commonDir, "config"))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid git config section %q", line)
+			}
+			name, sub, hasSub := strings.Cut(line[1:end], " ")
+			section = strings.ToLower(name)
+			if hasSub {
+				section += "." + strings.Trim(strings.TrimSpace(sub), `"`)
+			}
+			continue
+		}
+		key, value, hasValue := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !hasValue {
+			value = "true"
+		}
+		if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) && len(value) >= 2 {
+			value = value[1 : len(value)-1]
+		}
+		cfg[section+"."+key] = append(cfg[section+"."+key], value)
+	}
+	return cfg, scanner.Err()
+}
+
+// remoteURL returns the URL of the remote tracked by the current branch,
+// or else of "origin", or else of the first remote in name order. It
+// returns "" if there are no remotes.
+func (r *gitRepo) remoteURL() (string, error) {
+	cfg, err := r.config()
+	if err != nil {
+		return "", err
+	}
+	last := func(key string) string {
+		if vs := cfg[key]; len(vs) > 0 {
+			return vs[len(vs)-1]
+		}
+		return ""
+	}
+
+	if ref, err := r.symbolicHead(); err == nil {
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			if remote := last("branch." + branch + ".remote"); remote != "" {
+				if u := last("remote." + remote + ".url"); u != "" {
+					return u, nil
+				}
+			}
+		}
+	}
+	if u := last("remote.origin.url"); u != "" {
+		return u, nil
+	}
+	keys := []string{}
+	for k := range cfg {
+		if strings.HasPrefix(k, "remote.") && strings.HasSuffix(k, ".url") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		return last(keys[0]), nil
+	}
+	return "", nil
+}
+
+// symbolicHead returns the ref that HEAD points to, or an error if HEAD is
+// detached.
+func (r *gitRepo) symbolicHead() (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return "", fmt.Errorf("HEAD is detached")
+	}
+	return ref, nil
+}
+
+// resolveRef returns the object name that ref ("HEAD" or a full ref name
+// such as "refs/heads/main") points to, following symbolic refs.
+func (r *gitRepo) resolveRef(ref string) (string, error) {
+	for depth := 0; depth < 10; depth++ {
+		value, err := r.readRef(ref)
+		if err != nil {
+			return "", err
+		}
+		next, ok := strings.CutPrefix(value, "ref: ")
+		if !ok {
+			if !isGitObjectName(value) {
+				return "", fmt.Errorf("invalid value %q for ref %s", value, ref)
+			}
+			return value, nil
+		}
+		ref = next
+	}
+	return "", fmt.Errorf("too many levels of symbolic refs")
+}
+
+// readRef returns the raw value of ref, from a loose ref file or from
+// packed-refs.
+func (r *gitRepo) readRef(ref string) (string, error) {
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	if err := r.loadPackedRefs(); err != nil {
+		return "", err
+	}
+	if v, ok := r.packedRefs[ref]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("ref %s: %w", ref, errGitRefNotFound)
+}
+
+// loadPackedRefs reads the packed-refs file, if any. The peeled values
+// recorded for annotated tags are skipped, as tags are peeled by reading
+// their objects.
+func (r *gitRepo) loadPackedRefs() error {
+	if r.packedRefs != nil {
+		return nil
+	}
+	r.packedRefs = map[string]string{}
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		if name, ref, ok := strings.Cut(line, " "); ok {
+			r.packedRefs[ref] = name
+		}
+	}
+	return nil
+}
+
+// tags returns the repository's tags that point to commits.
+func (r *gitRepo) tags() ([]gitTag, error) {
+	if err := r.loadPackedRefs(); err != nil {
+		return nil, err
+	}
+	refs := map[string]string{}
+	for ref, name := range r.packedRefs {
+		if strings.HasPrefix(ref, "refs/tags/") {
+			refs[ref] = name
+		}
+	}
+	tagsDir := filepath.Join(r.commonDir, "refs", "tags")
+	err := filepath.Walk(tagsDir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(r.commonDir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		refs[filepath.ToSlash(rel)] = strings.TrimSpace(string(data))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []gitTag{}
+	for _, ref := range sortedKeys(refs) {
+		target := refs[ref]
+		if !isGitObjectName(target) {
+			continue
+		}
+		typ, data, err := r.readObject(target)
+		if err != nil {
+			return nil, err
+		}
+		tag := gitTag{Name: strings.TrimPrefix(ref, "refs/tags/"), Commit: target}
+		// peel annotated tags, which may themselves point to tags
+		for depth := 0; typ == "tag" && depth < 10; depth++ {
+			tag.Annotated = true
+			tag.Commit = gitHeader(data, "object")
+			if typ, data, err = r.readObject(tag.Commit); err != nil {
+				return nil, err
+			}
+		}
+		if typ == "commit" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// describe returns a version for commit in the form of "git describe
+// --tags": the name of a tag pointing to commit, or else
+// "<tag>-<n>-g<abbreviated commit>" for the nearest tagged ancestor, where
+// n is the number of commits since that tag. Annotated tags are preferred
+// to lightweight ones. It returns "" if no ancestor is tagged.
+func (r *gitRepo) describe(commit string) (string, error) {
+	tags, err := r.tags()
+	if err != nil {
+		return "", err
+	}
+	byCommit := map[string][]gitTag{}
+	for _, t := range tags {
+		byCommit[t.Commit] = append(byCommit[t.Commit], t)
+	}
+	best := func(ts []gitTag) string {
+		sort.Slice(ts, func(i, j int) bool {
+			if ts[i].Annotated != ts[j].Annotated {
+				return ts[i].Annotated
+			}
+			return ts[i].Name > ts[j].Name
+		})
+		return ts[0].Name
+	}
+	if ts := byCommit[commit]; len(ts) > 0 {
+		return best(ts), nil
+	}
+
+	// find the nearest tagged ancestor, breadth first
+	queue := []string{commit}
+	seen := map[string]bool{commit: true}
+	tagged := ""
+	for len(queue) > 0 && tagged == "" {
+		c := queue[0]
+		queue = queue[1:]
+		if len(byCommit[c]) > 0 {
+			tagged = c
+			break
+		}
+		parents, err := r.commitParents(c)
+		if err != nil {
+			return "", err
+		}
+		for _, p := range parents {
+			if !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	if tagged == "" {
+		return "", nil
+	}
+
+	// count the commits reachable from commit but not from the tag
+	fromTag, err := r.ancestors(tagged, nil)
+	if err != nil {
+		return "", err
+	}
+	fromCommit, err := r.ancestors(commit, fromTag)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d-g%s", best(byCommit[tagged]), len(fromCommit), commit[:7]), nil
+}
+
+// ancestors returns the set of commits reachable from commit, including
+// itself, without descending into any commit in stop.
+func (r *gitRepo) ancestors(commit string, stop map[string]bool) (map[string]bool, error) {
+	found := map[string]bool{}
+	stack := []string{commit}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if found[c] || stop[c] {
+			continue
+		}
+		found[c] = true
+		parents, err := r.commitParents(c)
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, parents...)
+	}
+	return found, nil
+}
+
+// commitParents returns the parents of commit. Parents missing from a
+// shallow clone are omitted.
+func (r *gitRepo) commitParents(commit string) ([]string, error) {
+	typ, data, err := r.readObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	if typ != "commit" {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", commit, typ)
+	}
+	parents := []string{}
+	for _, line := range gitHeaders(data) {
+		if p, ok := strings.CutPrefix(line, "parent "); ok && r.hasObject(p) {
+			parents = append(parents, p)
+		}
+	}
+	return parents, nil
+}
+
+// commitTree returns the tree of commit.
+func (r *gitRepo) commitTree(commit string) (string, error) {
+	typ, data, err := r.readObject(commit)
+	if err != nil {
+		return "", err
+	}
+	if typ != "commit" {
+		return "", fmt.Errorf("object %s is a %s, not a commit", commit, typ)
+	}
+	return gitHeader(data, "tree"), nil
+}
+
+// subtree returns the tree at the slash-separated path p within tree.
+func (r *gitRepo) subtree(tree string, p string) (string, error) {
+	for _, name := range strings.Split(p, "/") {
+		if name == "" || name == "." {
+			continue
+		}
+		typ, data, err := r.readObject(tree)
+		if err != nil {
+			return "", err
+		}
+		if typ != "tree" {
+			return "", fmt.Errorf("object %s is a %s, not a tree", tree, typ)
+		}
+		entries, err := parseGitTree(data)
+		if err != nil {
+			return "", err
+		}
+		next := ""
+		for _, e := range entries {
+			if e.Name == name && e.Mode == "40000" {
+				next = e.Hash
+			}
+		}
+		if next == "" {
+			return "", fmt.Errorf("directory %s not found in tree", p)
+		}
+		tree = next
+	}
+	return tree, nil
+}
+
+// gitTreeEntry is an entry of a tree object.
+type gitTreeEntry struct {
+	Mode string
+	Name string
+	Hash string
+}
+
+// parseGitTree parses the contents of a tree object.
+func parseGitTree(data []byte) ([]gitTreeEntry, error) {
+	entries := []gitTreeEntry{}
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if sp < 0 || nul < sp || len(data) < nul+21 {
+			return nil, fmt.Errorf("invalid tree object")
+		}
+		entries = append(entries, gitTreeEntry{
+			Mode: string(data[:sp]),
+			Name: string(data[sp+1 : nul]),
+			Hash: hex.EncodeToString(data[nul+1 : nul+21]),
+		})
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
+
+// gitHeaders returns the header lines of a commit or tag object.
+func gitHeaders(data []byte) []string {
+	head, _, _ := bytes.Cut(data, []byte("\n\n"))
+	return strings.Split(string(head), "\n")
+}
+
+// gitHeader returns the value of the first header named key in a commit
+// or tag object.
+func gitHeader(data []byte, key string) string {
+	for _, line := range gitHeaders(data) {
+		if v, ok := strings.CutPrefix(line, key+" "); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// isGitObjectName reports whether s is a full hex SHA-1 object name.
+func isGitObjectName(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// hasObject reports whether the object named hash is present, loose or
+// packed.
+func (r *gitRepo) hasObject(hash string) bool {
+	if fileExists(r.looseObjectPath(hash)) {
+		return true
+	}
+	if err := r.loadPacks(); err != nil {
+		return false
+	}
+	for _, p := range r.packs {
+		if _, ok := p.find(hash); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *gitRepo) looseObjectPath(hash string) string {
+	return filepath.Join(r.commonDir, "objects", hash[:2], hash[2:])
+}
+
+// readObject returns the type ("commit", "tree", "blob" or "tag") and
+// contents of the object named hash.
+func (r *gitRepo) readObject(hash string) (string, []byte, error) {
+	if !isGitObjectName(hash) {
+		return "", nil, fmt.Errorf("invalid object name %q", hash)
+	}
+	f, err := os.Open(r.looseObjectPath(hash))
+	if err == nil {
+		defer f.Close()
+		return readLooseGitObject(f)
+	}
+	if !os.IsNotExist(err) {
+		return "", nil, err
+	}
+
+	if err := r.loadPacks(); err != nil {
+		return "", nil, err
+	}
+	for _, p := range r.packs {
+		if offset, ok := p.find(hash); ok {
+			return p.readObject(offset, r)
+		}
+	}
+	return "", nil, fmt.Errorf("object %s not found", hash)
+}
+
+// readLooseGitObject reads a zlib-compressed loose object.
+func readLooseGitObject(rd io.Reader) (string, []byte, error) {
+	zr, err := zlib.NewReader(rd)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+	nul := bytes.IndexByte(data, 0)
+	if nul < 0 {
+		return "", nil, fmt.Errorf("invalid loose object header")
+	}
+	typ, size, ok := strings.Cut(string(data[:nul]), " ")
+	n, err := strconv.Atoi(size)
+	if !ok || err != nil || n != len(data)-nul-1 {
+		return "", nil, fmt.Errorf("invalid loose object header %q", data[:nul])
+	}
+	return typ, data[nul+1:], nil
+}
+
+// loadPacks opens the index of each pack file in the repository.
+func (r *gitRepo) loadPacks() error {
+	if r.packsLoaded {
+		return nil
+	}
+	r.packsLoaded = true
+	idxs, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxs {
+		p, err := openGitPack(idx)
+		if err != nil {
+			return err
+		}
+		r.packs = append(r.packs, p)
+	}
+	return nil
+}
+
+// gitModeSubmodule is the index mode of a submodule (a "gitlink").
+const gitModeSubmodule = 0160000
+
+// trackedFiles returns the paths, relative to the working tree and
+// slash-separated, listed in the repository's index, with their modes.
+func (r *gitRepo) trackedFiles() (map[string]uint32, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "index"))
+	if os.IsNotExist(err) {
+		return map[string]uint32{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseGitIndex(data)
+}
+
+// parseGitIndex returns the paths and modes listed in an index file of
+// version 2, 3 or 4.
+func parseGitIndex(data []byte) (map[string]uint32, error) {
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, fmt.Errorf("invalid git index")
+	}
+	version := be32(data[4:])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported git index version %d", version)
+	}
+	count := int(be32(data[8:]))
+
+	paths := map[string]uint32{}
+	pos := 12
+	prev := ""
+	for i := 0; i < count; i++ {
+		// ctime, mtime, dev, ino, mode, uid, gid, size, hash and flags
+		const fixed = 62
+		if pos+fixed > len(data) {
+			return nil, fmt.Errorf("truncated git index")
+		}
+		start := pos
+		mode := be32(data[pos+24:])
+		flags := uint16(data[pos+60])<<8 | uint16(data[pos+61])
+		pos += fixed
+		if flags&0x4000 != 0 {
+			if version < 3 {
+				return nil, fmt.Errorf("extended flags in git index version %d", version)
+			}
+			pos += 2
+		}
+
+		var name string
+		if version == 4 {
+			// the name is prefix-compressed against the previous entry
+			strip, n := gitIndexVarint(data[pos:])
+			if n == 0 || int(strip) > len(prev) {
+				return nil, fmt.Errorf("invalid git index entry")
+			}
+			pos += n
+			nul := bytes.IndexByte(data[pos:], 0)
+			if nul < 0 {
+				return nil, fmt.Errorf("truncated git index")
+			}
+			name = prev[:len(prev)-int(strip)] + string(data[pos:pos+nul])
+			pos += nul + 1
+		} else {
+			nul := bytes.IndexByte(data[pos:], 0)
+			if nul < 0 {
+				return nil, fmt.Errorf("truncated git index")
+			}
+			name = string(data[pos : pos+nul])
+			// entries are padded with 1 to 8 NULs to a multiple of 8 bytes
+			pos += nul + 1
+			for (pos-start)%8 != 0 {
+				pos++
+			}
+		}
+		paths[name] = mode
+		prev = name
+	}
+	return paths, nil
+}
+
+// gitIndexVarint decodes the offset-style variable-length integer used in
+// index version 4 and in pack OFS_DELTA entries, returning the value and
+// the number of bytes read, or 0 bytes if data is truncated.
+func gitIndexVarint(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	c := data[0]
+	v := uint64(c & 0x7f)
+	n := 1
+	for c&0x80 != 0 {
+		if n >= len(data) {
+			return 0, 0
+		}
+		c = data[n]
+		n++
+		v = ((v + 1) << 7) | uint64(c&0x7f)
+	}
+	return v, n
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// gitPack is a pack file with its version 2 index.
+type gitPack struct {
+	packPath string
+	hashes   [][]byte
+	offsets  []int64
+}
+
+// git pack object types
+const (
+	gitObjCommit   = 1
+	gitObjTree     = 2
+	gitObjBlob     = 3
+	gitObjTag      = 4
+	gitObjOfsDelta = 6
+	gitObjRefDelta = 7
+)
+
+var gitObjTypeNames = map[int]string{
+	gitObjCommit: "commit",
+	gitObjTree:   "tree",
+	gitObjBlob:   "blob",
+	gitObjTag:    "tag",
+}
+
+// openGitPack reads the index at idxPath, for the pack file of the same
+// name.
+func openGitPack(idxPath string) (*gitPack, error) {
+	data, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || be32(data[4:]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index %s", idxPath)
+	}
+	fanout := data[8 : 8+256*4]
+	n := int(be32(fanout[255*4:]))
+
+	hashStart := 8 + 256*4
+	crcStart := hashStart + n*20
+	offStart := crcStart + n*4
+	largeStart := offStart + n*4
+	if len(data) < largeStart {
+		return nil, fmt.Errorf("truncated pack index %s", idxPath)
+	}
+
+	p := &gitPack{
+		packPath: strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		hashes:   make([][]byte, n),
+		offsets:  make([]int64, n),
+	}
+	for i := 0; i < n; i++ {
+		p.hashes[i] = data[hashStart+i*20 : hashStart+(i+1)*20]
+		off := be32(data[offStart+i*4:])
+		if off&0x80000000 == 0 {
+			p.offsets[i] = int64(off)
+			continue
+		}
+		// large offsets are stored in a separate table of 64-bit values
+		j := largeStart + int(off&0x7fffffff)*8
+		if j+8 > len(data) {
+			return nil, fmt.Errorf("truncated pack index %s", idxPath)
+		}
+		p.offsets[i] = int64(be32(data[j:]))<<32 | int64(be32(data[j+4:]))
+	}
+	return p, nil
+}
+
+// find returns the offset in the pack of the object named hash.
+func (p *gitPack) find(hash string) (int64, bool) {
+	want, err := hex.DecodeString(hash)
+	if err != nil {
+		return 0, false
+	}
+	i := sort.Search(len(p.hashes), func(i int) bool { return bytes.Compare(p.hashes[i], want) >= 0 })
+	if i < len(p.hashes) && bytes.Equal(p.hashes[i], want) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// readObject returns the type and contents of the object at offset,
+// resolving deltas. Bases of REF_DELTA objects are looked up through r, as
+// they may be stored elsewhere in a thin pack.
+func (p *gitPack) readObject(offset int64, r *gitRepo) (string, []byte, error) {
+	f, err := os.Open(p.packPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	return p.readObjectAt(f, offset, r, 0)
+}
+
+func (p *gitPack) readObjectAt(f *os.File, offset int64, r *gitRepo, depth int) (string, []byte, error) {
+	if depth > 64 {
+		return "", nil, fmt.Errorf("delta chain too long in %s", p.packPath)
+	}
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	// the header holds the type and the inflated size, little-endian in
+	// 7-bit groups after the first 4 bits
+	c, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	typ := int(c>>4) & 7
+	for c&0x80 != 0 {
+		if c, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var baseType string
+	var base []byte
+	switch typ {
+	case gitObjOfsDelta:
+		buf := []byte{}
+		for {
+			c, err := br.ReadByte()
+			if err != nil {
+				return "", nil, err
+			}
+			buf = append(buf, c)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		rel, _ := gitIndexVarint(buf)
+		if rel == 0 || int64(rel) > offset {
+			return "", nil, fmt.Errorf("invalid delta offset in %s", p.packPath)
+		}
+		baseType, base, err = p.readObjectAt(f, offset-int64(rel), r, depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+	case gitObjRefDelta:
+		hash := make([]byte, 20)
+		if _, err := io.ReadFull(br, hash); err != nil {
+			return "", nil, err
+		}
+		baseType, base, err = r.readObject(hex.EncodeToString(hash))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if base != nil {
+		data, err = applyGitDelta(base, data)
+		return baseType, data, err
+	}
+	name, ok := gitObjTypeNames[typ]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown object type %d in %s", typ, p.packPath)
+	}
+	return name, data, nil
+}
+
+// applyGitDelta applies a pack delta to base.
+func applyGitDelta(base []byte, delta []byte) ([]byte, error) {
+	readSize := func() (int, bool) {
+		size, shift := 0, 0
+		for {
+			if len(delta) == 0 {
+				return 0, false
+			}
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+	}
+	srcSize, ok1 := readSize()
+	dstSize, ok2 := readSize()
+	if !ok1 || !ok2 || srcSize != len(base) {
+		return nil, fmt.Errorf("invalid delta header")
+	}
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			// copy from base, with the offset and size bytes present
+			// according to the low 7 bits of op
+			var offset, size int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated delta")
+				}
+				if i < 4 {
+					offset |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("delta copy out of range")
+			}
+			out = append(out, base[offset:offset+size]...)
+		case op != 0:
+			// insert the next op bytes
+			if int(op) > len(delta) {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, fmt.Errorf("invalid delta opcode")
+		}
+	}
+	if len(out) != dstSize {
+		return nil, fmt.Errorf("delta produced %d bytes, expected %d", len(out), dstSize)
+	}
+	return out, nil
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// gitIgnore matches paths against the rules of a working tree's .gitignore
+// files and its .git/info/exclude file.
+type gitIgnore struct {
+	rules []gitIgnoreRule
+}
+
+type gitIgnoreRule struct {
+	// base is the slash-separated directory containing the rule's file,
+	// relative to the working tree, or "" for the top level.
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// loadGitIgnore reads .git/info/exclude and the .gitignore file, if any, in
+// each directory enclosing one of paths, which are slash-separated and
+// relative to the working tree. The user's global excludes file is not
+// read.
+func loadGitIgnore(r *gitRepo, paths []string) (*gitIgnore, error) {
+	g := &gitIgnore{}
+	if err := g.addFile(filepath.Join(r.commonDir, "info", "exclude"), ""); err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]bool{"": true}
+	for _, p := range paths {
+		for d := path.Dir(p); d != "." && !dirs[d]; d = path.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	// rules in deeper directories take precedence, so add them last
+	bases := sortedKeys(dirs)
+	sort.SliceStable(bases, func(i, j int) bool {
+		return strings.Count(bases[i], "/") < strings.Count(bases[j], "/") ||
+			bases[i] == "" && bases[j] != ""
+	})
+	for _, base := range bases {
+		p := filepath.Join(r.workTree, filepath.FromSlash(base), ".gitignore")
+		if err := g.addFile(p, base); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// addFile adds the rules in the file at p, if it exists, for paths within
+// base.
+func (g *gitIgnore) addFile(p string, base string) error {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseGitIgnoreLine(scanner.Text(), base); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+// ignored reports whether the file at the slash-separated path p, relative
+// to the working tree, is ignored, either itself or because one of its
+// parent directories is. As in git, a file cannot be re-included if a
+// parent directory is ignored.
+func (g *gitIgnore) ignored(p string) bool {
+	parts := strings.Split(p, "/")
+	for i := 1; i <= len(parts); i++ {
+		if g.match(strings.Join(parts[:i], "/"), i < len(parts)) {
+			return true
+		}
+	}
+	return false
+}
+
+// match applies the rules to the single path p; the last matching rule
+// decides.
+func (g *gitIgnore) match(p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range g.rules {
+		rel := p
+		if rule.base != "" {
+			var ok bool
+			if rel, ok = strings.CutPrefix(p, rule.base+"/"); !ok {
+				continue
+			}
+		}
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseGitIgnoreLine parses one line of a .gitignore file, returning false
+// for blank lines, comments and invalid patterns.
+func parseGitIgnoreLine(line string, base string) (gitIgnoreRule, bool) {
+	rule := gitIgnoreRule{base: base}
+
+	// trailing spaces are ignored unless escaped
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return rule, false
+	}
+	if line[0] == '!' {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// a pattern containing a slash is relative to base; otherwise it
+	// matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/") && (i == 0 || line[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**") && i+2 == len(line) && i > 0 && line[i-1] == '/':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			sb.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return rule, false
+	}
+	rule.re = re
+	return rule, true
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"testing"
+)
+
+// ===== gitignore tests =====
+func TestGitIgnoreCanMatchPatterns(t *testing.T) {
+	g := &gitIgnore{}
+	for _, line := range []string{
+		"# comment",
+		"",
+		"*.o",
+		"!keep.o",
+		"/top.txt",
+		"logs/",
+		"doc/*.html",
+		"**/cache",
+		"out/**",
+		"a/**/z",
+		"file[0-9].txt",
+		`\#hash`,
+		"trailing   ",
+	} {
+		if rule, ok := parseGitIgnoreLine(line, ""); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	if rule, ok := parseGitIgnoreLine("*.tmp", "sub"); ok {
+		g.rules = append(g.rules, rule)
+	}
+
+	for p, want := range map[string]bool{
+		"main.o":            true,
+		"dir/main.o":        true,
+		"keep.o":            false,
+		"dir/keep.o":        false,
+		"top.txt":           true,
+		"dir/top.txt":       false,
+		"logs/today.txt":    true,
+		"dir/logs/x":        true,
+		"logs":              false,
+		"doc/index.html":    true,
+		"doc/api/x.html":    false,
+		"x/y/cache/data":    true,
+		"out/a/b":           true,
+		"a/z":               true,
+		"a/b/c/z":           true,
+		"file1.txt":         true,
+		"fileX.txt":         false,
+		"#hash":             true,
+		"trailing":          true,
+		"sub/x.tmp":         true,
+		"x.tmp":             false,
+		"other/sub/x.tmp":   false,
+		"README.md":         false,
+		"src/logs_file.txt": false,
+	} {
+		if got := g.ignored(p); got != want {
+			t.Errorf("%s: expected %v, got %v", p, want, got)
+		}
+	}
+}
+
+func TestGitIgnoreCannotReincludeFileInIgnoredDirectory(t *testing.T) {
+	g := &gitIgnore{}
+	for _, line := range []string{"build/", "!build/keep.txt"} {
+		rule, _ := parseGitIgnoreLine(line, "")
+		g.rules = append(g.rules, rule)
+	}
+	if !g.ignored("build/keep.txt") {
+		t.Errorf("expected build/keep.txt to stay ignored")
+	}
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// makeElementID joins the given parts with dashes into an ElementID,
+// replacing any character that is not permitted in an SPDX identifier
+// (letters, numbers, "." and "-") with a dash.
+func makeElementID(parts ...string) common.ElementID {
+	cleaned := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		cleaned = append(cleaned, utils.SanitizeElementID(part))
+	}
+	return common.ElementID(strings.Join(cleaned, "-"))
+}
+
+// FileIDScheme selects how the builder generates the identifiers of Files.
+type FileIDScheme int
+
+const (
+	// FileIDsSequential numbers the files "File0", "File1" and so on, in
+	// path order. Adding or removing a file renumbers the files after it.
+	FileIDsSequential FileIDScheme = iota
+
+	// FileIDsByPath derives each file's identifier from its path, such as
+	// "File-src-main.go" for "./src/main.go", so that it is unchanged as
+	// other files are added or removed.
+	FileIDsByPath
+
+	// FileIDsByContent derives each file's identifier from its SHA1
+	// checksum, such as "File-2c26b46b68ffc68ff99b453c1d30413413422d70",
+	// so that it is unchanged as the file is moved. Files with the same
+	// contents are distinguished by a numeric suffix, in path order.
+	FileIDsByContent
+)
+
+// assignFileIDs replaces the identifiers of the files of pkgs according
+// to scheme. Each new identifier is unique within the document; package
+// identifiers are reserved first.
+func assignFileIDs(pkgs []*spdx.Package, scheme FileIDScheme) {
+	if scheme == FileIDsSequential {
+		return
+	}
+	gen := utils.NewElementIDGenerator()
+	files := []*spdx.File{}
+	for _, pkg := range pkgs {
+		gen.Reserve(pkg.PackageSPDXIdentifier)
+		files = append(files, pkg.Files...)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].FileName < files[j].FileName
+	})
+	for _, f := range files {
+		switch scheme {
+		case FileIDsByPath:
+			f.FileSPDXIdentifier = gen.Generate("File", strings.TrimPrefix(f.FileName, "./"))
+		case FileIDsByContent:
+			sha1 := ""
+			for _, c := range f.Checksums {
+				if c.Algorithm == common.SHA1 {
+					sha1 = c.Value
+				}
+			}
+			f.FileSPDXIdentifier = gen.Generate("File", sha1)
+		}
+	}
+}
+
+// makeDocumentNamespace returns the DocumentNamespace for a document with
+// the given name, ending with suffix or, if config.UUIDNamespace is set,
+// with a random UUID.
+func makeDocumentNamespace(config *Config, name string, suffix string) (string, error) {
+	if config.UUIDNamespace {
+		uuid, err := utils.NewUUID()
+		if err != nil {
+			return "", err
+		}
+		suffix = uuid
+	}
+	return utils.MakeDocumentNamespace(config.NamespacePrefix, name, suffix), nil
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Identifier tests =====
+func TestBuildSanitizesPackageIDAndNamespace(t *testing.T) {
+	config := &Config{
+		NamespacePrefix: "https://example.com/spdx/",
+		TestValues:      map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+	doc, err := Build("@scope/my pkg", "../testdata/project1", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if id := doc.Packages[0].PackageSPDXIdentifier; id != "Package--scope-my-pkg" {
+		t.Errorf("expected %v, got %v", "Package--scope-my-pkg", id)
+	}
+	if ref := doc.Relationships[0].RefB.ElementRefID; ref != "Package--scope-my-pkg" {
+		t.Errorf("expected %v, got %v", "Package--scope-my-pkg", ref)
+	}
+	want := "https://example.com/spdx/@scope/my%20pkg-" + doc.Packages[0].PackageVerificationCode.Value
+	if doc.DocumentNamespace != want {
+		t.Errorf("expected %v, got %v", want, doc.DocumentNamespace)
+	}
+}
+
+func TestBuildCanUseUUIDNamespace(t *testing.T) {
+	config := &Config{NamespacePrefix: "https://example.com/spdx/", UUIDNamespace: true}
+	doc, err := Build("project1", "../testdata/project1", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	re := regexp.MustCompile(`^https://example.com/spdx/project1-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !re.MatchString(doc.DocumentNamespace) {
+		t.Errorf("expected UUID namespace, got %v", doc.DocumentNamespace)
+	}
+}
+
+func TestBuildCanDeriveFileIDsFromPaths(t *testing.T) {
+	doc, err := Build("project1", "../testdata/project1", &Config{FileIDs: FileIDsByPath})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := map[string]common.ElementID{
+		"./emptyfile.testdata.txt":     "File-emptyfile.testdata.txt",
+		"./file1.testdata.txt":         "File-file1.testdata.txt",
+		"./file3.testdata.txt":         "File-file3.testdata.txt",
+		"./folder1/file4.testdata.txt": "File-folder1-file4.testdata.txt",
+		"./lastfile.testdata.txt":      "File-lastfile.testdata.txt",
+	}
+	files := doc.Packages[0].Files
+	if len(files) != len(want) {
+		t.Fatalf("expected %d, got %d", len(want), len(files))
+	}
+	for _, f := range files {
+		if f.FileSPDXIdentifier != want[f.FileName] {
+			t.Errorf("expected %v, got %v", want[f.FileName], f.FileSPDXIdentifier)
+		}
+	}
+}
+
+func TestBuildCanDeriveFileIDsFromContents(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.txt", "hello\n")
+	writeTestFile(t, dir, "b/c.txt", "hello\n")
+	writeTestFile(t, dir, "d.txt", "other\n")
+
+	doc, err := Build("dup", dir, &Config{FileIDs: FileIDsByContent})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	files := doc.Packages[0].Files
+	// identical files are numbered in path order
+	want := []common.ElementID{
+		"File-f572d396fae9206628714fb2ce00f72e94f2258f",
+		"File-f572d396fae9206628714fb2ce00f72e94f2258f-2",
+		"File-" + common.ElementID(files[2].Checksums[0].Value),
+	}
+	for i, f := range files {
+		if f.FileSPDXIdentifier != want[i] {
+			t.Errorf("%s: expected %v, got %v", f.FileName, want[i], f.FileSPDXIdentifier)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// imageSource gives access to the files of an OCI image layout or of a
+// "docker save" tarball.
+type imageSource interface {
+	// open returns the contents of the file at the slash-separated path p,
+	// relative to the top of the layout or tarball.
+	open(p string) (io.ReadCloser, error)
+	has(p string) bool
+	close() error
+}
+
+// dirImageSource is an image layout directory.
+type dirImageSource struct {
+	root string
+}
+
+func (s *dirImageSource) open(p string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.root, filepath.FromSlash(p)))
+}
+
+func (s *dirImageSource) has(p string) bool {
+	return fileExists(filepath.Join(s.root, filepath.FromSlash(p)))
+}
+
+func (s *dirImageSource) close() error { return nil }
+
+// tarImageSource is an uncompressed tarball, whose entries are read in
+// place by their offsets.
+type tarImageSource struct {
+	f       *os.File
+	entries map[string]tarImageEntry
+}
+
+type tarImageEntry struct {
+	offset int64
+	size   int64
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// openTarImageSource indexes the regular files in the tarball at p.
+func openTarImageSource(p string) (*tarImageSource, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	s := &tarImageSource{f: f, entries: map[string]tarImageEntry{}}
+	cr := &countingReader{r: f}
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("reading %s: %v", p, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// the tar reader does not read ahead, so the entry's contents
+		// begin where its header ended
+		s.entries[path.Clean(strings.TrimPrefix(hdr.Name, "./"))] = tarImageEntry{offset: cr.n, size: hdr.Size}
+	}
+	return s, nil
+}
+
+func (s *tarImageSource) open(p string) (io.ReadCloser, error) {
+	e, ok := s.entries[p]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in image tarball", p)
+	}
+	return io.NopCloser(io.NewSectionReader(s.f, e.offset, e.size)), nil
+}
+
+func (s *tarImageSource) has(p string) bool {
+	_, ok := s.entries[p]
+	return ok
+}
+
+func (s *tarImageSource) close() error { return s.f.Close() }
+
+// openImageSource opens the image layout directory or tarball at p.
+func openImageSource(p string) (imageSource, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return &dirImageSource{root: p}, nil
+	}
+	return openTarImageSource(p)
+}
+
+// imageInfo describes the image selected from an image source.
+type imageInfo struct {
+	// Name is the image's repository, such as "docker.io/library/alpine",
+	// and Tag its tag, if known.
+	Name string
+	Tag  string
+	// Digest is the digest of the image manifest or, for images saved by
+	// older versions of docker without one, of the image configuration.
+	Digest       string
+	OS           string
+	Architecture string
+	Layers       []imageLayer
+}
+
+// imageLayer is a layer of an image, from the bottom up.
+type imageLayer struct {
+	// Path is the layer's path within the image source.
+	Path string
+	// Digest is the digest of the layer blob, if given by the manifest;
+	// otherwise it is computed when the layer is read.
+	Digest    string
+	MediaType string
+	DiffID    string
+	CreatedBy string
+}
+
+// ociDescriptor is a content descriptor in an OCI index or manifest.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+// ociIndex is an OCI image index or a manifest; only the fields needed
+// to tell them apart and to find the image's layers are read.
+type ociIndex struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Config    *ociDescriptor  `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+type ociImageConfig struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	RootFS       struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+}
+
+type dockerManifestEntry struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// readImageInfo reads the image from an OCI image layout (index.json) or,
+// failing that, from a docker save manifest (manifest.json). If the layout
+// holds several images, the first in index order is used.
+func readImageInfo(src imageSource) (*imageInfo, error) {
+	if src.has("index.json") {
+		return readOCIImageInfo(src)
+	}
+	if src.has("manifest.json") {
+		return readDockerImageInfo(src)
+	}
+	return nil, fmt.Errorf("neither index.json nor manifest.json found; not an OCI image layout or docker save tarball")
+}
+
+func readImageJSON(src imageSource, p string, v interface{}) error {
+	rc, err := src.open(p)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("parsing %s: %v", p, err)
+	}
+	return nil
+}
+
+// ociBlobPath returns the path of the blob with the given digest.
+func ociBlobPath(digest string) (string, error) {
+	alg, hex, ok := strings.Cut(digest, ":")
+	if !ok || alg == "" || hex == "" || strings.ContainsAny(digest, "/\\") {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return "blobs/" + alg + "/" + hex, nil
+}
+
+func readOCIImageInfo(src imageSource) (*imageInfo, error) {
+	idx := &ociIndex{}
+	if err := readImageJSON(src, "index.json", idx); err != nil {
+		return nil, err
+	}
+
+	info := &imageInfo{}
+	// descend through nested indexes to the first image manifest
+	var manifest *ociIndex
+	for depth := 0; manifest == nil; depth++ {
+		if len(idx.Manifests) == 0 || depth > 8 {
+			return nil, fmt.Errorf("no image manifest found in index.json")
+		}
+		desc := idx.Manifests[0]
+		if name := desc.Annotations["io.containerd.image.name"]; name != "" && info.Name == "" {
+			info.Name, info.Tag = splitImageReference(name)
+		} else if ref := desc.Annotations["org.opencontainers.image.ref.name"]; ref != "" && info.Tag == "" {
+			info.Tag = ref
+		}
+		p, err := ociBlobPath(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		next := &ociIndex{}
+		if err := readImageJSON(src, p, next); err != nil {
+			return nil, err
+		}
+		if next.Config != nil {
+			manifest = next
+			info.Digest = desc.Digest
+		}
+		idx = next
+	}
+
+	cfg := &ociImageConfig{}
+	p, err := ociBlobPath(manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := readImageJSON(src, p, cfg); err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Layers {
+		p, err := ociBlobPath(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		info.Layers = append(info.Layers, imageLayer{Path: p, Digest: desc.Digest, MediaType: desc.MediaType})
+	}
+	applyImageConfig(info, cfg)
+	return info, nil
+}
+
+func readDockerImageInfo(src imageSource) (*imageInfo, error) {
+	entries := []dockerManifestEntry{}
+	if err := readImageJSON(src, "manifest.json", &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no images found in manifest.json")
+	}
+	entry := entries[0]
+
+	info := &imageInfo{}
+	if len(entry.RepoTags) > 0 {
+		info.Name, info.Tag = splitImageReference(entry.RepoTags[0])
+	}
+	// the configuration's file name is the hex digest of its contents,
+	// which docker uses as the image ID
+	base := strings.TrimSuffix(path.Base(entry.Config), ".json")
+	if strings.HasPrefix(entry.Config, "blobs/") {
+		info.Digest = path.Base(path.Dir(entry.Config)) + ":" + base
+	} else {
+		info.Digest = "sha256:" + base
+	}
+
+	cfg := &ociImageConfig{}
+	if err := readImageJSON(src, path.Clean(entry.Config), cfg); err != nil {
+		return nil, err
+	}
+	for _, p := range entry.Layers {
+		info.Layers = append(info.Layers, imageLayer{Path: path.Clean(p)})
+	}
+	applyImageConfig(info, cfg)
+	return info, nil
+}
+
+// applyImageConfig records the platform, and the diff IDs and history of
+// the layers, from the image configuration.
+func applyImageConfig(info *imageInfo, cfg *ociImageConfig) {
+	info.OS, info.Architecture = cfg.OS, cfg.Architecture
+	for i := range info.Layers {
+		if i < len(cfg.RootFS.DiffIDs) {
+			info.Layers[i].DiffID = cfg.RootFS.DiffIDs[i]
+		}
+	}
+	// history entries for empty layers have no corresponding layer
+	i := 0
+	for _, h := range cfg.History {
+		if h.EmptyLayer {
+			continue
+		}
+		if i < len(info.Layers) {
+			info.Layers[i].CreatedBy = h.CreatedBy
+		}
+		i++
+	}
+}
+
+// splitImageReference splits a reference such as
+// "docker.io/library/alpine:3.18" into its repository and tag. A digest
+// suffix ("@sha256:...") is dropped.
+func splitImageReference(ref string) (string, string) {
+	ref, _, _ = strings.Cut(ref, "@")
+	slash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > slash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, ""
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/licenselist"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// osPackage is an entry of an operating system's package database.
+type osPackage struct {
+	Name       string
+	Version    string
+	Arch       string
+	Source     string
+	Maintainer string
+	Homepage   string
+	License    string
+	// Depends lists the package's dependencies; each is a list of
+	// alternatives, any of which satisfies it.
+	Depends  [][]string
+	Provides []string
+}
+
+// parseOSRelease parses an os-release file into its fields.
+func parseOSRelease(data []byte) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.HasPrefix(k, "#") {
+			continue
+		}
+		fields[k] = strings.Trim(v, `"'`)
+	}
+	return fields
+}
+
+// parseDebControlStanzas splits data in the Debian control file format,
+// as used by /var/lib/dpkg/status, into stanzas of fields. Continuation
+// lines are joined to the preceding field.
+func parseDebControlStanzas(data []byte) []map[string]string {
+	stanzas := []map[string]string{}
+	current := map[string]string{}
+	last := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(current) > 0 {
+				stanzas = append(stanzas, current)
+				current = map[string]string{}
+			}
+			last = ""
+		case line[0] == ' ' || line[0] == '\t':
+			if last != "" {
+				current[last] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			k, v, ok := strings.Cut(line, ":")
+			if ok {
+				last = k
+				current[k] = strings.TrimSpace(v)
+			}
+		}
+	}
+	if len(current) > 0 {
+		stanzas = append(stanzas, current)
+	}
+	return stanzas
+}
+
+// parseDpkgStatus returns the installed packages in a dpkg status file.
+func parseDpkgStatus(data []byte) []*osPackage {
+	pkgs := []*osPackage{}
+	for _, st := range parseDebControlStanzas(data) {
+		// status files in /var/lib/dpkg/status.d have no Status field
+		if status, ok := st["Status"]; ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		if st["Package"] == "" {
+			continue
+		}
+		pkg := &osPackage{
+			Name:       st["Package"],
+			Version:    st["Version"],
+			Arch:       st["Architecture"],
+			Maintainer: st["Maintainer"],
+			Homepage:   st["Homepage"],
+		}
+		// the source may be followed by its version, as in
+		// "openssl (3.0.2-1)"
+		if fields := strings.Fields(st["Source"]); len(fields) > 0 {
+			pkg.Source = fields[0]
+		}
+		for _, field := range []string{"Pre-Depends", "Depends"} {
+			pkg.Depends = append(pkg.Depends, parseDebDependencies(st[field])...)
+		}
+		for _, alts := range parseDebDependencies(st["Provides"]) {
+			pkg.Provides = append(pkg.Provides, alts...)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs
+}
+
+// parseDebDependencies parses a dependency field such as
+// "libc6 (>= 2.34), libssl3 | libssl1.1" into package names, dropping
+// version constraints and architecture qualifiers.
+func parseDebDependencies(field string) [][]string {
+	deps := [][]string{}
+	for _, group := range strings.Split(field, ",") {
+		alts := []string{}
+		for _, alt := range strings.Split(group, "|") {
+			fields := strings.Fields(alt)
+			if len(fields) == 0 {
+				continue
+			}
+			name, _, _ := strings.Cut(fields[0], ":")
+			name, _, _ = strings.Cut(name, "(")
+			alts = append(alts, name)
+		}
+		if len(alts) > 0 {
+			deps = append(deps, alts)
+		}
+	}
+	return deps
+}
+
+// parseApkInstalled returns the packages in an apk installed database
+// (/lib/apk/db/installed).
+func parseApkInstalled(data []byte) []*osPackage {
+	pkgs := []*osPackage{}
+	var pkg *osPackage
+	for _, line := range strings.Split(string(data)+"\n\n", "\n") {
+		if strings.TrimSpace(line) == "" {
+			if pkg != nil && pkg.Name != "" {
+				pkgs = append(pkgs, pkg)
+			}
+			pkg = nil
+			continue
+		}
+		if pkg == nil {
+			pkg = &osPackage{}
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "P":
+			pkg.Name = v
+		case "V":
+			pkg.Version = v
+		case "A":
+			pkg.Arch = v
+		case "o":
+			pkg.Source = v
+		case "m":
+			pkg.Maintainer = v
+		case "U":
+			pkg.Homepage = v
+		case "L":
+			pkg.License = v
+		case "D":
+			for _, dep := range strings.Fields(v) {
+				if strings.HasPrefix(dep, "!") {
+					continue
+				}
+				pkg.Depends = append(pkg.Depends, []string{apkDependencyName(dep)})
+			}
+		case "p":
+			for _, p := range strings.Fields(v) {
+				pkg.Provides = append(pkg.Provides, apkDependencyName(p))
+			}
+		}
+	}
+	return pkgs
+}
+
+// apkDependencyName strips the version constraint from an apk dependency
+// or provided name, such as "so:libc.musl-x86_64.so.1=1".
+func apkDependencyName(dep string) string {
+	if i := strings.IndexAny(dep, "<>=~"); i >= 0 {
+		return dep[:i]
+	}
+	return dep
+}
+
+// maintainerRegexp matches "Name <email>".
+var maintainerRegexp = regexp.MustCompile(`^\s*(.*?)\s*<([^>]*)>\s*$`)
+
+// makeMaintainerSupplier returns a Supplier for a package maintainer given
+// as "Name <email>", or nil if there is none.
+func makeMaintainerSupplier(maintainer string) *common.Supplier {
+	if maintainer == "" {
+		return nil
+	}
+	if m := maintainerRegexp.FindStringSubmatch(maintainer); m != nil {
+		return &common.Supplier{Supplier: m[1] + " (" + m[2] + ")", SupplierType: "Person"}
+	}
+	return &common.Supplier{Supplier: strings.TrimSpace(maintainer), SupplierType: "Person"}
+}
+
+// isListedLicenseExpression reports whether s is a license expression
+// whose licenses are all SPDX-listed licenses or LicenseRefs, since free
+// text such as "OpenSSL and others" may also parse as an expression.
+func isListedLicenseExpression(s string) bool {
+	e, err := licenseexpr.Parse(s)
+	if err != nil {
+		return false
+	}
+	listed := true
+	e.Walk(func(l *licenseexpr.Expression) {
+		if _, ok := licenselist.LicenseID(l.License); !ok && !strings.Contains(l.License, "LicenseRef-") {
+			listed = false
+		}
+	})
+	return listed
+}
+
+// buildOSPackages creates a Package for each OS package, with a pkg:deb or
+// pkg:apk package URL, and DEPENDS_ON relationships between them.
+// purlType is "deb" or "apk", and osRelease holds the image's os-release
+// fields, used for the package URL namespace and distro qualifier.
+func buildOSPackages(purlType string, osPkgs []*osPackage, osRelease map[string]string) *AnalyzerResult {
+	distro := osRelease["ID"]
+	if distro == "" {
+		distro = map[string]string{"deb": "debian", "apk": "alpine"}[purlType]
+	}
+	distroQualifier := distro
+	if v := osRelease["VERSION_ID"]; v != "" {
+		distroQualifier += "-" + v
+	}
+
+	b := newAnalyzerResultBuilder()
+	byName := map[string][]common.ElementID{}
+	ids := make([]common.ElementID, len(osPkgs))
+	for i, op := range osPkgs {
+		pkg := newManagedPackage(purlType, distro, op.Name, op.Version)
+		pkg.PackageName = op.Name
+		pkg.PackageExternalReferences[0].Locator = appendPurlQualifiers(
+			pkg.PackageExternalReferences[0].Locator,
+			map[string]string{"arch": op.Arch, "distro": distroQualifier, "upstream": op.Source},
+		)
+		pkg.PackageSupplier = makeMaintainerSupplier(op.Maintainer)
+		pkg.PackageHomePage = op.Homepage
+		if op.License != "" {
+			// apk records licenses as free text, which is usually, but not
+			// always, a valid license expression
+			if isListedLicenseExpression(op.License) {
+				pkg.PackageLicenseDeclared = op.License
+			} else {
+				pkg.PackageComment = "license: " + op.License
+			}
+		}
+		ids[i] = b.addPackage(pkg)
+		byName[op.Name] = append(byName[op.Name], ids[i])
+		for _, p := range op.Provides {
+			byName[p] = append(byName[p], ids[i])
+		}
+	}
+
+	for i, op := range osPkgs {
+		for _, alts := range op.Depends {
+			for _, alt := range alts {
+				if to := byName[alt]; len(to) > 0 {
+					b.addRelationship(makeDependencyRelationship(ids[i], to[0], common.TypeRelationshipDependsOn))
+					break
+				}
+			}
+		}
+	}
+	return b.result()
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ===== OS package database tests =====
+func TestParseDpkgStatusKeepsInstalledPackages(t *testing.T) {
+	pkgs := parseDpkgStatus([]byte("" +
+		"Package: openssl\nStatus: install ok installed\nVersion: 3.0.11-1\n" +
+		"Source: openssl (3.0.11-1)\nDepends: libc6 (>= 2.34), libssl3 | libssl1.1:amd64\n" +
+		"Description: toolkit\n continued description\n\n" +
+		"Package: gone\nStatus: purge ok not-installed\n"))
+	if len(pkgs) != 1 {
+		t.Fatalf("expected %d, got %d", 1, len(pkgs))
+	}
+	if pkgs[0].Name != "openssl" || pkgs[0].Source != "openssl" {
+		t.Errorf("unexpected package %+v", pkgs[0])
+	}
+	want := [][]string{{"libc6"}, {"libssl3", "libssl1.1"}}
+	if !reflect.DeepEqual(pkgs[0].Depends, want) {
+		t.Errorf("expected %v, got %v", want, pkgs[0].Depends)
+	}
+}
+
+func TestParseApkInstalledReadsPackages(t *testing.T) {
+	pkgs := parseApkInstalled([]byte("" +
+		"C:Q1abc=\nP:zlib\nV:1.3-r2\nA:aarch64\nL:Zlib\nD:so:libc.musl-aarch64.so.1 !conflict\n" +
+		"p:so:libz.so.1=1.3\n\nP:ssl_client\nV:3.1.4-r1\nL:OpenSSL and others\n\n" +
+		"P:musl\nV:1.2.4-r2\nL:MIT AND (BSD-2-Clause OR GPL-2.0-or-later\n"))
+	if len(pkgs) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(pkgs))
+	}
+	if !reflect.DeepEqual(pkgs[0].Depends, [][]string{{"so:libc.musl-aarch64.so.1"}}) {
+		t.Errorf("unexpected dependencies %v", pkgs[0].Depends)
+	}
+	if !reflect.DeepEqual(pkgs[0].Provides, []string{"so:libz.so.1"}) {
+		t.Errorf("unexpected provides %v", pkgs[0].Provides)
+	}
+
+	result := buildOSPackages("apk", pkgs, map[string]string{})
+	if result.Packages[0].PackageLicenseDeclared != "Zlib" {
+		t.Errorf("expected %v, got %v", "Zlib", result.Packages[0].PackageLicenseDeclared)
+	}
+	if p := result.Packages[1]; p.PackageLicenseDeclared != "NOASSERTION" || p.PackageComment != "license: OpenSSL and others" {
+		t.Errorf("expected free text license in comment, got %v %q", p.PackageLicenseDeclared, p.PackageComment)
+	}
+	if p := result.Packages[2]; p.PackageLicenseDeclared != "NOASSERTION" {
+		t.Errorf("expected invalid license expression to be left out, got %v", p.PackageLicenseDeclared)
+	}
+}
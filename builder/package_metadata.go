@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// PackageMetadata holds metadata for the package described by a built
+// Document, which the builder cannot determine from the files themselves.
+// Empty fields leave the package's values as built.
+type PackageMetadata struct {
+	// Version is the package's version, replacing any version determined
+	// by the builder, such as from git tags.
+	Version string
+
+	// Supplier and Originator are given as in tag-value documents: either
+	// "Person: name (email)" or "Organization: name (email)", where the
+	// email address is optional, or "NOASSERTION".
+	Supplier   string
+	Originator string
+
+	// HomePage is an http or https URL, or "NONE" or "NOASSERTION".
+	HomePage string
+
+	// PrimaryPackagePurpose is one of the purposes defined by SPDX 2.3,
+	// such as "APPLICATION", "LIBRARY" or "FIRMWARE".
+	PrimaryPackagePurpose string
+
+	// LicenseDeclared is a license expression, or "NONE" or "NOASSERTION".
+	LicenseDeclared string
+
+	// ExternalRefs are added to the package's external references.
+	ExternalRefs []*spdx.PackageExternalReference
+
+	// Checksums are those of the package's release artifact, such as a
+	// tarball, and are added to the package's checksums.
+	Checksums []common.Checksum
+}
+
+// packagePurposes lists the primary package purposes defined by SPDX 2.3.
+var packagePurposes = map[string]bool{
+	"APPLICATION":      true,
+	"FRAMEWORK":        true,
+	"LIBRARY":          true,
+	"CONTAINER":        true,
+	"OPERATING-SYSTEM": true,
+	"DEVICE":           true,
+	"FIRMWARE":         true,
+	"SOURCE":           true,
+	"ARCHIVE":          true,
+	"FILE":             true,
+	"INSTALL":          true,
+	"OTHER":            true,
+}
+
+// checksumLengths gives the number of hex digits in a checksum of each
+// algorithm; a length of 0 means any non-zero length is accepted.
+var checksumLengths = map[common.ChecksumAlgorithm]int{
+	common.SHA1:        40,
+	common.SHA224:      56,
+	common.SHA256:      64,
+	common.SHA384:      96,
+	common.SHA512:      128,
+	common.MD2:         32,
+	common.MD4:         32,
+	common.MD5:         32,
+	common.MD6:         0,
+	common.SHA3_256:    64,
+	common.SHA3_384:    96,
+	common.SHA3_512:    128,
+	common.BLAKE2b_256: 64,
+	common.BLAKE2b_384: 96,
+	common.BLAKE2b_512: 128,
+	common.BLAKE3:      0,
+	common.ADLER32:     8,
+}
+
+var (
+	// agentRegexp matches "Person: name (email)" or "Organization: name
+	// (email)", where the email is optional.
+	agentRegexp = regexp.MustCompile(`^(Person|Organization):\s*([^()]*?)\s*(?:\(([^()]*)\))?$`)
+	hexRegexp   = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+// parseAgent splits a supplier or originator into its type and the name
+// and email stored by the Supplier and Originator structs. NOASSERTION
+// has an empty type.
+func parseAgent(field string, value string) (string, string, error) {
+	if value == "NOASSERTION" {
+		return "", value, nil
+	}
+	m := agentRegexp.FindStringSubmatch(value)
+	if m == nil || m[2] == "" {
+		return "", "", fmt.Errorf("invalid %s %q: expected \"Person: name (email)\", \"Organization: name (email)\" or NOASSERTION", field, value)
+	}
+	if m[3] != "" && !strings.Contains(m[3], "@") {
+		return "", "", fmt.Errorf("invalid %s %q: %q is not an email address", field, value, m[3])
+	}
+	if strings.Contains(value, "(") {
+		return m[1], m[2] + " (" + m[3] + ")", nil
+	}
+	return m[1], m[2], nil
+}
+
+// validate checks the format of each field of m.
+func (m *PackageMetadata) validate() error {
+	if strings.ContainsAny(m.Version, "\n\r") {
+		return fmt.Errorf("invalid version %q: must be a single line", m.Version)
+	}
+	if m.Supplier != "" {
+		if _, _, err := parseAgent("supplier", m.Supplier); err != nil {
+			return err
+		}
+	}
+	if m.Originator != "" {
+		if _, _, err := parseAgent("originator", m.Originator); err != nil {
+			return err
+		}
+	}
+	if m.HomePage != "" && m.HomePage != "NONE" && m.HomePage != "NOASSERTION" {
+		u, err := url.Parse(m.HomePage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid home page %q: expected an http or https URL, NONE or NOASSERTION", m.HomePage)
+		}
+	}
+	if m.PrimaryPackagePurpose != "" && !packagePurposes[m.PrimaryPackagePurpose] {
+		return fmt.Errorf("invalid primary package purpose %q", m.PrimaryPackagePurpose)
+	}
+	if m.LicenseDeclared != "" && m.LicenseDeclared != "NONE" && m.LicenseDeclared != "NOASSERTION" {
+		if _, err := licenseexpr.Parse(m.LicenseDeclared); err != nil {
+			return fmt.Errorf("invalid declared license %q: expected a license expression, NONE or NOASSERTION", m.LicenseDeclared)
+		}
+	}
+	for _, ref := range m.ExternalRefs {
+		if err := validateExternalRef(ref); err != nil {
+			return err
+		}
+	}
+	for _, c := range m.Checksums {
+		n, ok := checksumLengths[c.Algorithm]
+		if !ok {
+			return fmt.Errorf("invalid checksum algorithm %q", c.Algorithm)
+		}
+		if !hexRegexp.MatchString(c.Value) {
+			return fmt.Errorf("invalid %s checksum %q: expected lowercase hex digits", c.Algorithm, c.Value)
+		}
+		if n != 0 && len(c.Value) != n {
+			return fmt.Errorf("invalid %s checksum %q: expected %d hex digits", c.Algorithm, c.Value, n)
+		}
+	}
+	return nil
+}
+
+// validateExternalRef checks the category, type and locator of an
+// external reference, and the format of package URL and CPE locators.
+func validateExternalRef(ref *spdx.PackageExternalReference) error {
+	if ref == nil {
+		return fmt.Errorf("invalid external reference: nil")
+	}
+	switch ref.Category {
+	case common.CategorySecurity, common.CategoryPackageManager, "PACKAGE_MANAGER", common.CategoryPersistentId, common.CategoryOther:
+	default:
+		return fmt.Errorf("invalid external reference category %q", ref.Category)
+	}
+	if ref.RefType == "" || strings.ContainsAny(ref.RefType, " \t\n") {
+		return fmt.Errorf("invalid external reference type %q", ref.RefType)
+	}
+	if ref.Locator == "" || strings.ContainsAny(ref.Locator, " \t\n") {
+		return fmt.Errorf("invalid %s external reference locator %q", ref.RefType, ref.Locator)
+	}
+	prefix := map[string]string{
+		common.TypePackageManagerPURL: "pkg:",
+		common.TypeSecurityCPE23Type:  "cpe:2.3:",
+		common.TypeSecurityCPE22Type:  "cpe:/",
+	}[ref.RefType]
+	if !strings.HasPrefix(ref.Locator, prefix) {
+		return fmt.Errorf("invalid %s external reference locator %q: expected prefix %q", ref.RefType, ref.Locator, prefix)
+	}
+	return nil
+}
+
+// apply sets the fields of pkg given by m, which must be valid.
+func (m *PackageMetadata) apply(pkg *spdx.Package) {
+	if m.Version != "" {
+		pkg.PackageVersion = m.Version
+	}
+	if m.Supplier != "" {
+		t, s, _ := parseAgent("supplier", m.Supplier)
+		pkg.PackageSupplier = &common.Supplier{Supplier: s, SupplierType: t}
+	}
+	if m.Originator != "" {
+		t, o, _ := parseAgent("originator", m.Originator)
+		pkg.PackageOriginator = &common.Originator{Originator: o, OriginatorType: t}
+	}
+	if m.HomePage != "" {
+		pkg.PackageHomePage = m.HomePage
+	}
+	if m.PrimaryPackagePurpose != "" {
+		pkg.PrimaryPackagePurpose = m.PrimaryPackagePurpose
+	}
+	if m.LicenseDeclared != "" {
+		pkg.PackageLicenseDeclared = m.LicenseDeclared
+	}
+	for _, ref := range m.ExternalRefs {
+		r := *ref
+		pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &r)
+	}
+	pkg.PackageChecksums = append(pkg.PackageChecksums, m.Checksums...)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Package metadata tests =====
+func TestBuildAppliesPackageMetadata(t *testing.T) {
+	config := &Config{
+		Package: PackageMetadata{
+			Version:               "1.2.3",
+			Supplier:              "Organization: Example Inc. (sbom@example.com)",
+			Originator:            "Person: Jane Doe",
+			HomePage:              "https://example.com/project1",
+			PrimaryPackagePurpose: "LIBRARY",
+			LicenseDeclared:       "Apache-2.0 OR MIT",
+			ExternalRefs: []*spdx.PackageExternalReference{
+				{Category: common.CategorySecurity, RefType: common.TypeSecurityCPE23Type, Locator: "cpe:2.3:a:example:project1:1.2.3:*:*:*:*:*:*:*"},
+			},
+			Checksums: []common.Checksum{
+				{Algorithm: common.SHA256, Value: "2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4"},
+			},
+		},
+		TestValues: map[string]string{"Created": "2018-10-19T04:38:00Z"},
+	}
+	doc, err := Build("project1", "../testdata/project1", config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	pkg := doc.Packages[0]
+	if pkg.PackageVersion != "1.2.3" || pkg.PackageHomePage != "https://example.com/project1" || pkg.PrimaryPackagePurpose != "LIBRARY" {
+		t.Errorf("unexpected package %+v", pkg)
+	}
+	if *pkg.PackageSupplier != (common.Supplier{Supplier: "Example Inc. (sbom@example.com)", SupplierType: "Organization"}) {
+		t.Errorf("unexpected supplier %+v", pkg.PackageSupplier)
+	}
+	if *pkg.PackageOriginator != (common.Originator{Originator: "Jane Doe", OriginatorType: "Person"}) {
+		t.Errorf("unexpected originator %+v", pkg.PackageOriginator)
+	}
+	if pkg.PackageLicenseDeclared != "Apache-2.0 OR MIT" {
+		t.Errorf("expected %v, got %v", "Apache-2.0 OR MIT", pkg.PackageLicenseDeclared)
+	}
+	if len(pkg.PackageExternalReferences) != 1 || pkg.PackageExternalReferences[0] == config.Package.ExternalRefs[0] {
+		t.Errorf("expected a copy of the external reference, got %+v", pkg.PackageExternalReferences)
+	}
+	if len(pkg.PackageChecksums) != 1 || pkg.PackageChecksums[0].Algorithm != common.SHA256 {
+		t.Errorf("unexpected checksums %+v", pkg.PackageChecksums)
+	}
+}
+
+func TestPackageMetadataAcceptsNoAssertion(t *testing.T) {
+	m := PackageMetadata{Supplier: "NOASSERTION", Originator: "Organization: Example ()", HomePage: "NONE", LicenseDeclared: "NOASSERTION"}
+	if err := m.validate(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	pkg := &spdx.Package{}
+	m.apply(pkg)
+	if *pkg.PackageSupplier != (common.Supplier{Supplier: "NOASSERTION"}) {
+		t.Errorf("unexpected supplier %+v", pkg.PackageSupplier)
+	}
+	if pkg.PackageOriginator.Originator != "Example ()" {
+		t.Errorf("expected %v, got %v", "Example ()", pkg.PackageOriginator.Originator)
+	}
+}
+
+func TestPackageMetadataFailsWithInvalidFields(t *testing.T) {
+	for want, m := range map[string]PackageMetadata{
+		"invalid version":                     {Version: "1.0\n2.0"},
+		"invalid supplier":                    {Supplier: "Example Inc."},
+		"not an email address":                {Supplier: "Organization: Example Inc. (example.com)"},
+		"invalid originator":                  {Originator: "Tool: builder"},
+		"invalid home page":                   {HomePage: "example.com"},
+		"invalid primary package purpose":     {PrimaryPackagePurpose: "library"},
+		"invalid declared license":            {LicenseDeclared: "Apache 2.0"},
+		"expected a license expression":       {LicenseDeclared: "(MIT AND Apache-2.0"},
+		"invalid external reference category": {ExternalRefs: []*spdx.PackageExternalReference{{Category: "SECURE", RefType: "url", Locator: "https://example.com"}}},
+		"invalid external reference type":     {ExternalRefs: []*spdx.PackageExternalReference{{Category: common.CategoryOther, Locator: "x"}}},
+		"expected prefix":                     {ExternalRefs: []*spdx.PackageExternalReference{{Category: common.CategoryPackageManager, RefType: "purl", Locator: "npm/x@1"}}},
+		"invalid checksum algorithm":          {Checksums: []common.Checksum{{Algorithm: "CRC32", Value: "00000000"}}},
+		"expected 40 hex digits":              {Checksums: []common.Checksum{{Algorithm: common.SHA1, Value: "abcd"}}},
+		"expected lowercase hex digits":       {Checksums: []common.Checksum{{Algorithm: common.MD5, Value: "D41D8CD98F00B204E9800998ECF8427E"}}},
+		"invalid external reference: nil":     {ExternalRefs: []*spdx.PackageExternalReference{nil}},
+	} {
+		err := m.validate()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+		// the builders validate the metadata before reading anything
+		if _, err := Build("project1", "./oops/nonexistent", &Config{Package: m}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q from Build, got %v", want, err)
+		}
+	}
+}
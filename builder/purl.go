@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// makePurl builds a package URL (https://github.com/package-url/purl-spec)
+// from its components. The namespace may contain several slash-separated
+// segments, each of which is escaped separately; the namespace and version
+// are optional and omitted when empty.
+func makePurl(purlType string, namespace string, name string, version string) string {
+	var sb strings.Builder
+	sb.WriteString("pkg:")
+	sb.WriteString(purlType)
+	sb.WriteString("/")
+	if namespace != "" {
+		for _, segment := range strings.Split(namespace, "/") {
+			if segment == "" {
+				continue
+			}
+			sb.WriteString(escapePurlComponent(segment))
+			sb.WriteString("/")
+		}
+	}
+	sb.WriteString(escapePurlComponent(name))
+	if version != "" {
+		sb.WriteString("@")
+		sb.WriteString(escapePurlComponent(version))
+	}
+	return sb.String()
+}
+
+// escapePurlComponent percent-encodes everything in s other than the
+// unreserved URI characters.
+func escapePurlComponent(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+			c == '.' || c == '-' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// appendPurlQualifiers appends qualifiers to purl in key order, omitting
+// those with empty values. Values are percent-encoded, except for the ':'
+// and '/' characters used in URLs.
+func appendPurlQualifiers(purl string, qualifiers map[string]string) string {
+	sep := "?"
+	for _, k := range sortedKeys(qualifiers) {
+		v := qualifiers[k]
+		if v == "" {
+			continue
+		}
+		parts := strings.Split(v, "/")
+		for i, part := range parts {
+			segments := strings.Split(part, ":")
+			for j, segment := range segments {
+				segments[j] = escapePurlComponent(segment)
+			}
+			parts[i] = strings.Join(segments, ":")
+		}
+		purl += sep + k + "=" + strings.Join(parts, "/")
+		sep = "&"
+	}
+	return purl
+}
+
+// makePurlExternalRef wraps a package URL in a PACKAGE-MANAGER external
+// reference.
+func makePurlExternalRef(purl string) *spdx.PackageExternalReference {
+	return &spdx.PackageExternalReference{
+		Category: common.CategoryPackageManager,
+		RefType:  common.TypePackageManagerPURL,
+		Locator:  purl,
+	}
+}
@@ -0,0 +1,470 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by lockfiles and project
+// manifests such as Cargo.lock, poetry.lock and pyproject.toml: tables,
+// arrays of tables, dotted and quoted keys, strings (basic, literal and
+// multi-line), integers, floats, booleans, arrays and inline tables. Dates
+// and times are kept as strings. Tables are returned as
+// map[string]interface{} and arrays as []interface{}.
+func parseTOML(data string) (map[string]interface{}, error) {
+	p := &tomlParser{data: data, line: 1}
+	root := map[string]interface{}{}
+	current := root
+
+	for {
+		p.skipWhitespaceAndNewlines()
+		if p.eof() {
+			return root, nil
+		}
+
+		switch {
+		case strings.HasPrefix(p.data[p.pos:], "[["):
+			p.pos += 2
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]]"); err != nil {
+				return nil, err
+			}
+			parent, err := tomlDescend(root, keys[:len(keys)-1])
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			last := keys[len(keys)-1]
+			arr, _ := parent[last].([]interface{})
+			if _, exists := parent[last]; exists && arr == nil {
+				return nil, p.errorf("key %s is not an array of tables", strings.Join(keys, "."))
+			}
+			current = map[string]interface{}{}
+			parent[last] = append(arr, current)
+		case p.peek() == '[':
+			p.pos++
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			current, err = tomlDescend(root, keys)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		default:
+			if err := p.parseKeyValue(current); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tomlDescend follows keys from table t, creating tables as needed. If a
+// key refers to an array of tables, its last element is used.
+func tomlDescend(t map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			t[k] = next
+			t = next
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("key %s is an empty array", k)
+			}
+			next, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %s is not a table", k)
+			}
+			t = next
+		default:
+			return nil, fmt.Errorf("key %s is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+type tomlParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool { return p.pos >= len(p.data) }
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *tomlParser) expect(s string) error {
+	p.skipSpaces()
+	if !strings.HasPrefix(p.data[p.pos:], s) {
+		return p.errorf("expected %q", s)
+	}
+	p.pos += len(s)
+	return nil
+}
+
+// skipSpaces skips spaces and tabs.
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipWhitespaceAndNewlines skips blank lines and comments.
+func (p *tomlParser) skipWhitespaceAndNewlines() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// endOfLine consumes the rest of the current line, which may only contain
+// whitespace and a comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+// parseKey parses a possibly dotted key, made of bare or quoted parts.
+func (p *tomlParser) parseKey() ([]string, error) {
+	keys := []string{}
+	for {
+		p.skipSpaces()
+		var k string
+		switch p.peek() {
+		case '"', '\'':
+			s, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			k = s
+		default:
+			start := p.pos
+			for !p.eof() {
+				c := p.peek()
+				if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-' {
+					p.pos++
+					continue
+				}
+				break
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected key")
+			}
+			k = p.data[start:p.pos]
+		}
+		keys = append(keys, k)
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// parseKeyValue parses "key = value" into table t.
+func (p *tomlParser) parseKeyValue(t map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	parent, err := tomlDescend(t, keys[:len(keys)-1])
+	if err != nil {
+		return p.errorf("%v", err)
+	}
+	parent[keys[len(keys)-1]] = v
+	return nil
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.data[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.data[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	}
+
+	// numbers, dates and times run until a delimiter
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}#\n\r", rune(p.peek())) {
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.data[start:p.pos])
+	if raw == "" {
+		return nil, p.errorf("expected value")
+	}
+	if i, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	// dates and times are kept as strings
+	if strings.Trim(raw, "0123456789-:.+TZtz ") != "" {
+		return nil, p.errorf("invalid value %q", raw)
+	}
+	return raw, nil
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	switch {
+	case strings.HasPrefix(p.data[p.pos:], `"""`):
+		return p.parseMultilineString(`"""`, true)
+	case strings.HasPrefix(p.data[p.pos:], `'''`):
+		return p.parseMultilineString(`'''`, false)
+	case p.peek() == '\'':
+		end := strings.IndexAny(p.data[p.pos+1:], "'\n")
+		if end < 0 || p.data[p.pos+1+end] != '\'' {
+			return "", p.errorf("unterminated string")
+		}
+		s := p.data[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return s, nil
+	}
+
+	// basic string, with escapes
+	var sb strings.Builder
+	p.pos++
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		if c == '"' {
+			p.pos++
+			return sb.String(), nil
+		}
+		if c == '\\' {
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseMultilineString(delim string, escapes bool) (string, error) {
+	p.pos += len(delim)
+	// a newline immediately after the opening delimiter is trimmed
+	if strings.HasPrefix(p.data[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.data[p.pos:], delim) {
+			p.pos += len(delim)
+			return sb.String(), nil
+		}
+		c := p.peek()
+		if c == '\\' && escapes {
+			// a backslash at the end of a line trims following whitespace
+			rest := strings.TrimLeft(p.data[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				trimmed := strings.TrimLeft(rest, " \t\r\n")
+				p.line += strings.Count(rest[:len(rest)-len(trimmed)], "\n")
+				p.pos = len(p.data) - len(trimmed)
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if c == '\n' {
+			p.line++
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	if p.pos+1 >= len(p.data) {
+		return p.errorf("invalid escape")
+	}
+	c := p.data[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.data) {
+			return p.errorf("invalid unicode escape")
+		}
+		r, err := strconv.ParseUint(p.data[p.pos:p.pos+n], 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape")
+		}
+		sb.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	arr := []interface{}{}
+	for {
+		p.skipWhitespaceAndNewlines()
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		p.skipWhitespaceAndNewlines()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	t := map[string]interface{}{}
+	for {
+		p.skipSpaces()
+		if p.peek() == '}' {
+			p.pos++
+			return t, nil
+		}
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// tomlString returns t[key] if it is a string, or "" otherwise.
+func tomlString(t map[string]interface{}, key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// tomlTable returns t[key] if it is a table, or nil otherwise.
+func tomlTable(t map[string]interface{}, key string) map[string]interface{} {
+	m, _ := t[key].(map[string]interface{})
+	return m
+}
+
+// tomlTables returns the elements of t[key] that are tables, if t[key] is
+// an array.
+func tomlTables(t map[string]interface{}, key string) []map[string]interface{} {
+	arr, _ := t[key].([]interface{})
+	tables := []map[string]interface{}{}
+	for _, v := range arr {
+		if m, ok := v.(map[string]interface{}); ok {
+			tables = append(tables, m)
+		}
+	}
+	return tables
+}
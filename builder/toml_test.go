@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestFile writes contents to name within dir, failing the test on
+// error.
+func writeTestFile(t *testing.T, dir string, name string, contents string) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(p, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// ===== TOML parser tests =====
+func TestTOMLCanParseTablesAndValues(t *testing.T) {
+	data := `
+# comment
+title = "x\ty" # trailing comment
+literal = 'C:\path'
+count = 1_000
+ratio = 0.5
+enabled = true
+date = 1979-05-27
+multi = """
+line1
+line2"""
+
+[server."with.dot"]
+ports = [ 8000,
+  8001, # comment
+]
+inline = { a = "b", c.d = 1 }
+
+[[item]]
+name = "first"
+
+[item.sub]
+x = 1
+
+[[item]]
+name = "second"
+`
+	got, err := parseTOML(data)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := map[string]interface{}{
+		"title":   "x\ty",
+		"literal": `C:\path`,
+		"count":   int64(1000),
+		"ratio":   0.5,
+		"enabled": true,
+		"date":    "1979-05-27",
+		"multi":   "line1\nline2",
+		"server": map[string]interface{}{
+			"with.dot": map[string]interface{}{
+				"ports": []interface{}{int64(8000), int64(8001)},
+				"inline": map[string]interface{}{
+					"a": "b",
+					"c": map[string]interface{}{"d": int64(1)},
+				},
+			},
+		},
+		"item": []interface{}{
+			map[string]interface{}{
+				"name": "first",
+				"sub":  map[string]interface{}{"x": int64(1)},
+			},
+			map[string]interface{}{"name": "second"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
+func TestTOMLFailsForInvalidInput(t *testing.T) {
+	for _, data := range []string{
+		`a = "unterminated`,
+		`a = [1, 2`,
+		`a = 1 b = 2`,
+		`[table`,
+		"a = 1\n[[a]]",
+	} {
+		if _, err := parseTOML(data); err == nil {
+			t.Errorf("expected non-nil error for %q, got nil", data)
+		}
+	}
+}
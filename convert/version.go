@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx/common"
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_1"
+	"github.com/spdx/tools-golang/spdx/v2/v2_2"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+// newDocument returns a pointer to a new document of the given SPDX
+// version, such as "SPDX-2.2"; the "SPDX-" prefix may be left out.
+func newDocument(version string) (common.AnyDocument, error) {
+	if !strings.HasPrefix(version, "SPDX-") {
+		version = "SPDX-" + version
+	}
+	switch version {
+	case v2_1.Version:
+		return &v2_1.Document{}, nil
+	case v2_2.Version:
+		return &v2_2.Document{}, nil
+	case v2_3.Version:
+		return &v2_3.Document{}, nil
+	}
+	return nil, fmt.Errorf("unsupported SPDX version: %s", version)
+}
+
+// ToVersion converts doc to a pointer to a document of the given SPDX
+// version, such as "SPDX-2.2", through the conversion chain. It also
+// returns the fields of doc that the version cannot represent, as JSON
+// paths such as "packages[0].primaryPackagePurpose", which are dropped by
+// the conversion. Relationships of types and checksums using algorithms
+// that the version does not define are dropped too, and returned as paths
+// such as "relationships[2]" and "packages[0].files[1].checksums[0]".
+func ToVersion(doc common.AnyDocument, version string) (common.AnyDocument, []string, error) {
+	to, err := newDocument(version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := Document(doc, to); err != nil {
+		return nil, nil, err
+	}
+
+	// convert back, and find the fields that did not survive
+	back := reflect.New(reflect.TypeOf(FromPtr(doc))).Interface()
+	if err := Document(to, back); err != nil {
+		return nil, nil, err
+	}
+	before, err := jsonValue(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := jsonValue(back)
+	if err != nil {
+		return nil, nil, err
+	}
+	dropped := []string{}
+	droppedFields("", before, after, &dropped)
+	dropped = append(dropped, dropUndefined(to)...)
+	sort.Strings(dropped)
+	return to, dropped, nil
+}
+
+// dropUndefined removes the relationships and checksums of doc that its
+// SPDX version does not define, which the conversion chain passes through
+// unchanged, and returns their paths. Documents of the latest version are
+// left as they are. doc may share its packages and files with the
+// converted document, so they are copied rather than changed.
+func dropUndefined(doc common.AnyDocument) []string {
+	dropped := []string{}
+	switch doc := doc.(type) {
+	case *v2_1.Document:
+		doc.Packages = copyEach(doc.Packages, func(i int, pkg *v2_1.Package) {
+			path := fmt.Sprintf("packages[%d]", i)
+			pkg.PackageChecksums = dropChecksums(pkg.PackageChecksums, v2_1.Version, path, &dropped)
+			pkg.Files = copyEach(pkg.Files, func(j int, f *v2_1.File) {
+				f.Checksums = dropChecksums(f.Checksums, v2_1.Version, fmt.Sprintf("%s.files[%d]", path, j), &dropped)
+			})
+		})
+		doc.Files = copyEach(doc.Files, func(i int, f *v2_1.File) {
+			f.Checksums = dropChecksums(f.Checksums, v2_1.Version, fmt.Sprintf("files[%d]", i), &dropped)
+		})
+		doc.Relationships = dropItems(doc.Relationships, "relationships", &dropped, func(r *v2_1.Relationship) bool {
+			return r == nil || definedRelationship(r.Relationship, v2_1.Version)
+		})
+	case *v2_2.Document:
+		doc.Packages = copyEach(doc.Packages, func(i int, pkg *v2_2.Package) {
+			path := fmt.Sprintf("packages[%d]", i)
+			pkg.PackageChecksums = dropChecksums(pkg.PackageChecksums, v2_2.Version, path, &dropped)
+			pkg.Files = copyEach(pkg.Files, func(j int, f *v2_2.File) {
+				f.Checksums = dropChecksums(f.Checksums, v2_2.Version, fmt.Sprintf("%s.files[%d]", path, j), &dropped)
+			})
+		})
+		doc.Files = copyEach(doc.Files, func(i int, f *v2_2.File) {
+			f.Checksums = dropChecksums(f.Checksums, v2_2.Version, fmt.Sprintf("files[%d]", i), &dropped)
+		})
+		doc.Relationships = dropItems(doc.Relationships, "relationships", &dropped, func(r *v2_2.Relationship) bool {
+			return r == nil || definedRelationship(r.Relationship, v2_2.Version)
+		})
+	}
+	return dropped
+}
+
+// copyEach returns a copy of items in which each item that is not nil is
+// replaced by a copy, changed by change. items is not changed.
+func copyEach[T any](items []*T, change func(i int, item *T)) []*T {
+	if items == nil {
+		return nil
+	}
+	copied := make([]*T, len(items))
+	for i, item := range items {
+		if item != nil {
+			c := *item
+			change(i, &c)
+			item = &c
+		}
+		copied[i] = item
+	}
+	return copied
+}
+
+// definedRelationship reports whether relType is defined by version, or
+// is unknown to every version, in which case it is left for validation to
+// report.
+func definedRelationship(relType string, version string) bool {
+	t := v2common.RelationshipType(relType)
+	if _, known := t.Info(); !known {
+		return true
+	}
+	return t.AvailableIn(version)
+}
+
+func dropChecksums(checksums []v2common.Checksum, version string, path string, dropped *[]string) []v2common.Checksum {
+	return dropItems(checksums, path+".checksums", dropped, func(c v2common.Checksum) bool {
+		// like relationship types, unknown algorithms are left for
+		// validation to report
+		return c.Algorithm.AvailableIn(version) || !c.Algorithm.AvailableIn(v2_3.Version)
+	})
+}
+
+// dropItems returns the items that keep reports true for, adding the
+// paths, under path, of the others to dropped. items is not changed.
+func dropItems[T any](items []T, path string, dropped *[]string, keep func(T) bool) []T {
+	var kept []T
+	for i, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		} else {
+			*dropped = append(*dropped, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+	if len(kept) == len(items) {
+		return items
+	}
+	return kept
+}
+
+func jsonValue(doc common.AnyDocument) (interface{}, error) {
+	if !IsPtr(doc) {
+		// pass a pointer, so that marshaling methods are used
+		ptr := reflect.New(reflect.TypeOf(doc))
+		ptr.Elem().Set(reflect.ValueOf(doc))
+		doc = ptr.Interface()
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(b, &value)
+	return value, err
+}
+
+// droppedFields adds to dropped the paths, under path, of the values of
+// before that are missing from or different in after. The SPDX version is
+// expected to differ, and is not reported.
+func droppedFields(path string, before interface{}, after interface{}, dropped *[]string) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, _ := after.(map[string]interface{})
+		for key, value := range b {
+			if path == "" && key == "spdxVersion" {
+				continue
+			}
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			droppedFields(field, value, a[key], dropped)
+		}
+	case []interface{}:
+		a, _ := after.([]interface{})
+		for i, value := range b {
+			var other interface{}
+			if i < len(a) {
+				other = a[i]
+			}
+			droppedFields(fmt.Sprintf("%s[%d]", path, i), value, other, dropped)
+		}
+	default:
+		if before != nil && !reflect.DeepEqual(before, after) {
+			*dropped = append(*dropped, path)
+		}
+	}
+}
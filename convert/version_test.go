@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_1"
+	"github.com/spdx/tools-golang/spdx/v2/v2_2"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+func Test_ToVersion(t *testing.T) {
+	doc := v2_3.Document{
+		SPDXVersion:    v2_3.Version,
+		DocumentName:   "test_doc",
+		SPDXIdentifier: "DOCUMENT",
+		Packages: []*v2_3.Package{
+			{
+				PackageName:           "p",
+				PackageSPDXIdentifier: "p",
+				PrimaryPackagePurpose: "LIBRARY",
+				BuiltDate:             "2020-01-01T00:00:00Z",
+				PackageChecksums:      []common.Checksum{{Algorithm: common.SHA1, Value: "abc"}},
+			},
+		},
+	}
+
+	converted, dropped, err := ToVersion(doc, "SPDX-2.1")
+	require.NoError(t, err)
+	v21, ok := converted.(*v2_1.Document)
+	require.True(t, ok)
+	assert.Equal(t, v2_1.Version, v21.SPDXVersion)
+	assert.Equal(t, "p", v21.Packages[0].PackageName)
+	assert.Equal(t, []string{"packages[0].builtDate", "packages[0].primaryPackagePurpose"}, dropped)
+
+	// converting up, or to the same version, drops nothing
+	converted, dropped, err = ToVersion(&v2_2.Document{SPDXVersion: v2_2.Version, DocumentName: "test_doc"}, "2.3")
+	require.NoError(t, err)
+	assert.IsType(t, &v2_3.Document{}, converted)
+	assert.Empty(t, dropped)
+
+	_, dropped, err = ToVersion(doc, v2_3.Version)
+	require.NoError(t, err)
+	assert.Empty(t, dropped)
+
+	_, _, err = ToVersion(doc, "SPDX-3.0")
+	assert.Error(t, err)
+}
+
+func Test_ToVersionDropsUndefinedTypesAndAlgorithms(t *testing.T) {
+	doc := &v2_3.Document{
+		SPDXVersion:    v2_3.Version,
+		DocumentName:   "test_doc",
+		SPDXIdentifier: "DOCUMENT",
+		Packages: []*v2_3.Package{
+			{
+				PackageName:           "p",
+				PackageSPDXIdentifier: "p",
+				PackageChecksums: []common.Checksum{
+					{Algorithm: common.BLAKE3, Value: "abc"},
+					{Algorithm: common.SHA1, Value: "def"},
+				},
+				Files: []*v2_3.File{
+					{FileName: "./a", FileSPDXIdentifier: "a", Checksums: []common.Checksum{
+						{Algorithm: common.SHA1, Value: "abc"},
+						{Algorithm: common.SHA512, Value: "def"},
+					}},
+				},
+			},
+		},
+		Relationships: []*v2_3.Relationship{
+			{RefA: common.MakeDocElementID("", "DOCUMENT"), RefB: common.MakeDocElementID("", "p"), Relationship: common.TypeRelationshipDescribe},
+			{RefA: common.MakeDocElementID("", "p"), RefB: common.MakeDocElementID("", "a"), Relationship: common.TypeRelationshipSpecificationFor},
+			{RefA: common.MakeDocElementID("", "p"), RefB: common.MakeDocElementID("", "a"), Relationship: "MADE_UP"},
+		},
+	}
+
+	converted, dropped, err := ToVersion(doc, "SPDX-2.2")
+	require.NoError(t, err)
+	v22 := converted.(*v2_2.Document)
+	assert.Equal(t, []string{"packages[0].checksums[0]", "relationships[1]"}, dropped)
+	assert.Equal(t, []common.Checksum{{Algorithm: common.SHA1, Value: "def"}}, v22.Packages[0].PackageChecksums)
+	assert.Len(t, v22.Packages[0].Files[0].Checksums, 2)
+	require.Len(t, v22.Relationships, 2)
+	assert.Equal(t, "MADE_UP", v22.Relationships[1].Relationship)
+
+	_, dropped, err = ToVersion(doc, "SPDX-2.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages[0].checksums[0]", "packages[0].files[0].checksums[1]", "relationships[1]"}, dropped)
+
+	// the input document is not changed
+	assert.Len(t, doc.Packages[0].PackageChecksums, 2)
+	assert.Len(t, doc.Relationships, 3)
+}
+
+func Test_ToVersionDoesNotChangeDocumentOfSameVersion(t *testing.T) {
+	checksums := []common.Checksum{
+		{Algorithm: common.SHA3_256, Value: "abc"},
+		{Algorithm: common.SHA1, Value: "def"},
+	}
+	doc := &v2_2.Document{
+		SPDXVersion:    v2_2.Version,
+		DocumentName:   "test_doc",
+		SPDXIdentifier: "DOCUMENT",
+		Packages: []*v2_2.Package{
+			{PackageName: "p", PackageSPDXIdentifier: "p", PackageChecksums: checksums},
+			nil,
+		},
+		Files: []*v2_2.File{
+			{FileName: "./a", FileSPDXIdentifier: "a", Checksums: checksums},
+		},
+	}
+
+	converted, dropped, err := ToVersion(doc, "SPDX-2.2")
+	require.NoError(t, err)
+	v22 := converted.(*v2_2.Document)
+	assert.Equal(t, []string{"files[0].checksums[0]", "packages[0].checksums[0]"}, dropped)
+	assert.Equal(t, checksums[1:], v22.Packages[0].PackageChecksums)
+	assert.Equal(t, checksums[1:], v22.Files[0].Checksums)
+
+	assert.Equal(t, checksums, doc.Packages[0].PackageChecksums)
+	assert.Equal(t, checksums, doc.Files[0].Checksums)
+}
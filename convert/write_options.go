@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package convert
+
+import (
+	"github.com/spdx/tools-golang/spdx/common"
+)
+
+// WriteConfig holds the settings of the WriteOptions shared by the json,
+// yaml and tagvalue writers.
+type WriteConfig struct {
+	// TargetVersion is the SPDX version, such as "SPDX-2.2", to convert
+	// the document to before writing it; if empty, it is written as is.
+	TargetVersion string
+	// DroppedFields, if set, is called with the fields of the document
+	// dropped by converting it to TargetVersion, if there are any.
+	DroppedFields func(fields []string)
+}
+
+type WriteOption func(*WriteConfig)
+
+// TargetVersion converts the document to the given SPDX version, such as
+// "SPDX-2.2", before writing it, as by ToVersion.
+func TargetVersion(version string) WriteOption {
+	return func(c *WriteConfig) {
+		c.TargetVersion = version
+	}
+}
+
+// DroppedFields calls report with the fields of the document dropped by
+// TargetVersion because the target version cannot represent them, if
+// there are any.
+func DroppedFields(report func(fields []string)) WriteOption {
+	return func(c *WriteConfig) {
+		c.DroppedFields = report
+	}
+}
+
+// Apply returns doc as it should be written according to c: converted to
+// c.TargetVersion, if set, with the dropped fields reported to
+// c.DroppedFields.
+func (c *WriteConfig) Apply(doc common.AnyDocument) (common.AnyDocument, error) {
+	if c.TargetVersion == "" {
+		return doc, nil
+	}
+	converted, dropped, err := ToVersion(doc, c.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(dropped) > 0 && c.DroppedFields != nil {
+		c.DroppedFields(dropped)
+	}
+	return converted, nil
+}
+
+// ApplyWriteOptions returns doc as it should be written according to
+// opts, as by WriteConfig.Apply.
+func ApplyWriteOptions(doc common.AnyDocument, opts ...WriteOption) (common.AnyDocument, error) {
+	c := &WriteConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c.Apply(doc)
+}
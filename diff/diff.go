@@ -0,0 +1,406 @@
+// Package diff compares two SPDX Documents, such as those of two releases
+// of a product, matching their elements by identity rather than position.
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ChangeKind is the kind of a change to an element.
+type ChangeKind string
+
+// Change kinds
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// FieldChange is a change to one field of an element. A field with several
+// values, such as a package's external references, has a FieldChange for
+// each value added (with an empty Old) or removed (with an empty New).
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// ElementChange is an element that was added, removed or changed.
+type ElementChange struct {
+	Kind ChangeKind `json:"kind"`
+	// ID identifies the element: its SPDXID, or for an OtherLicense, its
+	// license identifier, or for an external document reference, its
+	// DocumentRef. It is the ID in the second document, unless the element
+	// was removed.
+	ID string `json:"id"`
+	// OldID is the ID in the first document of a changed element, if it is
+	// different.
+	OldID string `json:"oldId,omitempty"`
+	// Name is a readable name for the element, such as a package's name
+	// and version, or a file's name.
+	Name string `json:"name,omitempty"`
+	// MatchedBy is how a changed element was matched between the
+	// documents: "SPDXID" (or license identifier, or DocumentRef), "purl",
+	// "checksum", "name", "path", "text" or "URI".
+	MatchedBy string        `json:"matchedBy,omitempty"`
+	Fields    []FieldChange `json:"fields,omitempty"`
+}
+
+// RelationshipChange is a relationship that was added, removed or whose
+// comment changed.
+type RelationshipChange struct {
+	Kind ChangeKind `json:"kind"`
+	// Relationship is the relationship as in tag-value, such as
+	// "SPDXRef-a DEPENDS_ON SPDXRef-b", with the element IDs of the second
+	// document, unless it was removed.
+	Relationship string        `json:"relationship"`
+	Fields       []FieldChange `json:"fields,omitempty"`
+}
+
+// Diff is the difference between two Documents.
+type Diff struct {
+	// Document lists changes to the document's own fields and its creation
+	// information.
+	Document             []FieldChange        `json:"document,omitempty"`
+	Packages             []ElementChange      `json:"packages,omitempty"`
+	Files                []ElementChange      `json:"files,omitempty"`
+	Snippets             []ElementChange      `json:"snippets,omitempty"`
+	Relationships        []RelationshipChange `json:"relationships,omitempty"`
+	OtherLicenses        []ElementChange      `json:"otherLicenses,omitempty"`
+	ExternalDocumentRefs []ElementChange      `json:"externalDocumentRefs,omitempty"`
+}
+
+// IsEmpty reports whether the documents compared are the same.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Document) == 0 && len(d.Packages) == 0 && len(d.Files) == 0 && len(d.Snippets) == 0 &&
+		len(d.Relationships) == 0 && len(d.OtherLicenses) == 0 && len(d.ExternalDocumentRefs) == 0
+}
+
+// Compare returns the difference between the documents first and second.
+//
+// Packages are matched by purl, ignoring its version, then by SPDXID, then
+// by checksum and finally by name. Files are matched by path, then by
+// checksum, so that a moved file is changed rather than removed and added,
+// then by SPDXID. Snippets are matched by SPDXID, OtherLicenses by license
+// identifier and then by text, and external document references by
+// DocumentRef and then by URI. Relationships are compared using the
+// matched elements, so that renaming an element does not change its
+// relationships, and a relationship and its inverse, such as "A CONTAINS B"
+// and "B CONTAINED_BY A", are the same.
+func Compare(first *spdx.Document, second *spdx.Document) *Diff {
+	if first == nil {
+		first = &spdx.Document{}
+	}
+	if second == nil {
+		second = &spdx.Document{}
+	}
+	d := &Diff{Document: compareDocumentFields(first, second)}
+	// ids maps element IDs of the second document to those of the first
+	ids := map[common.ElementID]common.ElementID{second.SPDXIdentifier: first.SPDXIdentifier}
+
+	oldPkgs, newPkgs := nonNilPackages(first.Packages), nonNilPackages(second.Packages)
+	d.Packages = compareElements(oldPkgs, newPkgs, []matcher[*spdx.Package]{
+		{"purl", packagePurlKeys},
+		{"SPDXID", func(p *spdx.Package) []string { return []string{string(p.PackageSPDXIdentifier)} }},
+		{"checksum", func(p *spdx.Package) []string { return checksumKeys(p.PackageChecksums) }},
+		{"name", func(p *spdx.Package) []string { return []string{p.PackageName} }},
+	}, func(p *spdx.Package) (string, string) {
+		return common.RenderElementID(p.PackageSPDXIdentifier), strings.TrimSpace(p.PackageName + " " + p.PackageVersion)
+	}, comparePackages, func(o, n *spdx.Package) {
+		ids[n.PackageSPDXIdentifier] = o.PackageSPDXIdentifier
+	})
+
+	d.Files = compareElements(allFiles(first), allFiles(second), []matcher[*spdx.File]{
+		{"path", func(f *spdx.File) []string { return []string{f.FileName} }},
+		{"checksum", func(f *spdx.File) []string { return checksumKeys(f.Checksums) }},
+		{"SPDXID", func(f *spdx.File) []string { return []string{string(f.FileSPDXIdentifier)} }},
+	}, func(f *spdx.File) (string, string) {
+		return common.RenderElementID(f.FileSPDXIdentifier), f.FileName
+	}, compareFiles, func(o, n *spdx.File) {
+		ids[n.FileSPDXIdentifier] = o.FileSPDXIdentifier
+	})
+
+	d.Snippets = compareElements(allSnippets(first), allSnippets(second), []matcher[*spdx.Snippet]{
+		{"SPDXID", func(s *spdx.Snippet) []string { return []string{string(s.SnippetSPDXIdentifier)} }},
+	}, func(s *spdx.Snippet) (string, string) {
+		return common.RenderElementID(s.SnippetSPDXIdentifier), s.SnippetName
+	}, compareSnippets, func(o, n *spdx.Snippet) {
+		ids[n.SnippetSPDXIdentifier] = o.SnippetSPDXIdentifier
+	})
+
+	d.OtherLicenses = compareElements(nonNilLicenses(first.OtherLicenses), nonNilLicenses(second.OtherLicenses), []matcher[*spdx.OtherLicense]{
+		{"SPDXID", func(l *spdx.OtherLicense) []string { return []string{l.LicenseIdentifier} }},
+		{"text", func(l *spdx.OtherLicense) []string { return []string{l.ExtractedText} }},
+	}, func(l *spdx.OtherLicense) (string, string) {
+		return l.LicenseIdentifier, l.LicenseName
+	}, compareOtherLicenses, nil)
+
+	d.ExternalDocumentRefs = compareElements(externalDocumentRefs(first), externalDocumentRefs(second), []matcher[*spdx.ExternalDocumentRef]{
+		{"SPDXID", func(r *spdx.ExternalDocumentRef) []string { return []string{string(r.DocumentRefID)} }},
+		{"URI", func(r *spdx.ExternalDocumentRef) []string { return []string{r.URI} }},
+	}, func(r *spdx.ExternalDocumentRef) (string, string) {
+		return "DocumentRef-" + string(r.DocumentRefID), r.URI
+	}, compareExternalDocumentRefs, nil)
+
+	d.Relationships = compareRelationships(first.Relationships, second.Relationships, ids)
+	return d
+}
+
+// matcher matches elements of the two documents with the same key. An
+// element may have several keys, such as its checksums.
+type matcher[T any] struct {
+	name string
+	keys func(T) []string
+}
+
+// compareElements matches the elements of the two documents with each
+// matcher in turn, and returns the changes: each element of the second
+// document that changed or was added, in order, then each element of the
+// first that was removed. matched, if not nil, is called for each pair.
+func compareElements[T any](olds []T, news []T, matchers []matcher[T], describe func(T) (string, string),
+	compare func(T, T) []FieldChange, matched func(T, T)) []ElementChange {
+	oldFor := make([]int, len(news))
+	matchedBy := make([]string, len(news))
+	for i := range oldFor {
+		oldFor[i] = -1
+	}
+	oldUsed := make([]bool, len(olds))
+
+	for _, m := range matchers {
+		byKey := map[string]int{}
+		for i, o := range olds {
+			if oldUsed[i] {
+				continue
+			}
+			for _, key := range m.keys(o) {
+				if _, ok := byKey[key]; !ok && key != "" {
+					byKey[key] = i
+				}
+			}
+		}
+		for i, n := range news {
+			if oldFor[i] >= 0 {
+				continue
+			}
+			for _, key := range m.keys(n) {
+				if j, ok := byKey[key]; ok && key != "" && !oldUsed[j] {
+					oldFor[i], matchedBy[i], oldUsed[j] = j, m.name, true
+					break
+				}
+			}
+		}
+	}
+
+	changes := []ElementChange{}
+	for i, n := range news {
+		id, name := describe(n)
+		if oldFor[i] < 0 {
+			changes = append(changes, ElementChange{Kind: Added, ID: id, Name: name})
+			continue
+		}
+		o := olds[oldFor[i]]
+		if matched != nil {
+			matched(o, n)
+		}
+		oldID, _ := describe(o)
+		fields := compare(o, n)
+		if len(fields) == 0 && oldID == id {
+			continue
+		}
+		change := ElementChange{Kind: Changed, ID: id, Name: name, MatchedBy: matchedBy[i], Fields: fields}
+		if oldID != id {
+			change.OldID = oldID
+		}
+		changes = append(changes, change)
+	}
+	for j, o := range olds {
+		if !oldUsed[j] {
+			id, name := describe(o)
+			changes = append(changes, ElementChange{Kind: Removed, ID: id, Name: name})
+		}
+	}
+	return changes
+}
+
+// relationshipKey identifies a relationship, in the direction of the
+// exact inverse types, such as CONTAINS for CONTAINED_BY.
+type relationshipKey struct {
+	refA, refB   common.DocElementID
+	relationship string
+}
+
+func makeRelationshipKey(rln *spdx.Relationship, ids map[common.ElementID]common.ElementID) relationshipKey {
+	mapID := func(id common.DocElementID) common.DocElementID {
+		if id.DocumentRefID == "" && id.SpecialID == "" {
+			if mapped, ok := ids[id.ElementRefID]; ok {
+				id.ElementRefID = mapped
+			}
+		}
+		return id
+	}
+	key := relationshipKey{mapID(rln.RefA), mapID(rln.RefB), rln.Relationship}
+	if info, ok := common.RelationshipType(rln.Relationship).Info(); ok && info.Reversed {
+		key = relationshipKey{key.refB, key.refA, string(info.Inverse)}
+	}
+	return key
+}
+
+func compareRelationships(olds []*spdx.Relationship, news []*spdx.Relationship, ids map[common.ElementID]common.ElementID) []RelationshipChange {
+	oldByKey := map[relationshipKey]*spdx.Relationship{}
+	for _, rln := range olds {
+		if rln == nil {
+			continue
+		}
+		key := makeRelationshipKey(rln, nil)
+		if _, ok := oldByKey[key]; !ok {
+			oldByKey[key] = rln
+		}
+	}
+
+	changes := []RelationshipChange{}
+	seen := map[relationshipKey]bool{}
+	for _, rln := range news {
+		if rln == nil {
+			continue
+		}
+		key := makeRelationshipKey(rln, ids)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		o, ok := oldByKey[key]
+		if !ok {
+			changes = append(changes, RelationshipChange{Kind: Added, Relationship: renderRelationship(rln)})
+			continue
+		}
+		fields := []FieldChange{}
+		compareField(&fields, "comment", o.RelationshipComment, rln.RelationshipComment)
+		if len(fields) > 0 {
+			changes = append(changes, RelationshipChange{Kind: Changed, Relationship: renderRelationship(rln), Fields: fields})
+		}
+	}
+	for _, rln := range olds {
+		if rln == nil {
+			continue
+		}
+		key := makeRelationshipKey(rln, nil)
+		if !seen[key] {
+			seen[key] = true
+			changes = append(changes, RelationshipChange{Kind: Removed, Relationship: renderRelationship(rln)})
+		}
+	}
+	return changes
+}
+
+func renderRelationship(rln *spdx.Relationship) string {
+	return fmt.Sprintf("%s %s %s", common.RenderDocElementID(rln.RefA), rln.Relationship, common.RenderDocElementID(rln.RefB))
+}
+
+// packagePurlKeys returns the purls of p without their versions,
+// qualifiers and subpaths.
+func packagePurlKeys(p *spdx.Package) []string {
+	keys := []string{}
+	for _, ref := range p.PackageExternalReferences {
+		if ref == nil || ref.RefType != common.TypePackageManagerPURL {
+			continue
+		}
+		purl := ref.Locator
+		if i := strings.IndexAny(purl, "?#"); i >= 0 {
+			purl = purl[:i]
+		}
+		// the version follows the last "@"; one in a name is escaped
+		if i := strings.LastIndex(purl, "@"); i >= 0 {
+			purl = purl[:i]
+		}
+		keys = append(keys, purl)
+	}
+	return keys
+}
+
+func checksumKeys(checksums []common.Checksum) []string {
+	keys := []string{}
+	for _, c := range checksums {
+		keys = append(keys, string(c.Algorithm)+":"+strings.ToLower(c.Value))
+	}
+	return keys
+}
+
+func nonNilPackages(pkgs []*spdx.Package) []*spdx.Package {
+	result := []*spdx.Package{}
+	for _, p := range pkgs {
+		if p != nil {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func nonNilLicenses(licenses []*spdx.OtherLicense) []*spdx.OtherLicense {
+	result := []*spdx.OtherLicense{}
+	for _, l := range licenses {
+		if l != nil {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// allFiles returns the files of the packages of doc, then its unpackaged
+// files, each once.
+func allFiles(doc *spdx.Document) []*spdx.File {
+	files := []*spdx.File{}
+	seen := map[*spdx.File]bool{}
+	add := func(f *spdx.File) {
+		if f != nil && !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	for _, p := range doc.Packages {
+		if p != nil {
+			for _, f := range p.Files {
+				add(f)
+			}
+		}
+	}
+	for _, f := range doc.Files {
+		add(f)
+	}
+	return files
+}
+
+// allSnippets returns the snippets of the files of doc, in ID order, then
+// its own snippets.
+func allSnippets(doc *spdx.Document) []*spdx.Snippet {
+	snippets := []*spdx.Snippet{}
+	for _, f := range allFiles(doc) {
+		ids := []string{}
+		for id, s := range f.Snippets {
+			if s != nil {
+				ids = append(ids, string(id))
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			snippets = append(snippets, f.Snippets[common.ElementID(id)])
+		}
+	}
+	for i := range doc.Snippets {
+		snippets = append(snippets, &doc.Snippets[i])
+	}
+	return snippets
+}
+
+func externalDocumentRefs(doc *spdx.Document) []*spdx.ExternalDocumentRef {
+	refs := []*spdx.ExternalDocumentRef{}
+	for i := range doc.ExternalDocumentReferences {
+		refs = append(refs, &doc.ExternalDocumentReferences[i])
+	}
+	return refs
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func purlRef(purl string) []*spdx.PackageExternalReference {
+	return []*spdx.PackageExternalReference{{Category: common.CategoryPackageManager, RefType: common.TypePackageManagerPURL, Locator: purl}}
+}
+
+func rln(a, relType, b string) *spdx.Relationship {
+	return &spdx.Relationship{RefA: common.MakeDocElementID("", a), RefB: common.MakeDocElementID("", b), Relationship: relType}
+}
+
+// makeReleases returns the documents of two releases of an app: lib is
+// upgraded and renamed, zlib is removed, json is added, and main.go is
+// moved.
+func makeReleases() (*spdx.Document, *spdx.Document) {
+	first := &spdx.Document{
+		SPDXVersion:    spdx.Version,
+		SPDXIdentifier: "DOCUMENT",
+		DocumentName:   "app-1.0",
+		CreationInfo:   &spdx.CreationInfo{Created: "2024-01-01T00:00:00Z", Creators: []common.Creator{{Creator: "builder", CreatorType: "Tool"}}},
+		Packages: []*spdx.Package{
+			{PackageName: "app", PackageVersion: "1.0", PackageSPDXIdentifier: "Package-app", Files: []*spdx.File{
+				{FileName: "./main.go", FileSPDXIdentifier: "File0", Checksums: []common.Checksum{{Algorithm: common.SHA1, Value: "aaaa"}}, LicenseConcluded: "MIT"},
+				{FileName: "./util.go", FileSPDXIdentifier: "File1", Checksums: []common.Checksum{{Algorithm: common.SHA1, Value: "bbbb"}}},
+			}},
+			{PackageName: "lib", PackageVersion: "1.0", PackageSPDXIdentifier: "Package-lib", PackageExternalReferences: purlRef("pkg:golang/example.com/lib@v1.0")},
+			{PackageName: "zlib", PackageVersion: "1.2", PackageSPDXIdentifier: "Package-zlib"},
+		},
+		OtherLicenses: []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-x", ExtractedText: "old"}},
+		Relationships: []*spdx.Relationship{
+			rln("DOCUMENT", common.TypeRelationshipDescribe, "Package-app"),
+			rln("Package-app", common.TypeRelationshipDependsOn, "Package-lib"),
+			rln("Package-app", common.TypeRelationshipDependsOn, "Package-zlib"),
+		},
+	}
+	second := &spdx.Document{
+		SPDXVersion:    spdx.Version,
+		SPDXIdentifier: "DOCUMENT",
+		DocumentName:   "app-1.1",
+		CreationInfo:   &spdx.CreationInfo{Created: "2024-01-01T00:00:00Z", Creators: []common.Creator{{Creator: "builder", CreatorType: "Tool"}}},
+		Packages: []*spdx.Package{
+			{PackageName: "app", PackageVersion: "1.0", PackageSPDXIdentifier: "Package-app", Files: []*spdx.File{
+				{FileName: "./util.go", FileSPDXIdentifier: "File0", Checksums: []common.Checksum{{Algorithm: common.SHA1, Value: "bbbb"}}},
+				{FileName: "./cmd/main.go", FileSPDXIdentifier: "File1", Checksums: []common.Checksum{{Algorithm: common.SHA1, Value: "AAAA"}}, LicenseConcluded: "MIT"},
+			}},
+			{PackageName: "golib", PackageVersion: "1.1", PackageSPDXIdentifier: "Package-golib", PackageExternalReferences: purlRef("pkg:golang/example.com/lib@v1.1")},
+			{PackageName: "json", PackageVersion: "2.0", PackageSPDXIdentifier: "Package-json"},
+		},
+		OtherLicenses: []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-x", ExtractedText: "new"}},
+		Relationships: []*spdx.Relationship{
+			rln("DOCUMENT", common.TypeRelationshipDescribe, "Package-app"),
+			rln("Package-golib", common.TypeRelationshipDependencyOf, "Package-app"),
+			rln("Package-app", common.TypeRelationshipDependsOn, "Package-json"),
+		},
+	}
+	return first, second
+}
+
+func elementStrings(changes []ElementChange) []string {
+	s := []string{}
+	for _, c := range changes {
+		s = append(s, fmt.Sprintf("%s %s %s %s %v", c.Kind, c.OldID, c.ID, c.MatchedBy, c.Fields))
+	}
+	return s
+}
+
+// ===== Diff tests =====
+func TestCompareIdenticalDocuments(t *testing.T) {
+	first, _ := makeReleases()
+	d := Compare(first, first)
+	if !d.IsEmpty() {
+		t.Errorf("expected no differences, got %+v", d)
+	}
+}
+
+func TestCompareMatchesPackagesByIdentity(t *testing.T) {
+	d := Compare(makeReleases())
+
+	want := []string{
+		"changed SPDXRef-Package-lib SPDXRef-Package-golib purl [{name lib golib} {versionInfo 1.0 1.1} {externalRefs PACKAGE-MANAGER purl pkg:golang/example.com/lib@v1.0 } {externalRefs  PACKAGE-MANAGER purl pkg:golang/example.com/lib@v1.1}]",
+		"added  SPDXRef-Package-json  []",
+		"removed  SPDXRef-Package-zlib  []",
+	}
+	if got := elementStrings(d.Packages); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if want := "[{name app-1.0 app-1.1}]"; fmt.Sprint(d.Document) != want {
+		t.Errorf("expected %v, got %v", want, d.Document)
+	}
+}
+
+func TestCompareMatchesMovedFilesByChecksum(t *testing.T) {
+	d := Compare(makeReleases())
+	want := []string{
+		"changed SPDXRef-File1 SPDXRef-File0 path []",
+		"changed SPDXRef-File0 SPDXRef-File1 checksum [{fileName ./main.go ./cmd/main.go}]",
+	}
+	if got := elementStrings(d.Files); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCompareRelationshipsOfMatchedElements(t *testing.T) {
+	d := Compare(makeReleases())
+	got := []string{}
+	for _, c := range d.Relationships {
+		got = append(got, fmt.Sprintf("%s %s", c.Kind, c.Relationship))
+	}
+	want := []string{
+		"added SPDXRef-Package-app DEPENDS_ON SPDXRef-Package-json",
+		"removed SPDXRef-Package-app DEPENDS_ON SPDXRef-Package-zlib",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCompareOtherLicensesAndExternalDocumentRefs(t *testing.T) {
+	first, second := makeReleases()
+	first.ExternalDocumentReferences = []spdx.ExternalDocumentRef{{DocumentRefID: "a", URI: "https://example.com/a"}}
+	second.ExternalDocumentReferences = []spdx.ExternalDocumentRef{{DocumentRefID: "b", URI: "https://example.com/a"}}
+	d := Compare(first, second)
+	if want := "[changed  LicenseRef-x SPDXID [{extractedText old new}]]"; fmt.Sprint(elementStrings(d.OtherLicenses)) != want {
+		t.Errorf("expected %v, got %v", want, elementStrings(d.OtherLicenses))
+	}
+	if want := "[changed DocumentRef-a DocumentRef-b URI []]"; fmt.Sprint(elementStrings(d.ExternalDocumentRefs)) != want {
+		t.Errorf("expected %v, got %v", want, elementStrings(d.ExternalDocumentRefs))
+	}
+}
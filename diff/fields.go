@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// compareField adds a FieldChange to changes if the old and new values of
+// a field differ.
+func compareField(changes *[]FieldChange, field string, old string, new string) {
+	if old != new {
+		*changes = append(*changes, FieldChange{Field: field, Old: old, New: new})
+	}
+}
+
+// compareSet adds a FieldChange to changes for each value of a field with
+// several values that was removed, then for each that was added. The order
+// of the values is ignored.
+func compareSet(changes *[]FieldChange, field string, old []string, new []string) {
+	inOld, inNew := map[string]bool{}, map[string]bool{}
+	for _, v := range old {
+		inOld[v] = true
+	}
+	for _, v := range new {
+		inNew[v] = true
+	}
+	for _, v := range old {
+		if !inNew[v] {
+			*changes = append(*changes, FieldChange{Field: field, Old: v})
+			inNew[v] = true
+		}
+	}
+	for _, v := range new {
+		if !inOld[v] {
+			*changes = append(*changes, FieldChange{Field: field, New: v})
+			inOld[v] = true
+		}
+	}
+}
+
+func checksumStrings(checksums []common.Checksum) []string {
+	s := []string{}
+	for _, c := range checksums {
+		s = append(s, fmt.Sprintf("%s: %s", c.Algorithm, strings.ToLower(c.Value)))
+	}
+	return s
+}
+
+func compareDocumentFields(first *spdx.Document, second *spdx.Document) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "spdxVersion", first.SPDXVersion, second.SPDXVersion)
+	compareField(&changes, "dataLicense", first.DataLicense, second.DataLicense)
+	compareField(&changes, "SPDXID", string(first.SPDXIdentifier), string(second.SPDXIdentifier))
+	compareField(&changes, "name", first.DocumentName, second.DocumentName)
+	compareField(&changes, "documentNamespace", first.DocumentNamespace, second.DocumentNamespace)
+	compareField(&changes, "comment", first.DocumentComment, second.DocumentComment)
+
+	oldInfo, newInfo := first.CreationInfo, second.CreationInfo
+	if oldInfo == nil {
+		oldInfo = &spdx.CreationInfo{}
+	}
+	if newInfo == nil {
+		newInfo = &spdx.CreationInfo{}
+	}
+	compareField(&changes, "licenseListVersion", oldInfo.LicenseListVersion, newInfo.LicenseListVersion)
+	creators := func(ci *spdx.CreationInfo) []string {
+		s := []string{}
+		for _, c := range ci.Creators {
+			s = append(s, c.CreatorType+": "+c.Creator)
+		}
+		return s
+	}
+	compareSet(&changes, "creators", creators(oldInfo), creators(newInfo))
+	compareField(&changes, "created", oldInfo.Created, newInfo.Created)
+	compareField(&changes, "creatorComment", oldInfo.CreatorComment, newInfo.CreatorComment)
+	return changes
+}
+
+func comparePackages(o *spdx.Package, n *spdx.Package) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "name", o.PackageName, n.PackageName)
+	compareField(&changes, "versionInfo", o.PackageVersion, n.PackageVersion)
+	compareField(&changes, "packageFileName", o.PackageFileName, n.PackageFileName)
+	supplier := func(s *common.Supplier) string {
+		switch {
+		case s == nil:
+			return ""
+		case s.SupplierType == "":
+			return s.Supplier
+		}
+		return s.SupplierType + ": " + s.Supplier
+	}
+	compareField(&changes, "supplier", supplier(o.PackageSupplier), supplier(n.PackageSupplier))
+	originator := func(o *common.Originator) string {
+		switch {
+		case o == nil:
+			return ""
+		case o.OriginatorType == "":
+			return o.Originator
+		}
+		return o.OriginatorType + ": " + o.Originator
+	}
+	compareField(&changes, "originator", originator(o.PackageOriginator), originator(n.PackageOriginator))
+	compareField(&changes, "downloadLocation", o.PackageDownloadLocation, n.PackageDownloadLocation)
+	compareField(&changes, "filesAnalyzed", fmt.Sprint(o.FilesAnalyzed), fmt.Sprint(n.FilesAnalyzed))
+	verificationCode := func(c *common.PackageVerificationCode) string {
+		if c == nil {
+			return ""
+		}
+		return c.Value
+	}
+	compareField(&changes, "packageVerificationCode", verificationCode(o.PackageVerificationCode), verificationCode(n.PackageVerificationCode))
+	compareSet(&changes, "checksums", checksumStrings(o.PackageChecksums), checksumStrings(n.PackageChecksums))
+	compareField(&changes, "homepage", o.PackageHomePage, n.PackageHomePage)
+	compareField(&changes, "sourceInfo", o.PackageSourceInfo, n.PackageSourceInfo)
+	compareField(&changes, "licenseConcluded", o.PackageLicenseConcluded, n.PackageLicenseConcluded)
+	compareSet(&changes, "licenseInfoFromFiles", o.PackageLicenseInfoFromFiles, n.PackageLicenseInfoFromFiles)
+	compareField(&changes, "licenseDeclared", o.PackageLicenseDeclared, n.PackageLicenseDeclared)
+	compareField(&changes, "licenseComments", o.PackageLicenseComments, n.PackageLicenseComments)
+	compareField(&changes, "copyrightText", o.PackageCopyrightText, n.PackageCopyrightText)
+	compareField(&changes, "summary", o.PackageSummary, n.PackageSummary)
+	compareField(&changes, "description", o.PackageDescription, n.PackageDescription)
+	compareField(&changes, "comment", o.PackageComment, n.PackageComment)
+	externalRefs := func(refs []*spdx.PackageExternalReference) []string {
+		s := []string{}
+		for _, ref := range refs {
+			if ref != nil {
+				s = append(s, ref.Category+" "+ref.RefType+" "+ref.Locator)
+			}
+		}
+		return s
+	}
+	compareSet(&changes, "externalRefs", externalRefs(o.PackageExternalReferences), externalRefs(n.PackageExternalReferences))
+	compareSet(&changes, "attributionTexts", o.PackageAttributionTexts, n.PackageAttributionTexts)
+	compareField(&changes, "primaryPackagePurpose", o.PrimaryPackagePurpose, n.PrimaryPackagePurpose)
+	compareField(&changes, "releaseDate", o.ReleaseDate, n.ReleaseDate)
+	compareField(&changes, "builtDate", o.BuiltDate, n.BuiltDate)
+	compareField(&changes, "validUntilDate", o.ValidUntilDate, n.ValidUntilDate)
+	return changes
+}
+
+func compareFiles(o *spdx.File, n *spdx.File) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "fileName", o.FileName, n.FileName)
+	compareSet(&changes, "fileTypes", o.FileTypes, n.FileTypes)
+	compareSet(&changes, "checksums", checksumStrings(o.Checksums), checksumStrings(n.Checksums))
+	compareField(&changes, "licenseConcluded", o.LicenseConcluded, n.LicenseConcluded)
+	compareSet(&changes, "licenseInfoInFiles", o.LicenseInfoInFiles, n.LicenseInfoInFiles)
+	compareField(&changes, "licenseComments", o.LicenseComments, n.LicenseComments)
+	compareField(&changes, "copyrightText", o.FileCopyrightText, n.FileCopyrightText)
+	compareField(&changes, "comment", o.FileComment, n.FileComment)
+	compareField(&changes, "noticeText", o.FileNotice, n.FileNotice)
+	compareSet(&changes, "fileContributors", o.FileContributors, n.FileContributors)
+	compareSet(&changes, "attributionTexts", o.FileAttributionTexts, n.FileAttributionTexts)
+	return changes
+}
+
+func compareSnippets(o *spdx.Snippet, n *spdx.Snippet) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "name", o.SnippetName, n.SnippetName)
+	ranges := func(s *spdx.Snippet) string {
+		r := []string{}
+		for _, sr := range s.Ranges {
+			r = append(r, fmt.Sprintf("%d:%d-%d:%d", sr.StartPointer.Offset, sr.StartPointer.LineNumber, sr.EndPointer.Offset, sr.EndPointer.LineNumber))
+		}
+		return strings.Join(r, ", ")
+	}
+	compareField(&changes, "ranges", ranges(o), ranges(n))
+	compareField(&changes, "licenseConcluded", o.SnippetLicenseConcluded, n.SnippetLicenseConcluded)
+	compareSet(&changes, "licenseInfoInSnippets", o.LicenseInfoInSnippet, n.LicenseInfoInSnippet)
+	compareField(&changes, "licenseComments", o.SnippetLicenseComments, n.SnippetLicenseComments)
+	compareField(&changes, "copyrightText", o.SnippetCopyrightText, n.SnippetCopyrightText)
+	compareField(&changes, "comment", o.SnippetComment, n.SnippetComment)
+	return changes
+}
+
+func compareOtherLicenses(o *spdx.OtherLicense, n *spdx.OtherLicense) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "name", o.LicenseName, n.LicenseName)
+	compareField(&changes, "extractedText", o.ExtractedText, n.ExtractedText)
+	compareSet(&changes, "seeAlsos", o.LicenseCrossReferences, n.LicenseCrossReferences)
+	compareField(&changes, "comment", o.LicenseComment, n.LicenseComment)
+	return changes
+}
+
+func compareExternalDocumentRefs(o *spdx.ExternalDocumentRef, n *spdx.ExternalDocumentRef) []FieldChange {
+	changes := []FieldChange{}
+	compareField(&changes, "spdxDocument", o.URI, n.URI)
+	compareSet(&changes, "checksum", checksumStrings([]common.Checksum{o.Checksum}), checksumStrings([]common.Checksum{n.Checksum}))
+	return changes
+}
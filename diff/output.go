@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes d to w as indented JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(d)
+}
+
+// section is a list of element changes with its heading.
+type section struct {
+	title   string
+	changes []ElementChange
+}
+
+func (d *Diff) sections() []section {
+	return []section{
+		{"Packages", d.Packages},
+		{"Files", d.Files},
+		{"Snippets", d.Snippets},
+		{"Other licenses", d.OtherLicenses},
+		{"External document references", d.ExternalDocumentRefs},
+	}
+}
+
+// summarize counts the changes of each kind, such as "1 added, 2 changed".
+func summarize(kinds []ChangeKind) string {
+	counts := map[ChangeKind]int{}
+	for _, k := range kinds {
+		counts[k]++
+	}
+	parts := []string{}
+	for _, k := range []ChangeKind{Added, Removed, Changed} {
+		if counts[k] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func elementKinds(changes []ElementChange) []ChangeKind {
+	kinds := []ChangeKind{}
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+	}
+	return kinds
+}
+
+func relationshipKinds(changes []RelationshipChange) []ChangeKind {
+	kinds := []ChangeKind{}
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+	}
+	return kinds
+}
+
+var textMarks = map[ChangeKind]string{Added: "+", Removed: "-", Changed: "~"}
+
+// formatField describes a FieldChange, as "field: old -> new" or, for a
+// value added to or removed from a field, "field: +new" or "field: -old".
+func formatField(f FieldChange, quote func(string) string) string {
+	switch {
+	case f.Old == "":
+		return fmt.Sprintf("%s: +%s", f.Field, quote(f.New))
+	case f.New == "":
+		return fmt.Sprintf("%s: -%s", f.Field, quote(f.Old))
+	}
+	return fmt.Sprintf("%s: %s -> %s", f.Field, quote(f.Old), quote(f.New))
+}
+
+func describeElement(c ElementChange, quote func(string) string) string {
+	s := quote(c.ID)
+	if c.OldID != "" {
+		s = quote(c.OldID) + " -> " + s
+	}
+	if c.Name != "" {
+		s += " (" + c.Name + ")"
+	}
+	if c.MatchedBy != "" && c.OldID != "" {
+		s += " matched by " + c.MatchedBy
+	}
+	return s
+}
+
+// WriteText writes a readable summary of d to w, with a line for each
+// change, marked "+" if added, "-" if removed or "~" if changed, followed
+// by indented lines for the fields that changed.
+func (d *Diff) WriteText(w io.Writer) error {
+	if d.IsEmpty() {
+		_, err := fmt.Fprintln(w, "No differences")
+		return err
+	}
+	b := &strings.Builder{}
+	plain := func(s string) string { return s }
+	quoted := func(s string) string { return fmt.Sprintf("%q", s) }
+	if len(d.Document) > 0 {
+		fmt.Fprintf(b, "Document: %d changed\n", len(d.Document))
+		for _, f := range d.Document {
+			fmt.Fprintf(b, "  ~ %s\n", formatField(f, quoted))
+		}
+	}
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "%s: %s\n", s.title, summarize(elementKinds(s.changes)))
+		for _, c := range s.changes {
+			fmt.Fprintf(b, "  %s %s\n", textMarks[c.Kind], describeElement(c, plain))
+			for _, f := range c.Fields {
+				fmt.Fprintf(b, "      %s\n", formatField(f, quoted))
+			}
+		}
+	}
+	if len(d.Relationships) > 0 {
+		fmt.Fprintf(b, "Relationships: %s\n", summarize(relationshipKinds(d.Relationships)))
+		for _, c := range d.Relationships {
+			fmt.Fprintf(b, "  %s %s\n", textMarks[c.Kind], c.Relationship)
+			for _, f := range c.Fields {
+				fmt.Fprintf(b, "      %s\n", formatField(f, quoted))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown writes a summary of d to w in Markdown, with a section
+// for each kind of element that changed.
+func (d *Diff) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("# SPDX document changes\n")
+	if d.IsEmpty() {
+		b.WriteString("\nNo differences.\n")
+	}
+	code := func(s string) string {
+		if s == "" {
+			return "(none)"
+		}
+		return "`" + strings.ReplaceAll(s, "`", "'") + "`"
+	}
+	titles := map[ChangeKind]string{Added: "Added", Removed: "Removed", Changed: "Changed"}
+	if len(d.Document) > 0 {
+		b.WriteString("\n## Document\n\n| Field | Old | New |\n| --- | --- | --- |\n")
+		for _, f := range d.Document {
+			fmt.Fprintf(b, "| %s | %s | %s |\n", f.Field, markdownCell(f.Old), markdownCell(f.New))
+		}
+	}
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n## %s (%s)\n\n", s.title, summarize(elementKinds(s.changes)))
+		for _, c := range s.changes {
+			fmt.Fprintf(b, "- **%s** %s\n", titles[c.Kind], describeElement(c, code))
+			for _, f := range c.Fields {
+				fmt.Fprintf(b, "  - %s\n", formatField(f, code))
+			}
+		}
+	}
+	if len(d.Relationships) > 0 {
+		fmt.Fprintf(b, "\n## Relationships (%s)\n\n", summarize(relationshipKinds(d.Relationships)))
+		for _, c := range d.Relationships {
+			fmt.Fprintf(b, "- **%s** %s\n", titles[c.Kind], code(c.Relationship))
+			for _, f := range c.Fields {
+				fmt.Fprintf(b, "  - %s\n", formatField(f, code))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes s for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
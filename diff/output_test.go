@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Diff output tests =====
+func TestWriteText(t *testing.T) {
+	first, second := makeReleases()
+	second.Packages = second.Packages[:1]
+	second.Packages[0].PackageLicenseDeclared = "MIT"
+	second.Relationships = second.Relationships[:1]
+	first.Packages = first.Packages[:1]
+	first.Relationships = append(first.Relationships[:1], rln("Package-app", common.TypeRelationshipContains, "File1"))
+	second.OtherLicenses = first.OtherLicenses
+	second.DocumentName = first.DocumentName
+
+	buf := &bytes.Buffer{}
+	if err := Compare(first, second).WriteText(buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := `Packages: 1 changed
+  ~ SPDXRef-Package-app (app 1.0)
+      licenseDeclared: +"MIT"
+Files: 2 changed
+  ~ SPDXRef-File1 -> SPDXRef-File0 (./util.go) matched by path
+  ~ SPDXRef-File0 -> SPDXRef-File1 (./cmd/main.go) matched by checksum
+      fileName: "./main.go" -> "./cmd/main.go"
+Relationships: 1 removed
+  - SPDXRef-Package-app CONTAINS SPDXRef-File1
+`
+	if buf.String() != want {
+		t.Errorf("expected %v, got %v", want, buf.String())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Compare(makeReleases()).WriteMarkdown(buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, want := range []string{
+		"# SPDX document changes\n",
+		"| name | app-1.0 | app-1.1 |\n",
+		"## Packages (1 added, 1 removed, 1 changed)\n",
+		"- **Changed** `SPDXRef-Package-lib` -> `SPDXRef-Package-golib` (golib 1.1) matched by purl\n  - name: `lib` -> `golib`\n",
+		"- **Removed** `SPDXRef-Package-app DEPENDS_ON SPDXRef-Package-zlib`\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in %v", want, buf.String())
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Compare(makeReleases()).WriteJSON(buf); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var d Diff
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(d.Packages) != 3 || d.Packages[0].Kind != Changed || d.Packages[0].MatchedBy != "purl" {
+		t.Errorf("unexpected packages %+v", d.Packages)
+	}
+}
@@ -115,15 +115,15 @@ func main() {
 			continue
 		}
 
-		// now, run a diff between the two
-		pairs, err := licensediff.MakePairs(p1, p2)
+		// now, run a diff between the two, matching files that moved
+		matches, err := licensediff.MatchFiles(p1, p2, nil)
 		if err != nil {
-			fmt.Printf("  Error generating licensediff pairs: %v\n", err)
+			fmt.Printf("  Error matching licensediff files: %v\n", err)
 			continue
 		}
 
-		// take the pairs and turn them into a more structured results set
-		resultSet, err := licensediff.MakeResults(pairs)
+		// take the matches and turn them into a more structured results set
+		resultSet, err := licensediff.MakeMatchResults(matches)
 		if err != nil {
 			fmt.Printf("  Error generating licensediff results set: %v\n", err)
 			continue
@@ -133,7 +133,19 @@ func main() {
 		fmt.Printf("  Files in first only: %d\n", len(resultSet.InFirstOnly))
 		fmt.Printf("  Files in second only: %d\n", len(resultSet.InSecondOnly))
 		fmt.Printf("  Files in both with different licenses: %d\n", len(resultSet.InBothChanged))
+		fmt.Printf("  Files in both with other licensing changes: %d\n", len(resultSet.Changed)-len(resultSet.InBothChanged))
 		fmt.Printf("  Files in both with same licenses: %d\n", len(resultSet.InBothSame))
+		fmt.Printf("  Files moved with same licenses: %d\n", len(resultSet.Moved))
+		fmt.Printf("  Files moved with different licenses: %d\n", len(resultSet.MovedChanged))
+		similar := 0
+		for _, moved := range []map[string]licensediff.FileMatch{resultSet.Moved, resultSet.MovedChanged} {
+			for _, m := range moved {
+				if m.MatchedBy == licensediff.MatchedBySimilarity {
+					similar++
+				}
+			}
+		}
+		fmt.Printf("  Files moved, matched only by similarity: %d\n", similar)
 	}
 
 	// now report if there are any package IDs in the second set that aren't
@@ -0,0 +1,212 @@
+// Package graph builds a directed graph of the elements of an SPDX Document
+// from its relationships, for queries such as which packages a package
+// transitively depends on.
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+package graph
+
+import (
+	"sort"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// NormalizeType returns the type in whose direction a relationship of type
+// relType is recorded, and whether the relationship's elements are reversed
+// to do so, as by common.RelationshipType.Normalize. For example, "A
+// CONTAINED_BY B" is recorded as an edge for "B CONTAINS A". DEPENDENCY_OF
+// and the scoped dependency types, such as BUILD_DEPENDENCY_OF, are all
+// recorded as DEPENDS_ON; the relationship's own type remains available
+// from the Edge.
+func NormalizeType(relType string) (string, bool) {
+	t, reversed := common.RelationshipType(relType).Normalize()
+	return string(t), reversed
+}
+
+// Edge is a directed edge of a Graph.
+type Edge struct {
+	From common.DocElementID
+	To   common.DocElementID
+	// Type is the normalized relationship type, as by NormalizeType.
+	Type string
+	// Relationship is the relationship the edge was made from, or nil if
+	// it records that a package contains one of its Files.
+	Relationship *spdx.Relationship
+}
+
+// edgeKey identifies an edge regardless of the relationship it was made
+// from.
+type edgeKey struct {
+	from, to common.DocElementID
+	relType  string
+}
+
+// Graph is a directed graph of the elements of a Document. Its nodes are
+// the document itself, its packages, files and snippets, and any other
+// element, such as one in an external document, or NONE or NOASSERTION,
+// that a relationship refers to.
+type Graph struct {
+	nodes []common.DocElementID
+	seen  map[common.DocElementID]bool
+	edges []Edge
+	out   map[common.DocElementID][]int
+	in    map[common.DocElementID][]int
+	keys  map[edgeKey]bool
+}
+
+// New builds the Graph of doc. Each relationship becomes an edge in the
+// direction of its normalized type, and each package has a CONTAINS edge
+// to each of its Files, unless a relationship already records it.
+// Identical edges are only recorded once.
+func New(doc *spdx.Document) *Graph {
+	g := &Graph{
+		seen: map[common.DocElementID]bool{},
+		out:  map[common.DocElementID][]int{},
+		in:   map[common.DocElementID][]int{},
+		keys: map[edgeKey]bool{},
+	}
+	if doc == nil {
+		return g
+	}
+
+	g.addNode(common.MakeDocElementID("", string(doc.SPDXIdentifier)))
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		g.addNode(common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)))
+		for _, f := range pkg.Files {
+			if f != nil {
+				g.addFileNodes(f)
+			}
+		}
+	}
+	for _, f := range doc.Files {
+		if f != nil {
+			g.addFileNodes(f)
+		}
+	}
+
+	for _, rln := range doc.Relationships {
+		if rln == nil {
+			continue
+		}
+		relType, reversed := NormalizeType(rln.Relationship)
+		from, to := rln.RefA, rln.RefB
+		if reversed {
+			from, to = to, from
+		}
+		g.addEdge(Edge{From: from, To: to, Type: relType, Relationship: rln})
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		pkgID := common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier))
+		for _, f := range pkg.Files {
+			if f == nil {
+				continue
+			}
+			g.addEdge(Edge{
+				From: pkgID,
+				To:   common.MakeDocElementID("", string(f.FileSPDXIdentifier)),
+				Type: common.TypeRelationshipContains,
+			})
+		}
+	}
+	return g
+}
+
+func (g *Graph) addFileNodes(f *spdx.File) {
+	g.addNode(common.MakeDocElementID("", string(f.FileSPDXIdentifier)))
+	// Snippets is a map, so add them in ID order
+	ids := []string{}
+	for _, s := range f.Snippets {
+		if s != nil {
+			ids = append(ids, string(s.SnippetSPDXIdentifier))
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		g.addNode(common.MakeDocElementID("", id))
+	}
+}
+
+func (g *Graph) addNode(id common.DocElementID) {
+	if !g.seen[id] {
+		g.seen[id] = true
+		g.nodes = append(g.nodes, id)
+	}
+}
+
+func (g *Graph) addEdge(e Edge) {
+	key := edgeKey{e.From, e.To, e.Type}
+	if g.keys[key] {
+		return
+	}
+	g.keys[key] = true
+	g.addNode(e.From)
+	g.addNode(e.To)
+	g.edges = append(g.edges, e)
+	g.out[e.From] = append(g.out[e.From], len(g.edges)-1)
+	g.in[e.To] = append(g.in[e.To], len(g.edges)-1)
+}
+
+// Nodes returns the nodes of g: the document, then its packages with their
+// files, then its unpackaged files, then any other elements in the order
+// relationships refer to them.
+func (g *Graph) Nodes() []common.DocElementID {
+	return append([]common.DocElementID{}, g.nodes...)
+}
+
+// HasNode reports whether id is a node of g.
+func (g *Graph) HasNode(id common.DocElementID) bool {
+	return g.seen[id]
+}
+
+// Edges returns the edges of g whose type is one of types, or all of its
+// edges if no types are given.
+func (g *Graph) Edges(types ...string) []Edge {
+	edges := []Edge{}
+	for _, e := range g.edges {
+		if matchesType(e, types) {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Out returns the edges from id whose type is one of types, or all edges
+// from id if no types are given.
+func (g *Graph) Out(id common.DocElementID, types ...string) []Edge {
+	return g.selectEdges(g.out[id], types)
+}
+
+// In returns the edges to id whose type is one of types, or all edges to
+// id if no types are given.
+func (g *Graph) In(id common.DocElementID, types ...string) []Edge {
+	return g.selectEdges(g.in[id], types)
+}
+
+func (g *Graph) selectEdges(indexes []int, types []string) []Edge {
+	edges := []Edge{}
+	for _, i := range indexes {
+		if matchesType(g.edges[i], types) {
+			edges = append(edges, g.edges[i])
+		}
+	}
+	return edges
+}
+
+func matchesType(e Edge, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if e.Type == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func id(s string) common.DocElementID {
+	return common.MakeDocElementID("", s)
+}
+
+func rln(a, relType, b string) *spdx.Relationship {
+	return &spdx.Relationship{RefA: id(a), RefB: id(b), Relationship: relType}
+}
+
+// makeTestDocument returns a document in which the document describes
+// app, which depends on lib (which contains lib.c) and, for building, on
+// tool; lib depends on zlib.
+func makeTestDocument() *spdx.Document {
+	return &spdx.Document{
+		SPDXIdentifier: "DOCUMENT",
+		Packages: []*spdx.Package{
+			{PackageSPDXIdentifier: "app"},
+			{PackageSPDXIdentifier: "lib", Files: []*spdx.File{{FileSPDXIdentifier: "lib.c"}}},
+			{PackageSPDXIdentifier: "zlib"},
+			{PackageSPDXIdentifier: "tool"},
+		},
+		Relationships: []*spdx.Relationship{
+			rln("DOCUMENT", common.TypeRelationshipDescribe, "app"),
+			rln("app", common.TypeRelationshipDependsOn, "lib"),
+			rln("zlib", common.TypeRelationshipDependencyOf, "lib"),
+			rln("tool", common.TypeRelationshipBuildDependencyOf, "app"),
+			rln("lib.c", common.TypeRelationshipContainedBy, "lib"),
+		},
+	}
+}
+
+func edgeStrings(edges []Edge) string {
+	s := []string{}
+	for _, e := range edges {
+		s = append(s, fmt.Sprintf("%s %s %s", e.From.ElementRefID, e.Type, e.To.ElementRefID))
+	}
+	return fmt.Sprint(s)
+}
+
+func idStrings(ids []common.DocElementID) string {
+	s := []string{}
+	for _, i := range ids {
+		s = append(s, common.RenderDocElementID(i))
+	}
+	return fmt.Sprint(s)
+}
+
+// ===== Graph construction tests =====
+func TestNormalizeType(t *testing.T) {
+	for relType, want := range map[string]string{
+		common.TypeRelationshipDependencyOf:     common.TypeRelationshipDependsOn,
+		common.TypeRelationshipDescribeBy:       common.TypeRelationshipDescribe,
+		common.TypeRelationshipContainedBy:      common.TypeRelationshipContains,
+		common.TypeRelationshipGeneratedFrom:    common.TypeRelationshipGenerates,
+		common.TypeRelationshipTestDependencyOf: common.TypeRelationshipDependsOn,
+		common.TypeRelationshipPrerequisiteFor:  common.TypeRelationshipHasPrerequisite,
+		common.TypeRelationshipDescendantOf:     common.TypeRelationshipAncestorOf,
+	} {
+		if got, reversed := NormalizeType(relType); got != want || !reversed {
+			t.Errorf("%s: expected %v reversed, got %v %v", relType, want, got, reversed)
+		}
+	}
+	if got, reversed := NormalizeType(common.TypeRelationshipDependsOn); got != common.TypeRelationshipDependsOn || reversed {
+		t.Errorf("expected DEPENDS_ON unchanged, got %v %v", got, reversed)
+	}
+}
+
+func TestNewNormalizesEdges(t *testing.T) {
+	doc := makeTestDocument()
+	g := New(doc)
+
+	want := "[DOCUMENT DESCRIBES app app DEPENDS_ON lib lib DEPENDS_ON zlib app DEPENDS_ON tool lib CONTAINS lib.c]"
+	if got := edgeStrings(g.Edges()); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if e := g.Out(id("app"), common.TypeRelationshipDependsOn)[1]; e.Relationship != doc.Relationships[3] {
+		t.Errorf("expected edge to keep its relationship, got %+v", e.Relationship)
+	}
+	if want := "[SPDXRef-DOCUMENT SPDXRef-app SPDXRef-lib SPDXRef-lib.c SPDXRef-zlib SPDXRef-tool]"; idStrings(g.Nodes()) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(g.Nodes()))
+	}
+}
+
+func TestNewMergesPackageFiles(t *testing.T) {
+	doc := makeTestDocument()
+	doc.Relationships = doc.Relationships[:4]
+	doc.Packages[2].Files = []*spdx.File{{FileSPDXIdentifier: "zlib.c"}}
+	g := New(doc)
+
+	contains := g.Edges(common.TypeRelationshipContains)
+	if want := "[lib CONTAINS lib.c zlib CONTAINS zlib.c]"; edgeStrings(contains) != want {
+		t.Errorf("expected %v, got %v", want, edgeStrings(contains))
+	}
+	if contains[0].Relationship != nil {
+		t.Errorf("expected no relationship for a package file, got %+v", contains[0].Relationship)
+	}
+}
+
+func TestOutAndInFilterByType(t *testing.T) {
+	g := New(makeTestDocument())
+	if want := "[app DEPENDS_ON lib app DEPENDS_ON tool]"; edgeStrings(g.Out(id("app"), common.TypeRelationshipDependsOn)) != want {
+		t.Errorf("expected %v, got %v", want, edgeStrings(g.Out(id("app"), common.TypeRelationshipDependsOn)))
+	}
+	if want := "[DOCUMENT DESCRIBES app]"; edgeStrings(g.In(id("app"))) != want {
+		t.Errorf("expected %v, got %v", want, edgeStrings(g.In(id("app"))))
+	}
+	if got := g.In(id("app"), common.TypeRelationshipDependsOn); len(got) != 0 {
+		t.Errorf("expected no edges, got %v", edgeStrings(got))
+	}
+}
+
+func TestNewKeepsExternalAndSpecialElements(t *testing.T) {
+	doc := makeTestDocument()
+	doc.Relationships = append(doc.Relationships,
+		&spdx.Relationship{RefA: id("zlib"), RefB: common.MakeDocElementID("other", "pkg"), Relationship: common.TypeRelationshipDependsOn},
+		&spdx.Relationship{RefA: id("tool"), RefB: common.MakeDocElementSpecial("NOASSERTION"), Relationship: common.TypeRelationshipDependsOn},
+	)
+	g := New(doc)
+	for _, n := range []common.DocElementID{common.MakeDocElementID("other", "pkg"), common.MakeDocElementSpecial("NOASSERTION")} {
+		if !g.HasNode(n) {
+			t.Errorf("expected node %v", common.RenderDocElementID(n))
+		}
+	}
+}
+
+func TestNewAcceptsNilDocument(t *testing.T) {
+	if g := New(nil); len(g.Nodes()) != 0 || len(g.Edges()) != 0 {
+		t.Errorf("expected empty graph")
+	}
+}
@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// Traversal selects the edges followed by a traversal or path query.
+type Traversal struct {
+	// Types are the normalized relationship types followed; if empty,
+	// edges of every type are followed.
+	Types []string
+	// Filter, if not nil, is called with each edge of a type in Types, and
+	// the edge is only followed if it returns true.
+	Filter func(Edge) bool
+	// Reverse follows edges from their targets to their sources, for
+	// example to find the packages that depend on a package by following
+	// DEPENDS_ON edges.
+	Reverse bool
+	// MaxDepth, if positive, limits a traversal to nodes at most that many
+	// edges from where it started.
+	MaxDepth int
+}
+
+// next returns the edges followed from id, and the node each leads to.
+func (g *Graph) next(id common.DocElementID, t Traversal) ([]Edge, []common.DocElementID) {
+	var edges []Edge
+	if t.Reverse {
+		edges = g.In(id, t.Types...)
+	} else {
+		edges = g.Out(id, t.Types...)
+	}
+	followed := edges[:0]
+	nodes := []common.DocElementID{}
+	for _, e := range edges {
+		if t.Filter != nil && !t.Filter(e) {
+			continue
+		}
+		followed = append(followed, e)
+		if t.Reverse {
+			nodes = append(nodes, e.From)
+		} else {
+			nodes = append(nodes, e.To)
+		}
+	}
+	return followed, nodes
+}
+
+// BFS visits the nodes reachable from start breadth first, starting with
+// start itself at depth 0. Each node is visited once, and the traversal
+// stops if visit returns false.
+func (g *Graph) BFS(start common.DocElementID, t Traversal, visit func(id common.DocElementID, depth int) bool) {
+	type queued struct {
+		id    common.DocElementID
+		depth int
+	}
+	seen := map[common.DocElementID]bool{start: true}
+	queue := []queued{{start, 0}}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		if !visit(q.id, q.depth) {
+			return
+		}
+		if t.MaxDepth > 0 && q.depth >= t.MaxDepth {
+			continue
+		}
+		_, nodes := g.next(q.id, t)
+		for _, n := range nodes {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, queued{n, q.depth + 1})
+			}
+		}
+	}
+}
+
+// DFS visits the nodes reachable from start depth first, in preorder,
+// starting with start itself at depth 0. Each node is visited once, and
+// the traversal stops if visit returns false.
+func (g *Graph) DFS(start common.DocElementID, t Traversal, visit func(id common.DocElementID, depth int) bool) {
+	seen := map[common.DocElementID]bool{}
+	var walk func(id common.DocElementID, depth int) bool
+	walk = func(id common.DocElementID, depth int) bool {
+		seen[id] = true
+		if !visit(id, depth) {
+			return false
+		}
+		if t.MaxDepth > 0 && depth >= t.MaxDepth {
+			return true
+		}
+		_, nodes := g.next(id, t)
+		for _, n := range nodes {
+			if !seen[n] && !walk(n, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(start, 0)
+}
+
+// Reachable returns the nodes reachable from start by one or more edges,
+// in breadth first order. start is only included if it is on a cycle.
+func (g *Graph) Reachable(start common.DocElementID, t Traversal) []common.DocElementID {
+	reached := []common.DocElementID{}
+	seen := map[common.DocElementID]bool{}
+	queue := []common.DocElementID{start}
+	depths := map[common.DocElementID]int{start: 0}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if t.MaxDepth > 0 && depths[id] >= t.MaxDepth {
+			continue
+		}
+		_, nodes := g.next(id, t)
+		for _, n := range nodes {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			reached = append(reached, n)
+			if _, ok := depths[n]; !ok {
+				depths[n] = depths[id] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reached
+}
+
+// TransitiveClosure returns, for each node of g from which any edge is
+// followed, the nodes reachable from it, as by Reachable.
+func (g *Graph) TransitiveClosure(t Traversal) map[common.DocElementID][]common.DocElementID {
+	closure := map[common.DocElementID][]common.DocElementID{}
+	for _, id := range g.nodes {
+		if reached := g.Reachable(id, t); len(reached) > 0 {
+			closure[id] = reached
+		}
+	}
+	return closure
+}
+
+// ShortestPath returns the edges of a shortest path from one node to
+// another, in order from the first node, or nil if there is none. If
+// t.Reverse is set, each edge of the path is followed from its target to
+// its source. The path from a node to itself is empty.
+func (g *Graph) ShortestPath(from common.DocElementID, to common.DocElementID, t Traversal) []Edge {
+	if from == to {
+		return []Edge{}
+	}
+	via := map[common.DocElementID]Edge{}
+	seen := map[common.DocElementID]bool{from: true}
+	queue := []common.DocElementID{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		edges, nodes := g.next(id, t)
+		for i, n := range nodes {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			via[n] = edges[i]
+			if n == to {
+				path := []Edge{}
+				for n != from {
+					e := via[n]
+					path = append([]Edge{e}, path...)
+					if t.Reverse {
+						n = e.To
+					} else {
+						n = e.From
+					}
+				}
+				return path
+			}
+			queue = append(queue, n)
+		}
+	}
+	return nil
+}
+
+// Cycles returns the cycles of g, as the sets of nodes that can each reach
+// all the others (its strongly connected components), including a node
+// with an edge to itself. The nodes of each cycle, and the cycles, are in
+// the order of Nodes. t.MaxDepth is ignored.
+func (g *Graph) Cycles(t Traversal) [][]common.DocElementID {
+	// Tarjan's strongly connected components algorithm
+	index := map[common.DocElementID]int{}
+	lowlink := map[common.DocElementID]int{}
+	onStack := map[common.DocElementID]bool{}
+	stack := []common.DocElementID{}
+	components := [][]common.DocElementID{}
+	t.MaxDepth = 0
+
+	var connect func(id common.DocElementID)
+	connect = func(id common.DocElementID) {
+		index[id] = len(index)
+		lowlink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		selfLoop := false
+		_, nodes := g.next(id, t)
+		for _, n := range nodes {
+			if n == id {
+				selfLoop = true
+			}
+			if _, ok := index[n]; !ok {
+				connect(n)
+				lowlink[id] = min(lowlink[id], lowlink[n])
+			} else if onStack[n] {
+				lowlink[id] = min(lowlink[id], index[n])
+			}
+		}
+
+		if lowlink[id] != index[id] {
+			return
+		}
+		members := map[common.DocElementID]bool{}
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[n] = false
+			members[n] = true
+			if n == id {
+				break
+			}
+		}
+		if len(members) > 1 || selfLoop {
+			component := []common.DocElementID{}
+			for _, n := range g.nodes {
+				if members[n] {
+					component = append(component, n)
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for _, id := range g.nodes {
+		if _, ok := index[id]; !ok {
+			connect(id)
+		}
+	}
+
+	order := map[common.DocElementID]int{}
+	for i, id := range g.nodes {
+		order[id] = i
+	}
+	sortCycles(components, order)
+	return components
+}
+
+// sortCycles orders cycles by the position of their first node.
+func sortCycles(cycles [][]common.DocElementID, order map[common.DocElementID]int) {
+	for i := 1; i < len(cycles); i++ {
+		for j := i; j > 0 && order[cycles[j][0]] < order[cycles[j-1][0]]; j-- {
+			cycles[j], cycles[j-1] = cycles[j-1], cycles[j]
+		}
+	}
+}
+
+// HasCycle reports whether g has any cycle made of the edges followed.
+func (g *Graph) HasCycle(t Traversal) bool {
+	return len(g.Cycles(t)) > 0
+}
+
+// CycleError is returned by TopologicalOrder if the graph has cycles.
+type CycleError struct {
+	Cycles [][]common.DocElementID
+}
+
+func (e *CycleError) Error() string {
+	cycles := []string{}
+	for _, c := range e.Cycles {
+		ids := []string{}
+		for _, id := range c {
+			ids = append(ids, common.RenderDocElementID(id))
+		}
+		cycles = append(cycles, "["+strings.Join(ids, " ")+"]")
+	}
+	return fmt.Sprintf("relationship graph has %d cycle(s): %s", len(e.Cycles), strings.Join(cycles, ", "))
+}
+
+// TopologicalOrder returns the nodes of g ordered so that each node comes
+// before the nodes its followed edges lead to; for DEPENDS_ON edges, each
+// package comes before its dependencies. Nodes that are not ordered
+// relative to each other keep the order of Nodes. If g has a cycle, a
+// *CycleError is returned. t.MaxDepth is ignored.
+func (g *Graph) TopologicalOrder(t Traversal) ([]common.DocElementID, error) {
+	t.MaxDepth = 0
+	if cycles := g.Cycles(t); len(cycles) > 0 {
+		return nil, &CycleError{Cycles: cycles}
+	}
+
+	// Kahn's algorithm, taking the earliest ready node each time
+	position := map[common.DocElementID]int{}
+	indegree := map[common.DocElementID]int{}
+	for i, id := range g.nodes {
+		position[id] = i
+		_, nodes := g.next(id, t)
+		for _, n := range nodes {
+			indegree[n]++
+		}
+	}
+	ready := &nodeQueue{position: position}
+	for _, id := range g.nodes {
+		if indegree[id] == 0 {
+			heap.Push(ready, id)
+		}
+	}
+	order := []common.DocElementID{}
+	for ready.Len() > 0 {
+		id := heap.Pop(ready).(common.DocElementID)
+		order = append(order, id)
+		_, nodes := g.next(id, t)
+		for _, n := range nodes {
+			if indegree[n]--; indegree[n] == 0 {
+				heap.Push(ready, n)
+			}
+		}
+	}
+	return order, nil
+}
+
+// nodeQueue is a heap of nodes ordered by their position in Nodes.
+type nodeQueue struct {
+	ids      []common.DocElementID
+	position map[common.DocElementID]int
+}
+
+func (q *nodeQueue) Len() int           { return len(q.ids) }
+func (q *nodeQueue) Less(i, j int) bool { return q.position[q.ids[i]] < q.position[q.ids[j]] }
+func (q *nodeQueue) Swap(i, j int)      { q.ids[i], q.ids[j] = q.ids[j], q.ids[i] }
+func (q *nodeQueue) Push(x any)         { q.ids = append(q.ids, x.(common.DocElementID)) }
+func (q *nodeQueue) Pop() any {
+	id := q.ids[len(q.ids)-1]
+	q.ids = q.ids[:len(q.ids)-1]
+	return id
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+var dependsOn = Traversal{Types: []string{common.TypeRelationshipDependsOn}}
+
+// ===== Traversal tests =====
+func TestBFSVisitsByDepth(t *testing.T) {
+	g := New(makeTestDocument())
+	visited := []string{}
+	g.BFS(id("DOCUMENT"), Traversal{}, func(n common.DocElementID, depth int) bool {
+		visited = append(visited, fmt.Sprintf("%s:%d", n.ElementRefID, depth))
+		return true
+	})
+	if want := "[DOCUMENT:0 app:1 lib:2 tool:2 zlib:3 lib.c:3]"; fmt.Sprint(visited) != want {
+		t.Errorf("expected %v, got %v", want, visited)
+	}
+}
+
+func TestDFSVisitsInPreorderAndStops(t *testing.T) {
+	g := New(makeTestDocument())
+	visited := []string{}
+	g.DFS(id("app"), Traversal{}, func(n common.DocElementID, depth int) bool {
+		visited = append(visited, string(n.ElementRefID))
+		return n.ElementRefID != "lib.c"
+	})
+	if want := "[app lib zlib lib.c]"; fmt.Sprint(visited) != want {
+		t.Errorf("expected %v, got %v", want, visited)
+	}
+}
+
+func TestTraversalFilterAndMaxDepth(t *testing.T) {
+	g := New(makeTestDocument())
+	runtimeOnly := Traversal{
+		Types: []string{common.TypeRelationshipDependsOn},
+		Filter: func(e Edge) bool {
+			return e.Relationship.Relationship != common.TypeRelationshipBuildDependencyOf
+		},
+	}
+	if want := "[SPDXRef-lib SPDXRef-zlib]"; idStrings(g.Reachable(id("app"), runtimeOnly)) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(g.Reachable(id("app"), runtimeOnly)))
+	}
+	runtimeOnly.MaxDepth = 1
+	if want := "[SPDXRef-lib]"; idStrings(g.Reachable(id("app"), runtimeOnly)) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(g.Reachable(id("app"), runtimeOnly)))
+	}
+}
+
+func TestReachableInReverse(t *testing.T) {
+	g := New(makeTestDocument())
+	dependents := Traversal{Types: []string{common.TypeRelationshipDependsOn}, Reverse: true}
+	if want := "[SPDXRef-lib SPDXRef-app]"; idStrings(g.Reachable(id("zlib"), dependents)) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(g.Reachable(id("zlib"), dependents)))
+	}
+}
+
+func TestTransitiveClosure(t *testing.T) {
+	g := New(makeTestDocument())
+	closure := g.TransitiveClosure(dependsOn)
+	if len(closure) != 2 {
+		t.Errorf("expected %d, got %d", 2, len(closure))
+	}
+	if want := "[SPDXRef-lib SPDXRef-tool SPDXRef-zlib]"; idStrings(closure[id("app")]) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(closure[id("app")]))
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	g := New(makeTestDocument())
+	if want := "[DOCUMENT DESCRIBES app app DEPENDS_ON lib lib CONTAINS lib.c]"; edgeStrings(g.ShortestPath(id("DOCUMENT"), id("lib.c"), Traversal{})) != want {
+		t.Errorf("expected %v, got %v", want, edgeStrings(g.ShortestPath(id("DOCUMENT"), id("lib.c"), Traversal{})))
+	}
+	reverse := Traversal{Reverse: true}
+	if want := "[lib DEPENDS_ON zlib app DEPENDS_ON lib]"; edgeStrings(g.ShortestPath(id("zlib"), id("app"), reverse)) != want {
+		t.Errorf("expected %v, got %v", want, edgeStrings(g.ShortestPath(id("zlib"), id("app"), reverse)))
+	}
+	if path := g.ShortestPath(id("zlib"), id("app"), Traversal{}); path != nil {
+		t.Errorf("expected no path, got %v", edgeStrings(path))
+	}
+	if path := g.ShortestPath(id("app"), id("app"), Traversal{}); path == nil || len(path) != 0 {
+		t.Errorf("expected empty path, got %v", path)
+	}
+}
+
+func TestCyclesAndTopologicalOrder(t *testing.T) {
+	doc := makeTestDocument()
+	g := New(doc)
+	if g.HasCycle(dependsOn) {
+		t.Errorf("expected no cycle, got %v", g.Cycles(dependsOn))
+	}
+	order, err := g.TopologicalOrder(dependsOn)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "[SPDXRef-DOCUMENT SPDXRef-app SPDXRef-lib SPDXRef-lib.c SPDXRef-zlib SPDXRef-tool]"; idStrings(order) != want {
+		t.Errorf("expected %v, got %v", want, idStrings(order))
+	}
+
+	doc.Relationships = append(doc.Relationships,
+		rln("zlib", common.TypeRelationshipDependsOn, "app"),
+		rln("tool", common.TypeRelationshipDependsOn, "tool"),
+	)
+	g = New(doc)
+	cycles := g.Cycles(dependsOn)
+	if want := "[[SPDXRef-app SPDXRef-lib SPDXRef-zlib] [SPDXRef-tool]]"; fmt.Sprint(cycleStrings(cycles)) != want {
+		t.Errorf("expected %v, got %v", want, cycleStrings(cycles))
+	}
+	_, err = g.TopologicalOrder(dependsOn)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) || len(cycleErr.Cycles) != 2 {
+		t.Errorf("expected CycleError, got %v", err)
+	}
+}
+
+func cycleStrings(cycles [][]common.DocElementID) []string {
+	s := []string{}
+	for _, c := range cycles {
+		s = append(s, idStrings(c))
+	}
+	return s
+}
@@ -39,6 +39,32 @@ type Config struct {
 	// by idsearcher, even if those paths have Files present. It uses the
 	// same format as BuilderPathsIgnored.
 	SearcherPathsIgnored []string
+
+	// BuilderPackageRoots maps subdirectories of dirRoot to the names of
+	// the packages describing them, as for builder.Config.PackageRoots.
+	// Licenses found in each file are summarized in the package containing
+	// it.
+	BuilderPackageRoots map[string]string
+
+	// BuilderDetectPackageRoots detects package roots, as for
+	// builder.Config.DetectPackageRoots.
+	BuilderDetectPackageRoots bool
+
+	// BuilderFileIDs selects how the identifiers of Files are generated,
+	// as for builder.Config.FileIDs.
+	BuilderFileIDs builder.FileIDScheme
+
+	// BuilderUUIDNamespace ends the DocumentNamespace with a random UUID,
+	// as for builder.Config.UUIDNamespace.
+	BuilderUUIDNamespace bool
+
+	// BuilderSymlinks selects how symbolic links are handled, as for
+	// builder.Config.Symlinks. Recorded symbolic links are not searched.
+	BuilderSymlinks utils.SymlinkPolicy
+
+	// BuilderPackage holds metadata for the package described by the
+	// document, as for builder.Config.Package.
+	BuilderPackage builder.PackageMetadata
 }
 
 // BuildIDsDocument creates an SPDX Document and searches for
@@ -51,10 +77,16 @@ type Config struct {
 func BuildIDsDocument(packageName string, dirRoot string, idconfig *Config) (*spdx.Document, error) {
 	// first, build the Document using builder
 	bconfig := &builder.Config{
-		NamespacePrefix: idconfig.NamespacePrefix,
-		CreatorType:     "Tool",
-		Creator:         "github.com/spdx/tools-golang/idsearcher",
-		PathsIgnored:    idconfig.BuilderPathsIgnored,
+		NamespacePrefix:    idconfig.NamespacePrefix,
+		CreatorType:        "Tool",
+		Creator:            "github.com/spdx/tools-golang/idsearcher",
+		PathsIgnored:       idconfig.BuilderPathsIgnored,
+		PackageRoots:       idconfig.BuilderPackageRoots,
+		DetectPackageRoots: idconfig.BuilderDetectPackageRoots,
+		FileIDs:            idconfig.BuilderFileIDs,
+		UUIDNamespace:      idconfig.BuilderUUIDNamespace,
+		Symlinks:           idconfig.BuilderSymlinks,
+		Package:            idconfig.BuilderPackage,
 	}
 	doc, err := builder.Build(packageName, dirRoot, bconfig)
 	if err != nil {
@@ -66,18 +98,31 @@ func BuildIDsDocument(packageName string, dirRoot string, idconfig *Config) (*sp
 	if doc.Packages == nil {
 		return nil, fmt.Errorf("builder returned nil Packages map")
 	}
-	if len(doc.Packages) != 1 {
-		return nil, fmt.Errorf("builder returned %d Packages", len(doc.Packages))
+	if len(doc.Packages) == 0 {
+		return nil, fmt.Errorf("builder returned 0 Packages")
 	}
 
-	// now, walk through each file and find its licenses (if any)
-	pkg := doc.Packages[0]
-	if pkg == nil {
-		return nil, fmt.Errorf("builder returned nil Package")
-	}
-	if pkg.Files == nil {
-		return nil, fmt.Errorf("builder returned nil Files in Package")
+	// now, walk through each package's files and find their licenses
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			return nil, fmt.Errorf("builder returned nil Package")
+		}
+		if !pkg.FilesAnalyzed {
+			continue
+		}
+		if pkg.Files == nil {
+			return nil, fmt.Errorf("builder returned nil Files in Package")
+		}
+		searchPackageIDs(pkg, dirRoot, idconfig.SearcherPathsIgnored, idconfig.BuilderSymlinks == utils.SymlinksRecord)
 	}
+
+	return doc, nil
+}
+
+// searchPackageIDs searches for short-form IDs in each of pkg's files,
+// filling in the license fields of the files and of pkg. If skipSymlinks
+// is set, files that are symbolic links are not searched.
+func searchPackageIDs(pkg *spdx.Package, dirRoot string, pathsIgnored []string, skipSymlinks bool) {
 	licsForPackage := map[string]int{}
 	for _, f := range pkg.Files {
 		// start by initializing / clearing values
@@ -85,11 +130,16 @@ func BuildIDsDocument(packageName string, dirRoot string, idconfig *Config) (*sp
 		f.LicenseConcluded = "NOASSERTION"
 
 		// check whether the searcher should ignore this file
-		if utils.ShouldIgnore(f.FileName, idconfig.SearcherPathsIgnored) {
+		if utils.ShouldIgnore(f.FileName, pathsIgnored) {
 			continue
 		}
 
 		fPath := filepath.Join(dirRoot, f.FileName)
+		if skipSymlinks {
+			if fi, err := os.Lstat(fPath); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+		}
 		// FIXME this is not preferable -- ignoring error
 		ids, _ := searchFileIDs(fPath)
 		// FIXME for now, proceed onwards with whatever IDs we obtained.
@@ -136,8 +186,6 @@ func BuildIDsDocument(packageName string, dirRoot string, idconfig *Config) (*sp
 		}
 		sort.Strings(pkg.PackageLicenseInfoFromFiles)
 	}
-
-	return doc, nil
 }
 
 // ===== Utility functions (not version-specific) =====
@@ -294,6 +294,43 @@ func TestSearcherFailsWithInvalidPath(t *testing.T) {
 	}
 }
 
+func TestSearcherCanFillInIDsPerPackage(t *testing.T) {
+	packageName := "monorepo"
+	dirRoot := "../testdata/monorepo/"
+	config := &Config{
+		NamespacePrefix:           "https://github.com/swinslow/spdx-docs/spdx-go/testdata-",
+		BuilderDetectPackageRoots: true,
+	}
+
+	doc, err := BuildIDsDocument(packageName, dirRoot, config)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(doc.Packages) != 3 {
+		t.Fatalf("expected %d, got %d", 3, len(doc.Packages))
+	}
+
+	want := map[string][]string{
+		"monorepo":   {"GPL-2.0-only"},
+		"libs/alpha": {"Apache-2.0", "MIT"},
+		"libs/beta":  {"BSD-3-Clause"},
+	}
+	for _, pkg := range doc.Packages {
+		lics, ok := want[pkg.PackageName]
+		if !ok {
+			t.Fatalf("unexpected package %s", pkg.PackageName)
+		}
+		if len(pkg.PackageLicenseInfoFromFiles) != len(lics) {
+			t.Fatalf("expected %v, got %v", lics, pkg.PackageLicenseInfoFromFiles)
+		}
+		for i, lic := range lics {
+			if pkg.PackageLicenseInfoFromFiles[i] != lic {
+				t.Errorf("expected %v, got %v", lic, pkg.PackageLicenseInfoFromFiles[i])
+			}
+		}
+	}
+}
+
 // ===== Searcher utility tests =====
 func TestCanFindShortFormIDWhenPresent(t *testing.T) {
 	filePath := "../testdata/project2/has-id.txt"
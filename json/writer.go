@@ -5,7 +5,9 @@ package json
 import (
 	"encoding/json"
 	"io"
+	"sync"
 
+	"github.com/spdx/tools-golang/convert"
 	"github.com/spdx/tools-golang/spdx/common"
 )
 
@@ -23,11 +25,48 @@ func EscapeHTML(escape bool) WriteOption {
 	}
 }
 
+// TargetVersion converts the document to the given SPDX version, such as
+// "SPDX-2.2", before writing it, as by convert.ToVersion.
+func TargetVersion(version string) WriteOption {
+	return writeConfigOption(convert.TargetVersion(version))
+}
+
+// DroppedFields calls report with the fields of the document dropped by
+// TargetVersion because the target version cannot represent them, if
+// there are any.
+func DroppedFields(report func(fields []string)) WriteOption {
+	return writeConfigOption(convert.DroppedFields(report))
+}
+
+// writeConfigs holds the convert.WriteConfig of each Write in progress, by
+// its encoder, for the options that configure the writing rather than the
+// encoder.
+var writeConfigs sync.Map
+
+// writeConfigOption adapts opt to a WriteOption, which applies it to the
+// convert.WriteConfig of the Write its encoder belongs to. It does nothing
+// to other encoders.
+func writeConfigOption(opt convert.WriteOption) WriteOption {
+	return func(e *json.Encoder) {
+		if c, ok := writeConfigs.Load(e); ok {
+			opt(c.(*convert.WriteConfig))
+		}
+	}
+}
+
 // Write takes an SPDX Document and an io.Writer, and writes the document to the writer in JSON format.
 func Write(doc common.AnyDocument, w io.Writer, opts ...WriteOption) error {
 	e := json.NewEncoder(w)
+	c := &convert.WriteConfig{}
+	writeConfigs.Store(e, c)
 	for _, opt := range opts {
 		opt(e)
 	}
+	writeConfigs.Delete(e)
+
+	doc, err := c.Apply(doc)
+	if err != nil {
+		return err
+	}
 	return e.Encode(doc)
 }
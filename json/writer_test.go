@@ -2,6 +2,7 @@ package json_test
 
 import (
 	"bytes"
+	gojson "encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -82,3 +83,36 @@ func Test_Write(t *testing.T) {
 		})
 	}
 }
+
+func Test_WriteEncoderOption(t *testing.T) {
+	// options may be plain functions of the encoder
+	buf := new(bytes.Buffer)
+	err := json.Write(spdx.Document{DocumentName: "<doc>"}, buf, func(e *gojson.Encoder) {
+		e.SetEscapeHTML(false)
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"name":"<doc>"`)
+}
+
+func Test_WriteTargetVersion(t *testing.T) {
+	doc := spdx.Document{
+		SPDXVersion:  spdx.Version,
+		DocumentName: "test_doc",
+		Packages: []*spdx.Package{
+			{PackageName: "p", PackageSPDXIdentifier: "p", PrimaryPackagePurpose: "LIBRARY"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	var dropped []string
+	err := json.Write(doc, buf, json.TargetVersion("SPDX-2.2"), json.DroppedFields(func(fields []string) {
+		dropped = fields
+	}), json.Indent(" "))
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"spdxVersion": "SPDX-2.2"`)
+	assert.NotContains(t, buf.String(), "primaryPackagePurpose")
+	assert.Equal(t, []string{"packages[0].primaryPackagePurpose"}, dropped)
+
+	err = json.Write(doc, buf, json.TargetVersion("SPDX-9.9"))
+	assert.Error(t, err)
+}
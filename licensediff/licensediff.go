@@ -1,5 +1,6 @@
 // Package licensediff is used to generate a "diff" between the concluded
-// licenses in two SPDX Packages, using the filename as the match point.
+// licenses in two SPDX Packages, using the filename as the match point, or,
+// with MatchFiles, also matching files that moved.
 // SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
 package licensediff
 
@@ -50,6 +51,20 @@ type LicenseDiff struct {
 	InBothSame    map[string]string
 	InFirstOnly   map[string]string
 	InSecondOnly  map[string]string
+	// Changed holds the files in both Packages under the same name whose
+	// licensing changed, including those whose only change is in
+	// LicenseInfoInFiles or copyright text, with the FileMatch whose
+	// Changes lists the fields that differ. Those whose LicenseConcluded
+	// changed are in InBothChanged as well.
+	Changed map[string]FileMatch
+	// Moved and MovedChanged hold the files that moved, keyed by their name
+	// in the second Package, with the same or changed licensing. The
+	// FileMatch's MatchedBy tells whether they were matched by checksum or
+	// only by similarity.
+	Moved        map[string]FileMatch
+	MovedChanged map[string]FileMatch
+	// Changed, Moved and MovedChanged are only filled in by
+	// MakeMatchResults.
 }
 
 // MakeResults creates a more structured set of results from the output
@@ -60,6 +75,9 @@ func MakeResults(pairs map[string]LicensePair) (*LicenseDiff, error) {
 		InBothSame:    map[string]string{},
 		InFirstOnly:   map[string]string{},
 		InSecondOnly:  map[string]string{},
+		Changed:       map[string]FileMatch{},
+		Moved:         map[string]FileMatch{},
+		MovedChanged:  map[string]FileMatch{},
 	}
 
 	// walk through pairs and allocate them where they belong
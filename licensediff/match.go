@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licensediff
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// How the files of a FileMatch were matched
+const (
+	MatchedByPath       = "path"
+	MatchedByChecksum   = "checksum"
+	MatchedBySimilarity = "similarity"
+)
+
+// FileMatch is a file of the first Package matched with a file of the
+// second. For a file found in only one Package, the other is nil.
+type FileMatch struct {
+	First     *spdx.File
+	Second    *spdx.File
+	MatchedBy string
+	// Similarity is the similarity of files matched by similarity, from 0
+	// to 1; see MatchOptions.
+	Similarity float64
+}
+
+// Moved reports whether the matched files have different names.
+func (m FileMatch) Moved() bool {
+	return m.First != nil && m.Second != nil && m.First.FileName != m.Second.FileName
+}
+
+// Changes returns the licensing fields of the matched files that differ:
+// "LicenseConcluded" (unless the licenses are equivalent, as by
+// licenseexpr.Equivalent), "LicenseInfoInFiles" (ignoring order) and
+// "FileCopyrightText".
+func (m FileMatch) Changes() []string {
+	if m.First == nil || m.Second == nil {
+		return nil
+	}
+	changes := []string{}
+	if !sameLicense(m.First.LicenseConcluded, m.Second.LicenseConcluded) {
+		changes = append(changes, "LicenseConcluded")
+	}
+	if !sameStrings(m.First.LicenseInfoInFiles, m.Second.LicenseInfoInFiles) {
+		changes = append(changes, "LicenseInfoInFiles")
+	}
+	if m.First.FileCopyrightText != m.Second.FileCopyrightText {
+		changes = append(changes, "FileCopyrightText")
+	}
+	return changes
+}
+
+// MatchOptions configures MatchFiles.
+type MatchOptions struct {
+	// SimilarityThreshold is the similarity, from 0 to 1, at or above
+	// which files not otherwise matched are matched. Similarity is scored
+	// from the files' metadata: half for the same base name, a quarter for
+	// the overlap of their LicenseInfoInFiles, and a quarter for the same
+	// copyright text. If zero, 0.75 is used, so that only files with the
+	// same base name are matched; if above 1, files are not matched by
+	// similarity.
+	SimilarityThreshold float64
+}
+
+// MatchFiles matches the Files of p1 with those of p2: first the files
+// with the same name and checksum, then those with the same SHA1 or SHA256
+// checksum (files that moved), then those with the same name (files that
+// changed), and finally those most similar. Files matched by similarity
+// may have different contents, which their MatchedBy and Similarity show.
+// It returns the matches in the order of p2's
+// files, followed by the files only in p1.
+func MatchFiles(p1 *spdx.Package, p2 *spdx.Package, opts *MatchOptions) ([]FileMatch, error) {
+	threshold := 0.75
+	if opts != nil && opts.SimilarityThreshold != 0 {
+		threshold = opts.SimilarityThreshold
+	}
+	firsts, seconds := nonNilFiles(p1.Files), nonNilFiles(p2.Files)
+	matchFor := make([]FileMatch, len(seconds))
+	firstUsed := make([]bool, len(firsts))
+
+	// matchBy matches each unmatched second file with the first unmatched
+	// first file sharing one of its keys
+	matchBy := func(matchedBy string, keys func(*spdx.File) []string) {
+		byKey := map[string][]int{}
+		for i, f := range firsts {
+			if !firstUsed[i] {
+				for _, key := range keys(f) {
+					byKey[key] = append(byKey[key], i)
+				}
+			}
+		}
+		for j, f := range seconds {
+			if matchFor[j].Second != nil {
+				continue
+			}
+		keys:
+			for _, key := range keys(f) {
+				for _, i := range byKey[key] {
+					if !firstUsed[i] {
+						firstUsed[i] = true
+						matchFor[j] = FileMatch{First: firsts[i], Second: f, MatchedBy: matchedBy}
+						break keys
+					}
+				}
+			}
+		}
+	}
+	matchBy(MatchedByChecksum, func(f *spdx.File) []string {
+		keys := []string{}
+		for _, c := range checksumKeys(f) {
+			keys = append(keys, f.FileName+"\x00"+c)
+		}
+		return keys
+	})
+	for j := range matchFor {
+		// these files are in the same place, rather than moved
+		if matchFor[j].Second != nil {
+			matchFor[j].MatchedBy = MatchedByPath
+		}
+	}
+	matchBy(MatchedByChecksum, checksumKeys)
+	matchBy(MatchedByPath, func(f *spdx.File) []string { return []string{f.FileName} })
+
+	if threshold <= 1 {
+		type candidate struct {
+			i, j  int
+			score float64
+		}
+		candidates := []candidate{}
+		for j, f2 := range seconds {
+			if matchFor[j].Second != nil {
+				continue
+			}
+			for i, f1 := range firsts {
+				if firstUsed[i] {
+					continue
+				}
+				if score := similarity(f1, f2); score >= threshold {
+					candidates = append(candidates, candidate{i, j, score})
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].score > candidates[b].score
+		})
+		for _, c := range candidates {
+			if firstUsed[c.i] || matchFor[c.j].Second != nil {
+				continue
+			}
+			firstUsed[c.i] = true
+			matchFor[c.j] = FileMatch{First: firsts[c.i], Second: seconds[c.j], MatchedBy: MatchedBySimilarity, Similarity: c.score}
+		}
+	}
+
+	matches := []FileMatch{}
+	for j, f := range seconds {
+		if matchFor[j].Second == nil {
+			matchFor[j] = FileMatch{Second: f}
+		}
+		matches = append(matches, matchFor[j])
+	}
+	for i, f := range firsts {
+		if !firstUsed[i] {
+			matches = append(matches, FileMatch{First: f})
+		}
+	}
+	return matches, nil
+}
+
+// MakeMatchResults creates a LicenseDiff from the output of MatchFiles.
+// Files are compared by LicenseConcluded, LicenseInfoInFiles and copyright
+// text. Files with the same name are InBothSame or Changed, and also
+// InBothChanged if their concluded licenses differ. Files that moved are
+// Moved or MovedChanged, by their name in the second Package. Those
+// matched only by similarity may have different contents; callers that
+// want only files with the same contents can skip matches whose MatchedBy
+// is MatchedBySimilarity, or whose Similarity is too low.
+func MakeMatchResults(matches []FileMatch) (*LicenseDiff, error) {
+	diff := &LicenseDiff{
+		InBothChanged: map[string]LicensePair{},
+		InBothSame:    map[string]string{},
+		InFirstOnly:   map[string]string{},
+		InSecondOnly:  map[string]string{},
+		Changed:       map[string]FileMatch{},
+		Moved:         map[string]FileMatch{},
+		MovedChanged:  map[string]FileMatch{},
+	}
+	for _, m := range matches {
+		changes := m.Changes()
+		switch {
+		case m.Second == nil:
+			diff.InFirstOnly[m.First.FileName] = m.First.LicenseConcluded
+		case m.First == nil:
+			diff.InSecondOnly[m.Second.FileName] = m.Second.LicenseConcluded
+		case m.Moved() && len(changes) > 0:
+			diff.MovedChanged[m.Second.FileName] = m
+		case m.Moved():
+			diff.Moved[m.Second.FileName] = m
+		case len(changes) > 0:
+			diff.Changed[m.Second.FileName] = m
+			if changes[0] == "LicenseConcluded" {
+				diff.InBothChanged[m.Second.FileName] = LicensePair{First: m.First.LicenseConcluded, Second: m.Second.LicenseConcluded}
+			}
+		default:
+			diff.InBothSame[m.Second.FileName] = m.Second.LicenseConcluded
+		}
+	}
+	return diff, nil
+}
+
+// similarity scores the similarity of two files' metadata, as described
+// for MatchOptions.
+func similarity(f1 *spdx.File, f2 *spdx.File) float64 {
+	score := 0.0
+	if path.Base(f1.FileName) == path.Base(f2.FileName) {
+		score += 0.5
+	}
+	inFirst, union := map[string]bool{}, map[string]bool{}
+	for _, l := range f1.LicenseInfoInFiles {
+		inFirst[l], union[l] = true, true
+	}
+	shared := map[string]bool{}
+	for _, l := range f2.LicenseInfoInFiles {
+		if inFirst[l] {
+			shared[l] = true
+		}
+		union[l] = true
+	}
+	if len(union) == 0 {
+		score += 0.25
+	} else {
+		score += 0.25 * float64(len(shared)) / float64(len(union))
+	}
+	if f1.FileCopyrightText == f2.FileCopyrightText {
+		score += 0.25
+	}
+	return score
+}
+
+// checksumKeys returns the SHA1 and SHA256 checksums of f.
+func checksumKeys(f *spdx.File) []string {
+	keys := []string{}
+	for _, c := range f.Checksums {
+		if c.Algorithm == common.SHA1 || c.Algorithm == common.SHA256 {
+			keys = append(keys, string(c.Algorithm)+":"+strings.ToLower(c.Value))
+		}
+	}
+	return keys
+}
+
+func sameLicense(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	// expressions that cannot be parsed, or have too many choices to
+	// compare, are only the same if they are equal
+	equivalent, err := licenseexpr.Equivalent(a, b)
+	return err == nil && equivalent
+}
+
+func sameStrings(a []string, b []string) bool {
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, s := range a {
+		inA[s] = true
+	}
+	for _, s := range b {
+		inB[s] = true
+	}
+	if len(inA) != len(inB) {
+		return false
+	}
+	for s := range inA {
+		if !inB[s] {
+			return false
+		}
+	}
+	return true
+}
+
+func nonNilFiles(files []*spdx.File) []*spdx.File {
+	result := []*spdx.File{}
+	for _, f := range files {
+		if f != nil {
+			result = append(result, f)
+		}
+	}
+	return result
+}
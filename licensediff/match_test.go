@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licensediff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func makeMatchTestFile(name string, sha1 string, license string) *spdx.File {
+	f := &spdx.File{
+		FileName:           name,
+		LicenseConcluded:   license,
+		LicenseInfoInFiles: []string{license},
+		FileCopyrightText:  "Copyright (c) Jane Doe",
+	}
+	if sha1 != "" {
+		f.Checksums = []common.Checksum{{Algorithm: common.SHA1, Value: sha1}}
+	}
+	return f
+}
+
+func matchStrings(matches []FileMatch) []string {
+	result := []string{}
+	for _, m := range matches {
+		first, second := "-", "-"
+		if m.First != nil {
+			first = m.First.FileName
+		}
+		if m.Second != nil {
+			second = m.Second.FileName
+		}
+		result = append(result, fmt.Sprintf("%s>%s:%s", first, second, m.MatchedBy))
+	}
+	return result
+}
+
+// ===== File matching tests =====
+func TestMatchFilesMatchesByPathChecksumAndSimilarity(t *testing.T) {
+	p1 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/a.c", "aaaa", "MIT"),
+		makeMatchTestFile("/old/b.c", "bbbb", "MIT"),
+		makeMatchTestFile("/c.c", "cccc", "MIT"),
+		makeMatchTestFile("/old/d.c", "dddd", "MIT"),
+		makeMatchTestFile("/gone.c", "eeee", "MIT"),
+	}}
+	p2 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/a.c", "aaaa", "MIT"),
+		makeMatchTestFile("/new/b.c", "BBBB", "MIT"),
+		makeMatchTestFile("/c.c", "c2c2", "Apache-2.0"),
+		makeMatchTestFile("/new/d.c", "d2d2", "MIT"),
+		makeMatchTestFile("/added.c", "ffff", "MIT"),
+	}}
+	matches, err := MatchFiles(p1, p2, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := "[/a.c>/a.c:path /old/b.c>/new/b.c:checksum /c.c>/c.c:path /old/d.c>/new/d.c:similarity ->/added.c: /gone.c>-:]"
+	if got := fmt.Sprint(matchStrings(matches)); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if matches[3].Similarity != 1 {
+		t.Errorf("expected %v, got %v", 1, matches[3].Similarity)
+	}
+}
+
+func TestMatchFilesPrefersSamePathForDuplicateChecksums(t *testing.T) {
+	p1 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/x/LICENSE", "aaaa", "MIT"),
+		makeMatchTestFile("/y/LICENSE", "aaaa", "MIT"),
+	}}
+	p2 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/z/LICENSE", "aaaa", "MIT"),
+		makeMatchTestFile("/y/LICENSE", "aaaa", "MIT"),
+	}}
+	matches, _ := MatchFiles(p1, p2, nil)
+	want := "[/x/LICENSE>/z/LICENSE:checksum /y/LICENSE>/y/LICENSE:path]"
+	if got := fmt.Sprint(matchStrings(matches)); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMatchFilesCanDisableSimilarity(t *testing.T) {
+	p1 := &spdx.Package{Files: []*spdx.File{makeMatchTestFile("/old/d.c", "dddd", "MIT")}}
+	p2 := &spdx.Package{Files: []*spdx.File{makeMatchTestFile("/new/d.c", "d2d2", "MIT")}}
+	matches, _ := MatchFiles(p1, p2, &MatchOptions{SimilarityThreshold: 2})
+	want := "[->/new/d.c: /old/d.c>-:]"
+	if got := fmt.Sprint(matchStrings(matches)); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFileMatchReportsLicensingChanges(t *testing.T) {
+	f1 := makeMatchTestFile("/a.c", "aaaa", "MIT")
+	f1.LicenseInfoInFiles = []string{"MIT", "BSD-3-Clause"}
+	f2 := makeMatchTestFile("/a.c", "aaaa", "MIT")
+	f2.LicenseInfoInFiles = []string{"BSD-3-Clause", "MIT"}
+	f1.LicenseConcluded, f2.LicenseConcluded = "MIT OR BSD-3-Clause", "BSD-3-Clause OR MIT"
+	if changes := (FileMatch{First: f1, Second: f2}).Changes(); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	f2.LicenseInfoInFiles = []string{"MIT"}
+	f2.FileCopyrightText = "Copyright (c) John Doe"
+	want := "[LicenseInfoInFiles FileCopyrightText]"
+	if got := fmt.Sprint((FileMatch{First: f1, Second: f2}).Changes()); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// ===== Match results tests =====
+func TestMakeMatchResultsCategorizesMatches(t *testing.T) {
+	copyrightChanged := makeMatchTestFile("/e.c", "e2e2", "MIT")
+	copyrightChanged.FileCopyrightText = "Copyright (c) John Doe"
+	p1 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/a.c", "aaaa", "MIT"),
+		makeMatchTestFile("/old/b.c", "bbbb", "MIT"),
+		makeMatchTestFile("/c.c", "cccc", "MIT"),
+		makeMatchTestFile("/old/d.c", "dddd", "MIT"),
+		makeMatchTestFile("/e.c", "eeee", "MIT"),
+		makeMatchTestFile("/gone.c", "9999", "MIT"),
+		makeMatchTestFile("/docs/README.md", "1111", "MIT"),
+	}}
+	movedChanged := makeMatchTestFile("/new/d.c", "dddd", "Apache-2.0")
+	p2 := &spdx.Package{Files: []*spdx.File{
+		makeMatchTestFile("/a.c", "aaaa", "MIT"),
+		makeMatchTestFile("/new/b.c", "bbbb", "MIT"),
+		makeMatchTestFile("/c.c", "c2c2", "Apache-2.0"),
+		movedChanged,
+		copyrightChanged,
+		makeMatchTestFile("/added.c", "ffff", "GPL-2.0-only"),
+		// a file with the same name, matched only by similarity
+		makeMatchTestFile("/examples/README.md", "2222", "MIT"),
+	}}
+	matches, _ := MatchFiles(p1, p2, nil)
+	diff, err := MakeMatchResults(matches)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(diff.InBothSame) != 1 || diff.InBothSame["/a.c"] != "MIT" {
+		t.Errorf("unexpected InBothSame %v", diff.InBothSame)
+	}
+	want := map[string]LicensePair{
+		"/c.c": {First: "MIT", Second: "Apache-2.0"},
+	}
+	if fmt.Sprint(diff.InBothChanged) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, diff.InBothChanged)
+	}
+	changes := map[string]string{}
+	for name, m := range diff.Changed {
+		changes[name] = fmt.Sprint(m.Changes())
+	}
+	wantChanges := map[string]string{
+		"/c.c": "[LicenseConcluded LicenseInfoInFiles]",
+		"/e.c": "[FileCopyrightText]",
+	}
+	if fmt.Sprint(changes) != fmt.Sprint(wantChanges) {
+		t.Errorf("expected %v, got %v", wantChanges, changes)
+	}
+	if len(diff.Moved) != 2 || diff.Moved["/new/b.c"].First.FileName != "/old/b.c" {
+		t.Errorf("unexpected Moved %v", diff.Moved)
+	}
+	if m := diff.Moved["/examples/README.md"]; m.MatchedBy != MatchedBySimilarity || m.First == nil || m.First.FileName != "/docs/README.md" {
+		t.Errorf("expected README.md to be moved by similarity, got %+v", m)
+	}
+	if len(diff.MovedChanged) != 1 || diff.MovedChanged["/new/d.c"].Second != movedChanged {
+		t.Errorf("unexpected MovedChanged %v", diff.MovedChanged)
+	}
+	if len(diff.InFirstOnly) != 1 || diff.InFirstOnly["/gone.c"] != "MIT" {
+		t.Errorf("unexpected InFirstOnly %v", diff.InFirstOnly)
+	}
+	if len(diff.InSecondOnly) != 1 || diff.InSecondOnly["/added.c"] != "GPL-2.0-only" {
+		t.Errorf("unexpected InSecondOnly %v", diff.InSecondOnly)
+	}
+}
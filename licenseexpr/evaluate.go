@@ -0,0 +1,284 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licenseexpr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Normalize returns a normalized copy of e: nested operands with the same
+// operator are flattened into one, so that "A AND (B AND C)" becomes
+// "A AND B AND C", duplicate operands are removed, and operands are sorted
+// by their string form, ignoring case. Two expressions that differ only in
+// these ways normalize to the same expression.
+func (e *Expression) Normalize() *Expression {
+	if e.IsLicense() {
+		copied := *e
+		return &copied
+	}
+	operands := []*Expression{}
+	seen := map[string]bool{}
+	var add func(o *Expression)
+	add = func(o *Expression) {
+		o = o.Normalize()
+		if o.Op == e.Op {
+			for _, nested := range o.Operands {
+				add(nested)
+			}
+			return
+		}
+		if key := o.String(); !seen[key] {
+			seen[key] = true
+			operands = append(operands, o)
+		}
+	}
+	for _, o := range e.Operands {
+		add(o)
+	}
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	sort.SliceStable(operands, func(i, j int) bool {
+		return lessFold(operands[i].String(), operands[j].String())
+	})
+	return &Expression{Op: e.Op, Operands: operands}
+}
+
+// MaxChoices is the largest number of choices Choices expands an
+// expression to. An expression ANDing n terms of the form "(A OR B)" has
+// 2^n choices, so larger expressions give ErrTooManyChoices.
+const MaxChoices = 1024
+
+// ErrTooManyChoices is returned by Choices and Equivalent when an
+// expression has more than MaxChoices choices.
+var ErrTooManyChoices = fmt.Errorf("license expression has more than %d choices", MaxChoices)
+
+// Choices returns the minimal sets of licenses, any one of which satisfies
+// e. Each license is given as a single license expression, such as "MIT"
+// or "GPL-2.0-or-later WITH Classpath-exception-2.0". For example, the
+// choices of "(MIT OR Apache-2.0) AND BSD-3-Clause" are [Apache-2.0
+// BSD-3-Clause] and [BSD-3-Clause MIT]. Each choice is sorted, and the
+// choices are sorted, ignoring case. A choice that includes all of the
+// licenses of another is left out. If e has more than MaxChoices choices,
+// ErrTooManyChoices is returned.
+func (e *Expression) Choices() ([][]string, error) {
+	choices, err := e.choices()
+	if err != nil {
+		return nil, err
+	}
+	return choices.sorted(), nil
+}
+
+// Satisfies reports whether the licenses allowed satisfy e: whether every
+// license of one of its choices is allowed. Licenses are compared ignoring
+// case, and a license with an exception is only allowed if it is given
+// with that exception, such as "GPL-2.0-only WITH Classpath-exception-2.0".
+// The choice satisfied, if any, is returned; if several are, it is one of
+// them. Unlike Choices, Satisfies does not expand e, so it works for
+// expressions of any size.
+func (e *Expression) Satisfies(allowed []string) ([]string, bool) {
+	isAllowed := map[string]bool{}
+	for _, a := range allowed {
+		if parsed, err := Parse(a); err == nil && parsed.IsLicense() {
+			a = parsed.String()
+		}
+		isAllowed[strings.ToLower(a)] = true
+	}
+	// start from every allowed license of e, then leave out the licenses
+	// that are not needed, so that what is left is a minimal choice
+	choice := map[string]string{}
+	e.Walk(func(license *Expression) {
+		if l := license.String(); isAllowed[strings.ToLower(l)] {
+			choice[strings.ToLower(l)] = l
+		}
+	})
+	if !e.satisfiedBy(choice) {
+		return nil, false
+	}
+	keys := []string{}
+	for key := range choice {
+		keys = append(keys, key)
+	}
+	// leave out later licenses first, to keep the earlier ones
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	for _, key := range keys {
+		l := choice[key]
+		delete(choice, key)
+		if !e.satisfiedBy(choice) {
+			choice[key] = l
+		}
+	}
+	return choiceSet{choice}.sorted()[0], true
+}
+
+// Equivalent reports whether e and other are satisfied by the same sets of
+// licenses, so that "MIT AND (MIT OR Apache-2.0)" is equivalent to "MIT".
+// Licenses are compared ignoring case. Expressions that normalize to the
+// same expression are equivalent; otherwise their choices are compared,
+// and ErrTooManyChoices is returned if either has more than MaxChoices.
+func (e *Expression) Equivalent(other *Expression) (bool, error) {
+	if strings.EqualFold(e.Normalize().String(), other.Normalize().String()) {
+		return true, nil
+	}
+	a, err := e.Choices()
+	if err != nil {
+		return false, err
+	}
+	b, err := other.Choices()
+	if err != nil {
+		return false, err
+	}
+	if len(a) != len(b) {
+		return false, nil
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false, nil
+		}
+		for j := range a[i] {
+			if !strings.EqualFold(a[i][j], b[i][j]) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Equivalent parses the license expressions a and b and reports whether
+// they are equivalent, as by Expression.Equivalent.
+func Equivalent(a string, b string) (bool, error) {
+	ea, err := Parse(a)
+	if err != nil {
+		return false, err
+	}
+	eb, err := Parse(b)
+	if err != nil {
+		return false, err
+	}
+	return ea.Equivalent(eb)
+}
+
+// satisfiedBy reports whether the licenses of choice, keyed by their
+// lower-cased string form, satisfy e.
+func (e *Expression) satisfiedBy(choice map[string]string) bool {
+	if e.IsLicense() {
+		_, ok := choice[strings.ToLower(e.String())]
+		return ok
+	}
+	for _, o := range e.Operands {
+		if o.satisfiedBy(choice) == (e.Op == Or) {
+			return e.Op == Or
+		}
+	}
+	return e.Op == And
+}
+
+// choiceSet is a set of choices, each a set of licenses keyed by their
+// lower-cased string form.
+type choiceSet []map[string]string
+
+func (e *Expression) choices() (choiceSet, error) {
+	if e.IsLicense() {
+		l := e.String()
+		return choiceSet{{strings.ToLower(l): l}}, nil
+	}
+	result, err := e.Operands[0].choices()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range e.Operands[1:] {
+		next, err := o.choices()
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == Or {
+			if len(result)+len(next) > MaxChoices {
+				return nil, ErrTooManyChoices
+			}
+			result = append(result, next...)
+		} else {
+			if len(result)*len(next) > MaxChoices {
+				return nil, ErrTooManyChoices
+			}
+			product := choiceSet{}
+			for _, a := range result {
+				for _, b := range next {
+					merged := map[string]string{}
+					for k, v := range a {
+						merged[k] = v
+					}
+					for k, v := range b {
+						merged[k] = v
+					}
+					product = append(product, merged)
+				}
+			}
+			result = product
+		}
+		result = result.minimize()
+	}
+	return result, nil
+}
+
+// minimize removes the choices that include all of the licenses of
+// another, and duplicates.
+func (s choiceSet) minimize() choiceSet {
+	sort.SliceStable(s, func(i, j int) bool { return len(s[i]) < len(s[j]) })
+	result := choiceSet{}
+	for _, c := range s {
+		redundant := false
+		for _, kept := range result {
+			if includes(c, kept) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// includes reports whether every license of b is in a.
+func includes(a map[string]string, b map[string]string) bool {
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s choiceSet) sorted() [][]string {
+	result := [][]string{}
+	for _, c := range s {
+		choice := []string{}
+		for _, l := range c {
+			choice = append(choice, l)
+		}
+		sort.Slice(choice, func(i, j int) bool { return lessFold(choice[i], choice[j]) })
+		result = append(result, choice)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if !strings.EqualFold(a[k], b[k]) {
+				return lessFold(a[k], b[k])
+			}
+		}
+		return len(a) < len(b)
+	})
+	return result
+}
+
+// lessFold orders strings ignoring case, then by case.
+func lessFold(a string, b string) bool {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	if la != lb {
+		return la < lb
+	}
+	return a < b
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licenseexpr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) *Expression {
+	t.Helper()
+	e, err := Parse(s)
+	if err != nil {
+		t.Fatalf("%s: expected nil error, got %v", s, err)
+	}
+	return e
+}
+
+// ===== Normalization tests =====
+func TestNormalizeFlattensDeduplicatesAndOrders(t *testing.T) {
+	for in, want := range map[string]string{
+		"MIT":                                    "MIT",
+		"MIT AND (ISC AND MIT)":                  "ISC AND MIT",
+		"(Zlib OR (MIT OR Apache-2.0)) AND ISC":  "(Apache-2.0 OR MIT OR Zlib) AND ISC",
+		"MIT OR MIT":                             "MIT",
+		"b AND A AND (c OR a)":                   "A AND (a OR c) AND b",
+		"GPL-2.0+ WITH X OR GPL-2.0+ WITH X":     "GPL-2.0+ WITH X",
+		"(MIT AND ISC) OR (ISC AND MIT) OR 0BSD": "0BSD OR ISC AND MIT",
+	} {
+		if got := mustParse(t, in).Normalize().String(); got != want {
+			t.Errorf("%s: expected %v, got %v", in, want, got)
+		}
+	}
+}
+
+// ===== Choice tests =====
+func TestChoicesAreMinimal(t *testing.T) {
+	for in, want := range map[string]string{
+		"MIT":                                              "[[MIT]]",
+		"(MIT OR Apache-2.0) AND BSD-3-Clause":             "[[Apache-2.0 BSD-3-Clause] [BSD-3-Clause MIT]]",
+		"MIT AND (MIT OR Apache-2.0)":                      "[[MIT]]",
+		"MIT OR (MIT AND Apache-2.0)":                      "[[MIT]]",
+		"(A OR B) AND (C OR D)":                            "[[A C] [A D] [B C] [B D]]",
+		"GPL-2.0-only WITH Classpath-exception-2.0 OR MIT": "[[GPL-2.0-only WITH Classpath-exception-2.0] [MIT]]",
+	} {
+		choices, err := mustParse(t, in).Choices()
+		if err != nil {
+			t.Errorf("%s: expected nil error, got %v", in, err)
+		}
+		if got := fmt.Sprint(choices); got != want {
+			t.Errorf("%s: expected %v, got %v", in, want, got)
+		}
+	}
+}
+
+// makeManyChoices returns the expression ANDing n terms "(Ai OR Bi)",
+// which has 2^n choices.
+func makeManyChoices(n int) string {
+	terms := []string{}
+	for i := 0; i < n; i++ {
+		terms = append(terms, fmt.Sprintf("(A%d OR B%d)", i, i))
+	}
+	return strings.Join(terms, " AND ")
+}
+
+func TestChoicesFailsWithTooManyChoices(t *testing.T) {
+	if _, err := mustParse(t, makeManyChoices(10)).Choices(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+	if _, err := mustParse(t, makeManyChoices(20)).Choices(); err != ErrTooManyChoices {
+		t.Errorf("expected %v, got %v", ErrTooManyChoices, err)
+	}
+}
+
+func TestSatisfiesChecksAllowedLicenses(t *testing.T) {
+	e := mustParse(t, "(MIT OR Apache-2.0) AND BSD-3-Clause")
+	if choice, ok := e.Satisfies([]string{"apache-2.0", "BSD-3-Clause", "ISC"}); !ok || fmt.Sprint(choice) != "[Apache-2.0 BSD-3-Clause]" {
+		t.Errorf("expected to be satisfied by [Apache-2.0 BSD-3-Clause], got %v, %v", choice, ok)
+	}
+	if _, ok := e.Satisfies([]string{"MIT", "Apache-2.0"}); ok {
+		t.Errorf("expected not to be satisfied without BSD-3-Clause")
+	}
+	withException := mustParse(t, "GPL-2.0-only WITH Classpath-exception-2.0")
+	if _, ok := withException.Satisfies([]string{"GPL-2.0-only"}); ok {
+		t.Errorf("expected not to be satisfied without the exception")
+	}
+	if _, ok := withException.Satisfies([]string{"GPL-2.0-only  with  Classpath-exception-2.0"}); !ok {
+		t.Errorf("expected to be satisfied with the exception")
+	}
+	if choice, ok := mustParse(t, "(A AND B) OR A OR C").Satisfies([]string{"A", "B", "C"}); !ok || fmt.Sprint(choice) != "[A]" {
+		t.Errorf("expected to be satisfied by [A], got %v, %v", choice, ok)
+	}
+
+	// expressions with too many choices to expand can still be checked
+	many := mustParse(t, makeManyChoices(20))
+	if choice, ok := many.Satisfies([]string{"A0", "B1", "A2", "A3", "A4", "A5", "A6", "A7", "A8", "A9", "A10", "A11", "A12", "A13", "A14", "A15", "A16", "A17", "A18", "A19", "MIT"}); !ok || len(choice) != 20 {
+		t.Errorf("expected to be satisfied by a choice of 20 licenses, got %v, %v", choice, ok)
+	}
+	if _, ok := many.Satisfies([]string{"A0", "B0"}); ok {
+		t.Errorf("expected not to be satisfied by A0 and B0")
+	}
+}
+
+// ===== Equivalence tests =====
+func TestEquivalentComparesChoices(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want bool
+	}{
+		{"MIT OR Apache-2.0", "Apache-2.0 OR MIT", true},
+		{"MIT AND (MIT OR Apache-2.0)", "MIT", true},
+		{"(A OR B) AND C", "(A AND C) OR (B AND C)", true},
+		{"mit", "MIT", true},
+		{"MIT OR Apache-2.0", "MIT AND Apache-2.0", false},
+		{"GPL-2.0+", "GPL-2.0", false},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", "GPL-2.0-only", false},
+	} {
+		got, err := Equivalent(c.a, c.b)
+		if err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+		if got != c.want {
+			t.Errorf("%s, %s: expected %v, got %v", c.a, c.b, c.want, got)
+		}
+	}
+	if _, err := Equivalent("MIT AND", "MIT"); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+
+	// expressions normalizing to the same expression are not expanded
+	many := makeManyChoices(20)
+	if got, err := Equivalent(many, strings.ToLower(many)); err != nil || !got {
+		t.Errorf("expected equivalent, got %v, %v", got, err)
+	}
+	if _, err := Equivalent(many, "MIT"); err != ErrTooManyChoices {
+		t.Errorf("expected %v, got %v", ErrTooManyChoices, err)
+	}
+}
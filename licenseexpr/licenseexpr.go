@@ -0,0 +1,285 @@
+// Package licenseexpr parses SPDX license expressions, such as
+// "(MIT OR Apache-2.0) AND GPL-2.0-or-later WITH Classpath-exception-2.0",
+// so that the licenses they refer to can be examined individually, and
+// evaluates them: whether a set of licenses satisfies an expression, and
+// whether two expressions are equivalent.
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+package licenseexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator is the operator of a compound Expression.
+type Operator string
+
+// The operators of compound expressions
+const (
+	And Operator = "AND"
+	Or  Operator = "OR"
+)
+
+// Expression is a parsed license expression: either a single license, or a
+// compound expression joining two or more Operands with an Operator.
+type Expression struct {
+	// Op is And or Or for a compound expression, and empty for a license.
+	Op       Operator
+	Operands []*Expression
+
+	// License is the license of a single license expression: an SPDX
+	// license ID, a LicenseRef, possibly prefixed by a DocumentRef, or
+	// NONE or NOASSERTION.
+	License string
+	// OrLater is whether the license was followed by "+".
+	OrLater bool
+	// Exception is the exception added to the license by WITH, if any.
+	Exception string
+}
+
+// IsLicense reports whether e is a single license, rather than a compound
+// expression.
+func (e *Expression) IsLicense() bool {
+	return e.Op == ""
+}
+
+// LicenseID returns the license of a single license expression, followed
+// by "+" if it applies to later versions too.
+func (e *Expression) LicenseID() string {
+	if e.OrLater {
+		return e.License + "+"
+	}
+	return e.License
+}
+
+// String returns e as a license expression, with parentheses only where
+// they are needed.
+func (e *Expression) String() string {
+	if e.IsLicense() {
+		if e.Exception != "" {
+			return e.LicenseID() + " WITH " + e.Exception
+		}
+		return e.LicenseID()
+	}
+	parts := []string{}
+	for _, o := range e.Operands {
+		if e.Op == And && o.Op == Or {
+			parts = append(parts, "("+o.String()+")")
+		} else {
+			parts = append(parts, o.String())
+		}
+	}
+	return strings.Join(parts, " "+string(e.Op)+" ")
+}
+
+// Walk calls visit for each single license of e, from left to right.
+func (e *Expression) Walk(visit func(license *Expression)) {
+	if e.IsLicense() {
+		visit(e)
+		return
+	}
+	for _, o := range e.Operands {
+		o.Walk(visit)
+	}
+}
+
+// Licenses returns the licenses of e, as by LicenseID, in the order they
+// first appear and without duplicates.
+func (e *Expression) Licenses() []string {
+	return distinct(e, func(l *Expression) string { return l.LicenseID() })
+}
+
+// Exceptions returns the exceptions of e in the order they first appear
+// and without duplicates.
+func (e *Expression) Exceptions() []string {
+	return distinct(e, func(l *Expression) string { return l.Exception })
+}
+
+func distinct(e *Expression, value func(*Expression) string) []string {
+	values := []string{}
+	seen := map[string]bool{}
+	e.Walk(func(l *Expression) {
+		v := value(l)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	})
+	return values
+}
+
+// Parse parses a license expression. WITH binds more tightly than AND,
+// which binds more tightly than OR, and operators may be given in upper or
+// lower case. Chains of the same operator, such as "A AND B AND C", are
+// parsed as a single compound expression.
+func Parse(s string) (*Expression, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %v", s, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, p.peek())
+	}
+	return e, nil
+}
+
+// Licenses returns the licenses of the expression s, as by
+// Expression.Licenses.
+func Licenses(s string) ([]string, error) {
+	e, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return e.Licenses(), nil
+}
+
+func tokenize(s string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token if it is the keyword kw, in any case.
+func (p *parser) accept(kw string) bool {
+	if strings.EqualFold(p.peek(), kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (*Expression, error) {
+	return p.parseChain(Or, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*Expression, error) {
+	return p.parseChain(And, p.parseWith)
+}
+
+func (p *parser) parseChain(op Operator, operand func() (*Expression, error)) (*Expression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*Expression{first}
+	for p.accept(string(op)) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &Expression{Op: op, Operands: operands}, nil
+}
+
+func (p *parser) parseWith() (*Expression, error) {
+	e, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("WITH") {
+		if !e.IsLicense() || e.Exception != "" {
+			return nil, fmt.Errorf("WITH must follow a license")
+		}
+		exception := p.peek()
+		if !isIdentifier(exception) {
+			return nil, fmt.Errorf("expected an exception after WITH")
+		}
+		p.pos++
+		e.Exception = exception
+	}
+	return e, nil
+}
+
+func (p *parser) parseOperand() (*Expression, error) {
+	if p.accept("(") {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return e, nil
+	}
+	tok := p.peek()
+	if tok == "" {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if !isIdentifier(tok) {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	p.pos++
+	e := &Expression{License: tok}
+	if strings.HasSuffix(tok, "+") {
+		e.License, e.OrLater = strings.TrimSuffix(tok, "+"), true
+	}
+	if e.License == "" {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	return e, nil
+}
+
+// isIdentifier reports whether tok can be a license or exception: not a
+// parenthesis or operator, and made only of letters, digits, ".", "-", ":"
+// and a trailing "+".
+func isIdentifier(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	for _, kw := range []string{"AND", "OR", "WITH"} {
+		if strings.EqualFold(tok, kw) {
+			return false
+		}
+	}
+	for i, r := range tok {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == ':':
+		case r == '+' && i == len(tok)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licenseexpr
+
+import (
+	"fmt"
+	"testing"
+)
+
+// ===== Parser tests =====
+func TestParseHandlesPrecedence(t *testing.T) {
+	e, err := Parse("MIT OR Apache-2.0 AND GPL-2.0+ WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if e.Op != Or || len(e.Operands) != 2 {
+		t.Fatalf("expected OR of 2 operands, got %+v", e)
+	}
+	and := e.Operands[1]
+	if and.Op != And || len(and.Operands) != 2 {
+		t.Fatalf("expected AND of 2 operands, got %+v", and)
+	}
+	gpl := and.Operands[1]
+	if gpl.License != "GPL-2.0" || !gpl.OrLater || gpl.Exception != "Classpath-exception-2.0" {
+		t.Errorf("unexpected license %+v", gpl)
+	}
+}
+
+func TestParseCanRoundTrip(t *testing.T) {
+	for in, want := range map[string]string{
+		"MIT":                                  "MIT",
+		"(MIT OR Apache-2.0) AND BSD-3-Clause": "(MIT OR Apache-2.0) AND BSD-3-Clause",
+		"((MIT))":                              "MIT",
+		"mit and (bsd-2-clause or isc)":        "mit AND (bsd-2-clause OR isc)",
+		"A AND B AND C":                        "A AND B AND C",
+		"DocumentRef-x:LicenseRef-y OR NONE":   "DocumentRef-x:LicenseRef-y OR NONE",
+		"GPL-2.0-only with Linux-syscall-note": "GPL-2.0-only WITH Linux-syscall-note",
+	} {
+		e, err := Parse(in)
+		if err != nil {
+			t.Errorf("%s: expected nil error, got %v", in, err)
+			continue
+		}
+		if e.String() != want {
+			t.Errorf("%s: expected %v, got %v", in, want, e.String())
+		}
+	}
+}
+
+func TestParseFailsOnInvalidExpressions(t *testing.T) {
+	for _, in := range []string{"", "MIT AND", "(MIT", "MIT)", "MIT Apache-2.0", "AND MIT", "MIT WITH", "(MIT OR ISC) WITH X", "M/T", "+"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%q: expected non-nil error, got nil", in)
+		}
+	}
+}
+
+func TestLicensesAreDistinctAndInOrder(t *testing.T) {
+	got, err := Licenses("(MIT OR GPL-2.0+) AND MIT AND GPL-2.0 WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := "[MIT GPL-2.0+ GPL-2.0]"; fmt.Sprint(got) != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	e, _ := Parse("GPL-2.0 WITH Classpath-exception-2.0 OR LGPL-2.1 WITH Classpath-exception-2.0")
+	if want := "[Classpath-exception-2.0]"; fmt.Sprint(e.Exceptions()) != want {
+		t.Errorf("expected %v, got %v", want, e.Exceptions())
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licenselist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+)
+
+// licenseIDs are commonly used IDs of the SPDX License List, including
+// deprecated ones. The list is not complete; see
+// https://spdx.org/licenses/ for the full list.
+var licenseIDs = []string{
+	"0BSD", "AAL", "AFL-1.1", "AFL-1.2", "AFL-2.0", "AFL-2.1", "AFL-3.0",
+	"AGPL-1.0", "AGPL-1.0-only", "AGPL-1.0-or-later", "AGPL-3.0",
+	"AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.0", "Apache-1.1",
+	"Apache-2.0", "APSL-2.0", "Artistic-1.0", "Artistic-1.0-Perl",
+	"Artistic-2.0", "Beerware", "BlueOak-1.0.0", "BSD-1-Clause",
+	"BSD-2-Clause", "BSD-2-Clause-FreeBSD", "BSD-2-Clause-NetBSD",
+	"BSD-2-Clause-Patent", "BSD-3-Clause", "BSD-3-Clause-Attribution",
+	"BSD-3-Clause-Clear", "BSD-3-Clause-LBNL", "BSD-4-Clause",
+	"BSD-4-Clause-UC", "BSD-Source-Code", "BSL-1.0", "BUSL-1.1",
+	"bzip2-1.0.5", "bzip2-1.0.6", "CAL-1.0", "CC-BY-1.0", "CC-BY-2.0",
+	"CC-BY-2.5", "CC-BY-3.0", "CC-BY-4.0", "CC-BY-NC-4.0",
+	"CC-BY-NC-ND-4.0", "CC-BY-NC-SA-4.0", "CC-BY-ND-4.0", "CC-BY-SA-2.0",
+	"CC-BY-SA-2.5", "CC-BY-SA-3.0", "CC-BY-SA-4.0", "CC-PDDC", "CC0-1.0",
+	"CDDL-1.0", "CDDL-1.1", "CDLA-Permissive-1.0", "CDLA-Permissive-2.0",
+	"CDLA-Sharing-1.0", "CECILL-2.0", "CECILL-2.1", "CECILL-B", "CECILL-C",
+	"ClArtistic", "CNRI-Python", "CPAL-1.0", "CPL-1.0", "curl", "ECL-2.0",
+	"eCos-2.0", "EFL-2.0", "EPL-1.0", "EPL-2.0", "EUPL-1.1", "EUPL-1.2",
+	"FSFAP", "FSFUL", "FSFULLR", "FTL", "GFDL-1.1", "GFDL-1.1-only",
+	"GFDL-1.1-or-later", "GFDL-1.2", "GFDL-1.2-only", "GFDL-1.2-or-later",
+	"GFDL-1.3", "GFDL-1.3-only", "GFDL-1.3-or-later", "GPL-1.0",
+	"GPL-1.0+", "GPL-1.0-only", "GPL-1.0-or-later", "GPL-2.0", "GPL-2.0+",
+	"GPL-2.0-only", "GPL-2.0-or-later", "GPL-2.0-with-autoconf-exception",
+	"GPL-2.0-with-bison-exception", "GPL-2.0-with-classpath-exception",
+	"GPL-2.0-with-font-exception", "GPL-2.0-with-GCC-exception",
+	"GPL-3.0", "GPL-3.0+", "GPL-3.0-only", "GPL-3.0-or-later",
+	"GPL-3.0-with-autoconf-exception", "GPL-3.0-with-GCC-exception",
+	"HPND", "ICU", "IJG", "Imlib2", "Info-ZIP", "Intel", "IPA", "IPL-1.0",
+	"ISC", "JasPer-2.0", "JSON", "LGPL-2.0", "LGPL-2.0+", "LGPL-2.0-only",
+	"LGPL-2.0-or-later", "LGPL-2.1", "LGPL-2.1+", "LGPL-2.1-only",
+	"LGPL-2.1-or-later", "LGPL-3.0", "LGPL-3.0+", "LGPL-3.0-only",
+	"LGPL-3.0-or-later", "LGPLLR", "Libpng", "libpng-2.0", "libtiff",
+	"LPL-1.02", "LPPL-1.3c", "MirOS", "MIT", "MIT-0", "MIT-CMU",
+	"MIT-advertising", "MIT-enna", "MIT-feh", "MIT-Modern-Variant",
+	"MITNFA", "MPL-1.0", "MPL-1.1", "MPL-2.0",
+	"MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL", "MulanPSL-2.0",
+	"NCSA", "Net-SNMP", "NTP", "Nunit", "ODbL-1.0", "OFL-1.0", "OFL-1.1",
+	"OGL-UK-3.0", "OLDAP-2.8", "OpenSSL", "OSL-1.0", "OSL-2.0", "OSL-2.1",
+	"OSL-3.0", "PDDL-1.0", "PHP-3.0", "PHP-3.01", "PostgreSQL", "PSF-2.0",
+	"Python-2.0", "Python-2.0.1", "Qhull", "QPL-1.0", "Ruby", "SGI-B-2.0",
+	"SISSL", "Sleepycat", "SMLNJ", "SSPL-1.0", "StandardML-NJ", "TCL",
+	"UCL-1.0", "Unicode-3.0", "Unicode-DFS-2015", "Unicode-DFS-2016",
+	"Unlicense", "UPL-1.0", "Vim", "W3C", "W3C-20150513", "WTFPL",
+	"wxWindows", "X11", "XFree86-1.1", "Xnet", "xpp", "Zed", "Zend-2.0",
+	"Zlib", "zlib-acknowledgement", "ZPL-1.1", "ZPL-2.0", "ZPL-2.1",
+}
+
+// exceptionIDs are commonly used IDs of the SPDX License Exceptions List.
+var exceptionIDs = []string{
+	"389-exception", "Autoconf-exception-2.0", "Autoconf-exception-3.0",
+	"Bison-exception-2.2", "Bootloader-exception", "Classpath-exception-2.0",
+	"CLISP-exception-2.0", "DigiRule-FOSS-exception", "eCos-exception-2.0",
+	"Fawkes-Runtime-exception", "FLTK-exception", "Font-exception-2.0",
+	"freertos-exception-2.0", "GCC-exception-2.0", "GCC-exception-3.1",
+	"gnu-javamail-exception", "GPL-3.0-linking-exception",
+	"GPL-3.0-linking-source-exception", "GPL-CC-1.0", "i2p-gpl-java-exception",
+	"LGPL-3.0-linking-exception", "Libtool-exception", "Linux-syscall-note",
+	"LLVM-exception", "LZMA-exception", "mif-exception", "OCaml-LGPL-linking-exception",
+	"OCCT-exception-1.0", "OpenJDK-assembly-exception-1.0", "openvpn-openssl-exception",
+	"PS-or-PDF-font-exception-20170817", "Qt-GPL-exception-1.0",
+	"Qt-LGPL-exception-1.1", "Qwt-exception-1.0", "SHL-2.0", "SHL-2.1",
+	"Swift-exception", "u-boot-exception-2.0", "Universal-FOSS-exception-1.0",
+	"WxWindows-exception-3.1",
+}
+
+// deprecated maps deprecated license IDs to the expressions replacing
+// them. An ID followed by "+" is replaced when it is followed by "+".
+var deprecated = map[string]string{
+	"AGPL-1.0":                         "AGPL-1.0-only",
+	"AGPL-3.0":                         "AGPL-3.0-only",
+	"BSD-2-Clause-FreeBSD":             "BSD-2-Clause",
+	"BSD-2-Clause-NetBSD":              "BSD-2-Clause",
+	"bzip2-1.0.5":                      "bzip2-1.0.6",
+	"eCos-2.0":                         "GPL-2.0-or-later WITH eCos-exception-2.0",
+	"GFDL-1.1":                         "GFDL-1.1-only",
+	"GFDL-1.2":                         "GFDL-1.2-only",
+	"GFDL-1.3":                         "GFDL-1.3-only",
+	"GPL-1.0":                          "GPL-1.0-only",
+	"GPL-1.0+":                         "GPL-1.0-or-later",
+	"GPL-2.0":                          "GPL-2.0-only",
+	"GPL-2.0+":                         "GPL-2.0-or-later",
+	"GPL-2.0-with-autoconf-exception":  "GPL-2.0-only WITH Autoconf-exception-2.0",
+	"GPL-2.0-with-bison-exception":     "GPL-2.0-or-later WITH Bison-exception-2.2",
+	"GPL-2.0-with-classpath-exception": "GPL-2.0-only WITH Classpath-exception-2.0",
+	"GPL-2.0-with-font-exception":      "GPL-2.0-only WITH Font-exception-2.0",
+	"GPL-2.0-with-GCC-exception":       "GPL-2.0-only WITH GCC-exception-2.0",
+	"GPL-3.0":                          "GPL-3.0-only",
+	"GPL-3.0+":                         "GPL-3.0-or-later",
+	"GPL-3.0-with-autoconf-exception":  "GPL-3.0-only WITH Autoconf-exception-3.0",
+	"GPL-3.0-with-GCC-exception":       "GPL-3.0-only WITH GCC-exception-3.1",
+	"LGPL-2.0":                         "LGPL-2.0-only",
+	"LGPL-2.0+":                        "LGPL-2.0-or-later",
+	"LGPL-2.1":                         "LGPL-2.1-only",
+	"LGPL-2.1+":                        "LGPL-2.1-or-later",
+	"LGPL-3.0":                         "LGPL-3.0-only",
+	"LGPL-3.0+":                        "LGPL-3.0-or-later",
+	"Nunit":                            "zlib-acknowledgement",
+	"StandardML-NJ":                    "SMLNJ",
+	"wxWindows":                        "GPL-2.0-or-later WITH WxWindows-exception-3.1",
+}
+
+var licenseIDsByLower, exceptionIDsByLower = lowerIndex(licenseIDs), lowerIndex(exceptionIDs)
+
+func lowerIndex(ids []string) map[string]string {
+	index := map[string]string{}
+	for _, id := range ids {
+		index[strings.ToLower(id)] = id
+	}
+	return index
+}
+
+// LicenseID returns the listed license ID matching id regardless of case,
+// if it is known.
+func LicenseID(id string) (string, bool) {
+	listed, ok := licenseIDsByLower[strings.ToLower(id)]
+	return listed, ok
+}
+
+// ExceptionID returns the listed exception ID matching id regardless of
+// case, if it is known.
+func ExceptionID(id string) (string, bool) {
+	listed, ok := exceptionIDsByLower[strings.ToLower(id)]
+	return listed, ok
+}
+
+// Replacement returns the expression replacing the deprecated license ID
+// id, which may end with "+", if it is deprecated.
+func Replacement(id string) (string, bool) {
+	if listed, ok := LicenseID(strings.TrimSuffix(id, "+")); ok {
+		if strings.HasSuffix(id, "+") {
+			listed += "+"
+		}
+		id = listed
+	}
+	replacement, ok := deprecated[id]
+	return replacement, ok
+}
+
+// Update rewrites the licenses of e in place: it changes the case of
+// listed license and exception IDs to that of the list, and replaces
+// deprecated license IDs, such as GPL-2.0+ by GPL-2.0-or-later and
+// GPL-2.0-with-classpath-exception by "GPL-2.0-only WITH
+// Classpath-exception-2.0". It returns a description of each change, such
+// as "GPL-2.0 -> GPL-2.0-only". A deprecated license with an exception is
+// not replaced by one with an exception of its own.
+func Update(e *licenseexpr.Expression) []string {
+	changes := []string{}
+	e.Walk(func(l *licenseexpr.Expression) {
+		old := l.String()
+		if id, ok := LicenseID(l.License); ok {
+			l.License = id
+		}
+		if id, ok := ExceptionID(l.Exception); ok {
+			l.Exception = id
+		}
+		if replacement, ok := Replacement(l.LicenseID()); ok {
+			if r, err := licenseexpr.Parse(replacement); err == nil && (r.Exception == "" || l.Exception == "") {
+				l.License, l.OrLater = r.License, r.OrLater
+				if r.Exception != "" {
+					l.Exception = r.Exception
+				}
+			}
+		}
+		if updated := l.String(); updated != old {
+			changes = append(changes, fmt.Sprintf("%s -> %s", old, updated))
+		}
+	})
+	return changes
+}
@@ -0,0 +1,77 @@
+// Package licenselist holds data from the SPDX License List, such as the
+// texts of some commonly used short licenses.
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+package licenselist
+
+import (
+	"embed"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed texts/*.txt
+var textFiles embed.FS
+
+// names are the full names of the licenses whose texts are embedded.
+var names = map[string]string{
+	"0BSD":         "BSD Zero Clause License",
+	"BSD-2-Clause": `BSD 2-Clause "Simplified" License`,
+	"BSD-3-Clause": `BSD 3-Clause "New" or "Revised" License`,
+	"BSL-1.0":      "Boost Software License 1.0",
+	"ISC":          "ISC License",
+	"MIT":          "MIT License",
+	"Zlib":         "zlib License",
+}
+
+// Name returns the full name of the listed license id, if known.
+func Name(id string) string {
+	return names[id]
+}
+
+// Text returns the text of the listed license id, without a copyright
+// line, if it is one of those in TextIDs.
+func Text(id string) (string, bool) {
+	text, err := textFiles.ReadFile("texts/" + id + ".txt")
+	if err != nil {
+		return "", false
+	}
+	return string(text), true
+}
+
+// TextIDs returns the sorted IDs of the licenses whose texts are known.
+func TextIDs() []string {
+	ids := []string{}
+	entries, _ := textFiles.ReadDir("texts")
+	for _, e := range entries {
+		ids = append(ids, strings.TrimSuffix(e.Name(), ".txt"))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Match returns the ID of the listed license whose text is contained in
+// text, ignoring case, punctuation and whitespace, so that a copyright line
+// or other preamble may come before it. If several are contained, the
+// longest is returned.
+func Match(text string) (string, bool) {
+	normalized := normalizeText(text)
+	match, matchLen := "", 0
+	for _, id := range TextIDs() {
+		listed, _ := Text(id)
+		listed = normalizeText(listed)
+		if len(listed) > matchLen && strings.Contains(normalized, listed) {
+			match, matchLen = id, len(listed)
+		}
+	}
+	return match, match != ""
+}
+
+// normalizeText lowers the case of text and keeps only its letters and
+// digits, separated by single spaces.
+func normalizeText(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
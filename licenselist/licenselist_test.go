@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package licenselist
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+)
+
+// ===== License text tests =====
+func TestTextsHaveNames(t *testing.T) {
+	for _, id := range TextIDs() {
+		if Name(id) == "" {
+			t.Errorf("%s: expected a name", id)
+		}
+		if text, ok := Text(id); !ok || text == "" {
+			t.Errorf("%s: expected a text", id)
+		}
+	}
+	if _, ok := Text("GPL-2.0-only"); ok {
+		t.Errorf("expected no text for GPL-2.0-only")
+	}
+}
+
+func TestMatchFindsListedTexts(t *testing.T) {
+	for _, id := range TextIDs() {
+		text, _ := Text(id)
+		// reflowed, with a copyright line and different punctuation
+		text = "Copyright (c) 2024 Someone\n" + strings.Join(strings.Fields(strings.ReplaceAll(text, `"`, "'")), " ")
+		if got, ok := Match(text); !ok || got != id {
+			t.Errorf("expected %v, got %v", id, got)
+		}
+	}
+	if got, ok := Match("All rights reserved."); ok {
+		t.Errorf("expected no match, got %v", got)
+	}
+}
+
+// ===== License ID tests =====
+func TestReplacementHandlesOrLater(t *testing.T) {
+	for id, want := range map[string]string{
+		"GPL-2.0":      "GPL-2.0-only",
+		"gpl-2.0+":     "GPL-2.0-or-later",
+		"LGPL-3.0+":    "LGPL-3.0-or-later",
+		"AGPL-3.0":     "AGPL-3.0-only",
+		"MIT":          "",
+		"MIT+":         "",
+		"GPL-2.0-only": "",
+	} {
+		if got, _ := Replacement(id); got != want {
+			t.Errorf("%s: expected %v, got %v", id, want, got)
+		}
+	}
+}
+
+func TestUpdateKeepsExistingExceptions(t *testing.T) {
+	e, _ := licenseexpr.Parse("GPL-2.0-with-GCC-exception WITH Classpath-exception-2.0 OR gpl-3.0 WITH llvm-exception")
+	changes := Update(e)
+	if want := "GPL-2.0-with-GCC-exception WITH Classpath-exception-2.0 OR GPL-3.0-only WITH LLVM-exception"; e.String() != want {
+		t.Errorf("expected %v, got %v", want, e.String())
+	}
+	if want := "[gpl-3.0 WITH llvm-exception -> GPL-3.0-only WITH LLVM-exception]"; fmt.Sprint(changes) != want {
+		t.Errorf("expected %v, got %v", want, changes)
+	}
+}
@@ -0,0 +1,249 @@
+// Package notice generates a third-party notices file, such as a NOTICE
+// or ATTRIBUTION file, from an SPDX Document, with the copyright and
+// attribution texts of its packages grouped by license, followed by the
+// texts of those licenses.
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+package notice
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/licenselist"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// Entry is a package, or a file outside of any package, in a notices file.
+type Entry struct {
+	SPDXIdentifier common.ElementID
+	Name           string
+	Version        string
+	HomePage       string
+	// Copyrights are the distinct copyright texts of the package and its
+	// files, leaving out NONE and NOASSERTION.
+	Copyrights []string
+	// AttributionTexts are the distinct attribution texts of the package
+	// and its files.
+	AttributionTexts []string
+	// Notices are the distinct notice texts of the package's files.
+	Notices []string
+}
+
+// Group is the entries under a license expression.
+type Group struct {
+	License string
+	Entries []Entry
+}
+
+// License is a license referred to by the groups of a notices file.
+type License struct {
+	ID   string
+	Name string
+	// Text is the license's text, from the document's OtherLicenses or
+	// the texts of the licenselist package. If it is empty, URL may be set
+	// to where the text of an SPDX-listed license can be found.
+	Text string
+	URL  string
+}
+
+// Notices is the content of a notices file.
+type Notices struct {
+	DocumentName string
+	// Groups is sorted by license expression.
+	Groups []Group
+	// Licenses is sorted by ID.
+	Licenses []License
+}
+
+// Format is the format of a notices file.
+type Format string
+
+// The formats of notices files
+const (
+	Text     Format = "text"
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+)
+
+// Options configures Write.
+type Options struct {
+	// Format is the format of the notices file; it defaults to Text.
+	Format Format
+	// Template, if set, is executed with the Notices instead of the
+	// template for Format.
+	Template *template.Template
+	// LicenseTexts are the texts of licenses by ID, used ahead of those
+	// of the licenselist package but after those of the document.
+	LicenseTexts map[string]string
+}
+
+// Make collects the Notices of doc. A package is grouped under its
+// concluded license or, if that is NONE or NOASSERTION, its declared
+// license.
+func Make(doc *spdx.Document, opts *Options) (*Notices, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	groups := map[string]*Group{}
+	add := func(license string, e Entry) {
+		if groups[license] == nil {
+			groups[license] = &Group{License: license}
+		}
+		groups[license].Entries = append(groups[license].Entries, e)
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		e := Entry{
+			SPDXIdentifier: pkg.PackageSPDXIdentifier,
+			Name:           pkg.PackageName,
+			Version:        pkg.PackageVersion,
+			HomePage:       pkg.PackageHomePage,
+		}
+		e.addCopyright(pkg.PackageCopyrightText)
+		e.AttributionTexts = appendDistinct(e.AttributionTexts, pkg.PackageAttributionTexts...)
+		for _, f := range pkg.Files {
+			if f != nil {
+				e.addFile(f)
+			}
+		}
+		license := pkg.PackageLicenseConcluded
+		if !known(license) {
+			license = pkg.PackageLicenseDeclared
+		}
+		add(groupLicense(license), e)
+	}
+	for _, f := range doc.Files {
+		if f == nil {
+			continue
+		}
+		e := Entry{SPDXIdentifier: f.FileSPDXIdentifier, Name: f.FileName}
+		e.addFile(f)
+		add(groupLicense(f.LicenseConcluded), e)
+	}
+
+	n := &Notices{DocumentName: doc.DocumentName}
+	for _, g := range groups {
+		n.Groups = append(n.Groups, *g)
+	}
+	sort.Slice(n.Groups, func(i, j int) bool { return n.Groups[i].License < n.Groups[j].License })
+
+	otherLicenses := map[string]*spdx.OtherLicense{}
+	for _, lic := range doc.OtherLicenses {
+		if lic != nil {
+			otherLicenses[lic.LicenseIdentifier] = lic
+		}
+	}
+	ids := []string{}
+	for _, g := range n.Groups {
+		if expr, err := licenseexpr.Parse(g.License); err == nil {
+			expr.Walk(func(l *licenseexpr.Expression) {
+				if known(l.License) {
+					ids = appendDistinct(ids, l.License)
+				}
+			})
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		n.Licenses = append(n.Licenses, findLicense(id, otherLicenses[id], opts.LicenseTexts))
+	}
+	return n, nil
+}
+
+// Write writes the notices file of doc to w.
+func Write(doc *spdx.Document, w io.Writer, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	n, err := Make(doc, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Template != nil {
+		return opts.Template.Execute(w, n)
+	}
+	format := opts.Format
+	if format == "" {
+		format = Text
+	}
+	tmpl := templates[format]
+	if tmpl == nil {
+		return fmt.Errorf("unknown notices format %q", format)
+	}
+	return tmpl.Execute(w, n)
+}
+
+func (e *Entry) addFile(f *spdx.File) {
+	e.addCopyright(f.FileCopyrightText)
+	e.AttributionTexts = appendDistinct(e.AttributionTexts, f.FileAttributionTexts...)
+	if f.FileNotice != "" {
+		e.Notices = appendDistinct(e.Notices, f.FileNotice)
+	}
+}
+
+func (e *Entry) addCopyright(text string) {
+	if known(text) {
+		e.Copyrights = appendDistinct(e.Copyrights, strings.TrimSpace(text))
+	}
+}
+
+func findLicense(id string, other *spdx.OtherLicense, texts map[string]string) License {
+	lic := License{ID: id, Name: licenselist.Name(id)}
+	switch {
+	case other != nil:
+		lic.Text = other.ExtractedText
+		if other.LicenseName != "" && other.LicenseName != "NOASSERTION" {
+			lic.Name = other.LicenseName
+		}
+	case texts[id] != "":
+		lic.Text = texts[id]
+	default:
+		lic.Text, _ = licenselist.Text(id)
+	}
+	if lic.Text == "" && !strings.Contains(id, "LicenseRef-") {
+		lic.URL = "https://spdx.org/licenses/" + id + ".html"
+	}
+	lic.Text = strings.TrimRight(lic.Text, "\n")
+	return lic
+}
+
+// groupLicense returns the license expression to group an entry under,
+// normalized if it is valid, so that "MIT OR ISC" and "ISC OR MIT" are
+// grouped together.
+func groupLicense(expr string) string {
+	if !known(expr) {
+		return "NOASSERTION"
+	}
+	if e, err := licenseexpr.Parse(expr); err == nil {
+		return e.Normalize().String()
+	}
+	return expr
+}
+
+func known(s string) bool {
+	return s != "" && s != "NONE" && s != "NOASSERTION"
+}
+
+func appendDistinct(values []string, add ...string) []string {
+	for _, a := range add {
+		found := false
+		for _, v := range values {
+			if v == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, a)
+		}
+	}
+	return values
+}
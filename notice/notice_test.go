@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package notice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+)
+
+func makeNoticeTestDocument() *spdx.Document {
+	return &spdx.Document{
+		DocumentName: "product-1.0",
+		Packages: []*spdx.Package{
+			{
+				PackageSPDXIdentifier:   "Package-zlib",
+				PackageName:             "zlib",
+				PackageVersion:          "1.3",
+				PackageHomePage:         "https://zlib.net",
+				PackageLicenseConcluded: "Zlib",
+				PackageCopyrightText:    "Copyright (C) 1995-2023 Jean-loup Gailly and Mark Adler",
+			},
+			{
+				PackageSPDXIdentifier:   "Package-a",
+				PackageName:             "a",
+				PackageLicenseConcluded: "NOASSERTION",
+				PackageLicenseDeclared:  "MIT  OR  LicenseRef-custom",
+				PackageCopyrightText:    "NOASSERTION",
+				PackageAttributionTexts: []string{"Includes software from a"},
+				Files: []*spdx.File{
+					{FileName: "./a.c", FileCopyrightText: "Copyright (c) A", FileNotice: "a notice"},
+					{FileName: "./b.c", FileCopyrightText: "Copyright (c) A", FileAttributionTexts: []string{"Includes software from a"}},
+				},
+			},
+			{
+				PackageSPDXIdentifier:   "Package-b",
+				PackageName:             "b",
+				PackageLicenseConcluded: "(LicenseRef-custom OR MIT)",
+				PackageCopyrightText:    "Copyright (c) B",
+			},
+			{
+				PackageSPDXIdentifier:   "Package-c",
+				PackageName:             "c",
+				PackageLicenseConcluded: "GPL-2.0-only",
+			},
+		},
+		Files: []*spdx.File{
+			{FileSPDXIdentifier: "File-x", FileName: "./x.sh", LicenseConcluded: "NONE", FileCopyrightText: "NONE"},
+		},
+		OtherLicenses: []*spdx.OtherLicense{
+			{LicenseIdentifier: "LicenseRef-custom", ExtractedText: "Custom license text\n", LicenseName: "Custom"},
+		},
+	}
+}
+
+// ===== Notices tests =====
+func TestMakeGroupsEntriesByLicense(t *testing.T) {
+	n, err := Make(makeNoticeTestDocument(), nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	groups := []string{}
+	for _, g := range n.Groups {
+		names := []string{}
+		for _, e := range g.Entries {
+			names = append(names, e.Name)
+		}
+		groups = append(groups, fmt.Sprintf("%s=%v", g.License, names))
+	}
+	want := "[GPL-2.0-only=[c] LicenseRef-custom OR MIT=[a b] NOASSERTION=[./x.sh] Zlib=[zlib]]"
+	if fmt.Sprint(groups) != want {
+		t.Errorf("expected %v, got %v", want, groups)
+	}
+
+	a := n.Groups[1].Entries[0]
+	if fmt.Sprint(a.Copyrights) != "[Copyright (c) A]" || fmt.Sprint(a.AttributionTexts) != "[Includes software from a]" || fmt.Sprint(a.Notices) != "[a notice]" {
+		t.Errorf("unexpected entry %+v", a)
+	}
+	if x := n.Groups[2].Entries[0]; x.Copyrights != nil {
+		t.Errorf("expected no copyrights, got %v", x.Copyrights)
+	}
+}
+
+func TestMakeFindsLicenseTexts(t *testing.T) {
+	n, _ := Make(makeNoticeTestDocument(), &Options{LicenseTexts: map[string]string{"Zlib": "our zlib text"}})
+	licenses := map[string]License{}
+	ids := []string{}
+	for _, lic := range n.Licenses {
+		licenses[lic.ID] = lic
+		ids = append(ids, lic.ID)
+	}
+	if want := "[GPL-2.0-only LicenseRef-custom MIT Zlib]"; fmt.Sprint(ids) != want {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+	if lic := licenses["LicenseRef-custom"]; lic.Text != "Custom license text" || lic.Name != "Custom" {
+		t.Errorf("unexpected license %+v", lic)
+	}
+	if lic := licenses["MIT"]; !strings.HasPrefix(lic.Text, "Permission is hereby granted") || lic.Name != "MIT License" {
+		t.Errorf("unexpected license %+v", lic)
+	}
+	if lic := licenses["Zlib"]; lic.Text != "our zlib text" {
+		t.Errorf("expected %v, got %v", "our zlib text", lic.Text)
+	}
+	if lic := licenses["GPL-2.0-only"]; lic.Text != "" || lic.URL != "https://spdx.org/licenses/GPL-2.0-only.html" {
+		t.Errorf("unexpected license %+v", lic)
+	}
+}
+
+// ===== Output tests =====
+func TestWriteRendersFormats(t *testing.T) {
+	for format, wants := range map[Format][]string{
+		Text: {
+			"THIRD-PARTY SOFTWARE NOTICES\n\nproduct-1.0\n",
+			"zlib 1.3\n  https://zlib.net\n  Copyright (C) 1995-2023 Jean-loup Gailly and Mark Adler\n",
+			"a\n  Copyright (c) A\n\n  Includes software from a\n\n  a notice\n",
+			"--- LicenseRef-custom: Custom ---\n\nCustom license text\n",
+			"See https://spdx.org/licenses/GPL-2.0-only.html\n",
+		},
+		Markdown: {
+			"# Third-party software notices\n",
+			"## LicenseRef-custom OR MIT\n\n### a\n\n```\nCopyright (c) A\n```\n",
+			"### zlib 1.3\n\n<https://zlib.net>\n",
+			"### LicenseRef-custom: Custom\n\n```\nCustom license text\n```\n",
+		},
+		HTML: {
+			"<h2>LicenseRef-custom OR MIT</h2>\n<h3>a</h3>\n<pre>Copyright (c) A</pre>\n",
+			"<h3>./x.sh</h3>\n",
+			"<p>See <a href=\"https://spdx.org/licenses/GPL-2.0-only.html\">",
+		},
+	} {
+		var got bytes.Buffer
+		if err := Write(makeNoticeTestDocument(), &got, &Options{Format: format}); err != nil {
+			t.Fatalf("%s: expected nil error, got %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(got.String(), want) {
+				t.Errorf("%s: expected output to contain %q, got %v", format, want, got.String())
+			}
+		}
+	}
+}
+
+func TestWriteEscapesHTML(t *testing.T) {
+	doc := &spdx.Document{Packages: []*spdx.Package{
+		{PackageName: "<b>", PackageLicenseConcluded: "MIT"},
+		{PackageName: "c", PackageLicenseConcluded: "MIT", PackageHomePage: "javascript:alert(1)"},
+		{PackageName: "d", PackageLicenseConcluded: "MIT", PackageHomePage: "https://example.com/d?a=1&b=2"},
+	}}
+	var got bytes.Buffer
+	if err := Write(doc, &got, &Options{Format: HTML}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(got.String(), "<h3>&lt;b&gt;</h3>") {
+		t.Errorf("expected escaped package name, got %v", got.String())
+	}
+	// unsafe URLs are not linked to
+	if strings.Contains(got.String(), `href="javascript:`) || !strings.Contains(got.String(), `<a href="#ZgotmplZ">javascript:alert(1)</a>`) {
+		t.Errorf("expected javascript: home page not to be linked, got %v", got.String())
+	}
+	if !strings.Contains(got.String(), `<a href="https://example.com/d?a=1&amp;b=2">`) {
+		t.Errorf("expected home page link, got %v", got.String())
+	}
+}
+
+func TestWriteCanUseCustomTemplate(t *testing.T) {
+	tmpl, err := NewTemplate("custom", `{{range .Groups}}{{.License}}: {{range .Entries}}{{.Name}} {{end}}{{join (index .Entries 0).Copyrights ", "}}
+{{end}}`)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var got bytes.Buffer
+	if err := Write(makeNoticeTestDocument(), &got, &Options{Template: tmpl}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := "GPL-2.0-only: c \nLicenseRef-custom OR MIT: a b Copyright (c) A\nNOASSERTION: ./x.sh \nZlib: zlib Copyright (C) 1995-2023 Jean-loup Gailly and Mark Adler\n"
+	if got.String() != want {
+		t.Errorf("expected %q, got %q", want, got.String())
+	}
+}
+
+func TestWriteFailsOnUnknownFormat(t *testing.T) {
+	if err := Write(makeNoticeTestDocument(), &bytes.Buffer{}, &Options{Format: "pdf"}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
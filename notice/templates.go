@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package notice
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// funcs are available to the templates, including custom ones made with
+// NewTemplate.
+var funcs = template.FuncMap{
+	"join":   strings.Join,
+	"indent": indent,
+}
+
+// executor is a text or HTML template.
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// NewTemplate parses text as a template for Options.Template. The template
+// is executed with a *Notices, and can call "join", as strings.Join, and
+// "indent", which prefixes each line of a text with a number of spaces.
+func NewTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Parse(text)
+}
+
+func indent(spaces int, text string) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+const textTemplate = `THIRD-PARTY SOFTWARE NOTICES
+{{if .DocumentName}}
+{{.DocumentName}}
+{{end}}
+{{- range .Groups}}
+================================================================================
+{{.License}}
+================================================================================
+{{range .Entries}}
+{{.Name}}{{if .Version}} {{.Version}}{{end}}
+{{- if .HomePage}}
+  {{.HomePage}}{{end}}
+{{- range .Copyrights}}
+{{indent 2 .}}{{end}}
+{{- range .AttributionTexts}}
+
+{{indent 2 .}}{{end}}
+{{- range .Notices}}
+
+{{indent 2 .}}{{end}}
+{{end}}{{end}}
+{{- if .Licenses}}
+================================================================================
+LICENSES
+================================================================================
+{{range .Licenses}}
+--- {{.ID}}{{if .Name}}: {{.Name}}{{end}} ---
+
+{{if .Text}}{{.Text}}{{else if .URL}}See {{.URL}}{{else}}No license text available.{{end}}
+{{end}}{{end}}`
+
+const markdownTemplate = `# Third-party software notices
+{{if .DocumentName}}
+{{.DocumentName}}
+{{end}}
+{{- range .Groups}}
+## {{.License}}
+{{range .Entries}}
+### {{.Name}}{{if .Version}} {{.Version}}{{end}}
+{{if .HomePage}}
+<{{.HomePage}}>
+{{end}}
+{{- if .Copyrights}}
+` + "```" + `
+{{join .Copyrights "\n"}}
+` + "```" + `
+{{end}}
+{{- range .AttributionTexts}}
+` + "```" + `
+{{.}}
+` + "```" + `
+{{end}}
+{{- range .Notices}}
+` + "```" + `
+{{.}}
+` + "```" + `
+{{end}}{{end}}{{end}}
+{{- if .Licenses}}
+## Licenses
+{{range .Licenses}}
+### {{.ID}}{{if .Name}}: {{.Name}}{{end}}
+
+{{if .Text}}` + "```" + `
+{{.Text}}
+` + "```" + `{{else if .URL}}See <{{.URL}}>.{{else}}No license text available.{{end}}
+{{end}}{{end}}`
+
+// htmlTemplate is executed as an html/template, which escapes each value
+// for its context, and replaces URLs with unsafe schemes, such as a home
+// page of "javascript:...", by "#ZgotmplZ".
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Third-party software notices</title>
+</head>
+<body>
+<h1>Third-party software notices</h1>
+{{- if .DocumentName}}
+<p>{{.DocumentName}}</p>
+{{- end}}
+{{- range .Groups}}
+<h2>{{.License}}</h2>
+{{- range .Entries}}
+<h3>{{.Name}}{{if .Version}} {{.Version}}{{end}}</h3>
+{{- if .HomePage}}
+<p><a href="{{.HomePage}}">{{.HomePage}}</a></p>
+{{- end}}
+{{- range .Copyrights}}
+<pre>{{.}}</pre>
+{{- end}}
+{{- range .AttributionTexts}}
+<pre>{{.}}</pre>
+{{- end}}
+{{- range .Notices}}
+<pre>{{.}}</pre>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Licenses}}
+<h2>Licenses</h2>
+{{- range .Licenses}}
+<h3 id="{{.ID}}">{{.ID}}{{if .Name}}: {{.Name}}{{end}}</h3>
+{{- if .Text}}
+<pre>{{.Text}}</pre>
+{{- else if .URL}}
+<p>See <a href="{{.URL}}">{{.URL}}</a>.</p>
+{{- else}}
+<p>No license text available.</p>
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
+
+var templates = map[Format]executor{
+	Text:     template.Must(NewTemplate("text", textTemplate)),
+	Markdown: template.Must(NewTemplate("markdown", markdownTemplate)),
+	HTML:     htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap(funcs)).Parse(htmlTemplate)),
+}
@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package reporter
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// LicenseCount is the number of files under a license.
+type LicenseCount struct {
+	License string `json:"license"`
+	Count   int    `json:"count"`
+}
+
+// Counts counts the files of a set with and without a concluded license,
+// and the files under each license ID used by their LicenseConcluded
+// expressions. A file concluded "MIT OR Apache-2.0" counts for both MIT
+// and Apache-2.0, so the Licenses counts can add up to more than Found.
+type Counts struct {
+	Found    int `json:"found"`
+	NotFound int `json:"notFound"`
+	// Licenses is sorted by decreasing count, then by license ID.
+	Licenses []LicenseCount `json:"licenses"`
+}
+
+// PackageReport is the license report of a Package.
+type PackageReport struct {
+	SPDXIdentifier   common.ElementID `json:"SPDXID"`
+	Name             string           `json:"name"`
+	Version          string           `json:"version,omitempty"`
+	FilesAnalyzed    bool             `json:"filesAnalyzed"`
+	LicenseConcluded string           `json:"licenseConcluded"`
+	LicenseDeclared  string           `json:"licenseDeclared"`
+	// Files counts the package's files; it is empty if its files have
+	// not been analyzed.
+	Files Counts `json:"files"`
+	// LicenseMismatch is set if both licenses are known and the concluded
+	// license is not a choice of the declared license: if some choice of
+	// the concluded license does not satisfy the declared license, or uses
+	// a license the declared license does not. A concluded "MIT" is a
+	// choice of a declared "MIT OR Apache-2.0", but a concluded "MIT AND
+	// Apache-2.0" is not a choice of a declared "MIT".
+	LicenseMismatch bool `json:"licenseMismatch,omitempty"`
+	// ConcludedNotDeclared and DeclaredNotConcluded are the license IDs of
+	// the concluded license missing from the declared license and the
+	// other way around. They are only set if LicenseMismatch is.
+	ConcludedNotDeclared []string `json:"concludedNotDeclared,omitempty"`
+	DeclaredNotConcluded []string `json:"declaredNotConcluded,omitempty"`
+}
+
+// Report is the license report of a Document.
+type Report struct {
+	DocumentName string          `json:"documentName"`
+	Packages     []PackageReport `json:"packages"`
+	// Unpackaged counts the files of the document outside of any package.
+	Unpackaged Counts `json:"unpackaged"`
+	// Total counts all of the files of the document.
+	Total Counts `json:"total"`
+	// PackageLicenses counts the packages under each license ID used by
+	// their LicenseConcluded expressions.
+	PackageLicenses []LicenseCount `json:"packageLicenses"`
+	// InvalidExpressions lists the license expressions that could not be
+	// parsed. Each is counted as a single license.
+	InvalidExpressions []string `json:"invalidExpressions,omitempty"`
+}
+
+// MakeReport creates the license Report of doc. Unlike Generate, it
+// includes packages whose files have not been analyzed, reporting only
+// their package-level licenses.
+func MakeReport(doc *spdx.Document) (*Report, error) {
+	c := &counter{invalid: map[string]bool{}}
+	report := &Report{DocumentName: doc.DocumentName}
+	total := tally{}
+	packageLicenses := map[string]int{}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		pr := PackageReport{
+			SPDXIdentifier:   pkg.PackageSPDXIdentifier,
+			Name:             pkg.PackageName,
+			Version:          pkg.PackageVersion,
+			FilesAnalyzed:    pkg.FilesAnalyzed,
+			LicenseConcluded: pkg.PackageLicenseConcluded,
+			LicenseDeclared:  pkg.PackageLicenseDeclared,
+		}
+		files := tally{}
+		for _, f := range pkg.Files {
+			if f != nil {
+				c.countFile(f, &files, &total)
+			}
+		}
+		pr.Files = files.counts()
+		if known(pkg.PackageLicenseConcluded) {
+			for _, id := range c.licenses(pkg.PackageLicenseConcluded) {
+				packageLicenses[id]++
+			}
+		}
+		if known(pkg.PackageLicenseConcluded) && known(pkg.PackageLicenseDeclared) {
+			concluded := c.licenses(pkg.PackageLicenseConcluded)
+			declared := c.licenses(pkg.PackageLicenseDeclared)
+			isChoice, err := isChoiceOf(pkg.PackageLicenseConcluded, pkg.PackageLicenseDeclared)
+			if err != nil {
+				isChoice = missing(concluded, declared) == nil && missing(declared, concluded) == nil
+			}
+			if !isChoice {
+				pr.LicenseMismatch = true
+				pr.ConcludedNotDeclared = missing(concluded, declared)
+				pr.DeclaredNotConcluded = missing(declared, concluded)
+			}
+		}
+		report.Packages = append(report.Packages, pr)
+	}
+
+	unpackaged := tally{}
+	for _, f := range doc.Files {
+		if f != nil {
+			c.countFile(f, &unpackaged, &total)
+		}
+	}
+	report.Unpackaged = unpackaged.counts()
+	report.Total = total.counts()
+	report.PackageLicenses = sortCounts(packageLicenses)
+	for expr := range c.invalid {
+		report.InvalidExpressions = append(report.InvalidExpressions, expr)
+	}
+	sort.Strings(report.InvalidExpressions)
+	return report, nil
+}
+
+// GenerateDocument creates the license Report of doc and renders it to w.
+// If r is nil, the report is rendered as text.
+func GenerateDocument(doc *spdx.Document, w io.Writer, r Renderer) error {
+	report, err := MakeReport(doc)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		r = TextRenderer{}
+	}
+	return r.Render(report, w)
+}
+
+// tally accumulates Counts.
+type tally struct {
+	found, notFound int
+	licenses        map[string]int
+}
+
+func (t *tally) add(ids []string) {
+	if ids == nil {
+		t.notFound++
+		return
+	}
+	t.found++
+	if t.licenses == nil {
+		t.licenses = map[string]int{}
+	}
+	for _, id := range ids {
+		t.licenses[id]++
+	}
+}
+
+func (t *tally) counts() Counts {
+	return Counts{Found: t.found, NotFound: t.notFound, Licenses: sortCounts(t.licenses)}
+}
+
+// counter splits license expressions into license IDs, remembering those
+// that are invalid.
+type counter struct {
+	invalid map[string]bool
+}
+
+// countFile adds f to each of the tallies.
+func (c *counter) countFile(f *spdx.File, tallies ...*tally) {
+	var ids []string
+	if known(f.LicenseConcluded) {
+		ids = c.licenses(f.LicenseConcluded)
+	}
+	for _, t := range tallies {
+		t.add(ids)
+	}
+}
+
+func (c *counter) licenses(expr string) []string {
+	ids, err := licenseexpr.Licenses(expr)
+	if err != nil {
+		c.invalid[expr] = true
+		return []string{expr}
+	}
+	return ids
+}
+
+// known reports whether the license expression expr states a license.
+func known(expr string) bool {
+	return expr != "" && expr != "NOASSERTION" && expr != "NONE"
+}
+
+// isChoiceOf reports whether the license expression concluded is a choice
+// of declared: whether every choice of concluded satisfies declared using
+// only licenses of declared. An error is returned if either expression
+// cannot be parsed, or concluded has too many choices to check.
+func isChoiceOf(concluded string, declared string) (bool, error) {
+	ec, err := licenseexpr.Parse(concluded)
+	if err != nil {
+		return false, err
+	}
+	ed, err := licenseexpr.Parse(declared)
+	if err != nil {
+		return false, err
+	}
+	choices, err := ec.Choices()
+	if err != nil {
+		return false, err
+	}
+	inDeclared := map[string]bool{}
+	ed.Walk(func(license *licenseexpr.Expression) {
+		inDeclared[strings.ToLower(license.String())] = true
+	})
+	for _, choice := range choices {
+		for _, l := range choice {
+			if !inDeclared[strings.ToLower(l)] {
+				return false, nil
+			}
+		}
+		if _, ok := ed.Satisfies(choice); !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// missing returns the ids not in other.
+func missing(ids []string, other []string) []string {
+	in := map[string]bool{}
+	for _, id := range other {
+		in[id] = true
+	}
+	result := []string{}
+	for _, id := range ids {
+		if !in[id] {
+			result = append(result, id)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func sortCounts(counts map[string]int) []LicenseCount {
+	result := []LicenseCount{}
+	for lic, n := range counts {
+		result = append(result, LicenseCount{License: lic, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].License < result[j].License
+	})
+	return result
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+)
+
+func makeReportTestDocument() *spdx.Document {
+	return &spdx.Document{
+		DocumentName: "report-test",
+		Packages: []*spdx.Package{
+			{
+				PackageSPDXIdentifier:   "Package-a",
+				PackageName:             "a",
+				PackageVersion:          "1.0",
+				FilesAnalyzed:           true,
+				PackageLicenseConcluded: "MIT AND Apache-2.0",
+				PackageLicenseDeclared:  "MIT",
+				Files: []*spdx.File{
+					{FileSPDXIdentifier: "File0", FileName: "./a.c", LicenseConcluded: "MIT"},
+					{FileSPDXIdentifier: "File1", FileName: "./b.c", LicenseConcluded: "MIT OR Apache-2.0"},
+					{FileSPDXIdentifier: "File2", FileName: "./c.c", LicenseConcluded: "NOASSERTION"},
+				},
+			},
+			{
+				PackageSPDXIdentifier:   "Package-b",
+				PackageName:             "b",
+				FilesAnalyzed:           false,
+				PackageLicenseConcluded: "MIT",
+				PackageLicenseDeclared:  "NOASSERTION",
+			},
+		},
+		Files: []*spdx.File{
+			{FileSPDXIdentifier: "File3", FileName: "./d.c", LicenseConcluded: "BSD-3-Clause AND ("},
+		},
+	}
+}
+
+// ===== Document report tests =====
+func TestMakeReportCountsLicenseIDs(t *testing.T) {
+	report, err := MakeReport(makeReportTestDocument())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("expected %d, got %d", 2, len(report.Packages))
+	}
+	a := report.Packages[0]
+	if want := "{2 1 [{MIT 2} {Apache-2.0 1}]}"; fmt.Sprint(a.Files) != want {
+		t.Errorf("expected %v, got %v", want, a.Files)
+	}
+	if !a.LicenseMismatch || fmt.Sprint(a.ConcludedNotDeclared) != "[Apache-2.0]" || a.DeclaredNotConcluded != nil {
+		t.Errorf("unexpected mismatch %v, %v", a.ConcludedNotDeclared, a.DeclaredNotConcluded)
+	}
+	if b := report.Packages[1]; b.LicenseMismatch || b.Files.Found+b.Files.NotFound != 0 {
+		t.Errorf("unexpected report for package b %+v", b)
+	}
+	if want := "{1 0 [{BSD-3-Clause AND ( 1}]}"; fmt.Sprint(report.Unpackaged) != want {
+		t.Errorf("expected %v, got %v", want, report.Unpackaged)
+	}
+	if want := "{3 1 [{MIT 2} {Apache-2.0 1} {BSD-3-Clause AND ( 1}]}"; fmt.Sprint(report.Total) != want {
+		t.Errorf("expected %v, got %v", want, report.Total)
+	}
+	if want := "[{MIT 2} {Apache-2.0 1}]"; fmt.Sprint(report.PackageLicenses) != want {
+		t.Errorf("expected %v, got %v", want, report.PackageLicenses)
+	}
+	if want := "[BSD-3-Clause AND (]"; fmt.Sprint(report.InvalidExpressions) != want {
+		t.Errorf("expected %v, got %v", want, report.InvalidExpressions)
+	}
+}
+
+func TestMakeReportComparesLicenseExpressions(t *testing.T) {
+	for _, c := range []struct {
+		concluded, declared string
+		want                string
+	}{
+		{"MIT", "MIT OR Apache-2.0", ""},
+		{"Apache-2.0 OR MIT", "MIT OR Apache-2.0", ""},
+		{"mit", "MIT", ""},
+		{"MIT AND Apache-2.0", "MIT", "concluded but not declared: Apache-2.0"},
+		{"MIT OR Apache-2.0", "MIT AND Apache-2.0", "concluded license is not a choice of the declared license"},
+		{"MIT", "MIT AND Apache-2.0", "declared but not concluded: Apache-2.0"},
+		{"MIT AND (", "MIT AND (", ""},
+	} {
+		doc := &spdx.Document{Packages: []*spdx.Package{
+			{PackageName: "a", PackageLicenseConcluded: c.concluded, PackageLicenseDeclared: c.declared},
+		}}
+		report, err := MakeReport(doc)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		pr := report.Packages[0]
+		if got := mismatch(pr); got != c.want || pr.LicenseMismatch != (c.want != "") {
+			t.Errorf("%s, %s: expected %q, got %q", c.concluded, c.declared, c.want, got)
+		}
+	}
+}
+
+// ===== Renderer tests =====
+func TestGenerateDocumentRendersText(t *testing.T) {
+	var got bytes.Buffer
+	if err := GenerateDocument(makeReportTestDocument(), &got, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, want := range []string{
+		"License report for report-test\n",
+		"Package a 1.0 (SPDXRef-Package-a)\n  Concluded: MIT AND Apache-2.0\n  Declared: MIT\n  Mismatch: concluded but not declared: Apache-2.0\n",
+		"  2  License found\n  1  License not found\n  3  TOTAL\n  2  MIT\n  1  Apache-2.0\n",
+		"Invalid license expression: BSD-3-Clause AND (\n",
+	} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("expected output to contain %q, got %v", want, got.String())
+		}
+	}
+}
+
+func TestGenerateDocumentRendersJSON(t *testing.T) {
+	var got bytes.Buffer
+	if err := GenerateDocument(makeReportTestDocument(), &got, JSONRenderer{}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(got.Bytes(), &report); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if report.Total.Found != 3 || report.Packages[0].ConcludedNotDeclared[0] != "Apache-2.0" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestGenerateDocumentRendersCSV(t *testing.T) {
+	var got bytes.Buffer
+	if err := GenerateDocument(makeReportTestDocument(), &got, CSVRenderer{}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := `scope,SPDXID,name,version,license,files,mismatch
+package,SPDXRef-Package-a,a,1.0,MIT,2,concluded but not declared: Apache-2.0
+package,SPDXRef-Package-a,a,1.0,Apache-2.0,1,concluded but not declared: Apache-2.0
+package,SPDXRef-Package-a,a,1.0,NOASSERTION,1,concluded but not declared: Apache-2.0
+package,SPDXRef-Package-b,b,,,,
+unpackaged,,,,BSD-3-Clause AND (,1,
+document,,report-test,,MIT,2,
+document,,report-test,,Apache-2.0,1,
+document,,report-test,,BSD-3-Clause AND (,1,
+document,,report-test,,NOASSERTION,1,
+`
+	if got.String() != want {
+		t.Errorf("expected %v, got %v", want, got.String())
+	}
+}
+
+func TestGenerateDocumentRendersMarkdown(t *testing.T) {
+	var got bytes.Buffer
+	if err := GenerateDocument(makeReportTestDocument(), &got, MarkdownRenderer{}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, want := range []string{
+		"# License report for report-test\n",
+		"| a 1.0 | MIT AND Apache-2.0 | MIT | 3 | concluded but not declared: Apache-2.0 |\n",
+		"| b | MIT | NOASSERTION | not analyzed |  |\n",
+		"| MIT | 2 |\n",
+		"- `BSD-3-Clause AND (`\n",
+	} {
+		if !strings.Contains(got.String(), want) {
+			t.Errorf("expected output to contain %q, got %v", want, got.String())
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Renderer renders a Report to an io.Writer.
+type Renderer interface {
+	Render(r *Report, w io.Writer) error
+}
+
+// TextRenderer renders a Report as text tables, in the style of Generate.
+type TextRenderer struct{}
+
+// JSONRenderer renders a Report as indented JSON.
+type JSONRenderer struct{}
+
+// CSVRenderer renders the license counts of a Report as CSV, with a row
+// for each license of each package, of the unpackaged files and of the
+// whole document. Files without a concluded license are counted under
+// NOASSERTION. A package whose files were not analyzed has a single row,
+// without a license or count. The mismatch column of a package's rows
+// describes any mismatch between its concluded and declared licenses.
+type CSVRenderer struct{}
+
+// MarkdownRenderer renders a Report as a Markdown document.
+type MarkdownRenderer struct{}
+
+// Render implements Renderer.
+func (TextRenderer) Render(r *Report, w io.Writer) error {
+	fmt.Fprintf(w, "License report for %s\n", r.DocumentName)
+	for _, pr := range r.Packages {
+		fmt.Fprintf(w, "\nPackage %s (SPDXRef-%s)\n", packageTitle(pr), pr.SPDXIdentifier)
+		fmt.Fprintf(w, "  Concluded: %s\n", pr.LicenseConcluded)
+		fmt.Fprintf(w, "  Declared: %s\n", pr.LicenseDeclared)
+		if pr.LicenseMismatch {
+			fmt.Fprintf(w, "  Mismatch: %s\n", mismatch(pr))
+		}
+		if pr.FilesAnalyzed {
+			writeTextCounts(w, pr.Files)
+		}
+	}
+	if r.Unpackaged.Found+r.Unpackaged.NotFound > 0 {
+		fmt.Fprintf(w, "\nUnpackaged files\n")
+		writeTextCounts(w, r.Unpackaged)
+	}
+	fmt.Fprintf(w, "\nAll files\n")
+	writeTextCounts(w, r.Total)
+	for _, expr := range r.InvalidExpressions {
+		fmt.Fprintf(w, "\nInvalid license expression: %s\n", expr)
+	}
+	return nil
+}
+
+func writeTextCounts(w io.Writer, c Counts) {
+	wr := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(wr, "%d\t  License found\n", c.Found)
+	fmt.Fprintf(wr, "%d\t  License not found\n", c.NotFound)
+	fmt.Fprintf(wr, "%d\t  TOTAL\n", c.Found+c.NotFound)
+	for _, lc := range c.Licenses {
+		fmt.Fprintf(wr, "%d\t  %s\n", lc.Count, lc.License)
+	}
+	wr.Flush()
+}
+
+// Render implements Renderer.
+func (JSONRenderer) Render(r *Report, w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(r)
+}
+
+// Render implements Renderer.
+func (CSVRenderer) Render(r *Report, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"scope", "SPDXID", "name", "version", "license", "files", "mismatch"})
+	writeRows := func(scope, id, name, version string, c Counts, mismatch string) {
+		for _, lc := range c.Licenses {
+			cw.Write([]string{scope, id, name, version, lc.License, fmt.Sprint(lc.Count), mismatch})
+		}
+		if c.NotFound > 0 {
+			cw.Write([]string{scope, id, name, version, "NOASSERTION", fmt.Sprint(c.NotFound), mismatch})
+		}
+	}
+	for _, pr := range r.Packages {
+		id := "SPDXRef-" + string(pr.SPDXIdentifier)
+		if !pr.FilesAnalyzed {
+			cw.Write([]string{"package", id, pr.Name, pr.Version, "", "", mismatch(pr)})
+			continue
+		}
+		writeRows("package", id, pr.Name, pr.Version, pr.Files, mismatch(pr))
+	}
+	writeRows("unpackaged", "", "", "", r.Unpackaged, "")
+	writeRows("document", "", r.DocumentName, "", r.Total, "")
+	cw.Flush()
+	return cw.Error()
+}
+
+// Render implements Renderer.
+func (MarkdownRenderer) Render(r *Report, w io.Writer) error {
+	fmt.Fprintf(w, "# License report for %s\n", markdownEscape(r.DocumentName))
+	fmt.Fprintf(w, "\n## Packages\n\n")
+	fmt.Fprintf(w, "| Package | Concluded | Declared | Files | Mismatch |\n")
+	fmt.Fprintf(w, "| --- | --- | --- | --- | --- |\n")
+	for _, pr := range r.Packages {
+		files := "not analyzed"
+		if pr.FilesAnalyzed {
+			files = fmt.Sprint(pr.Files.Found + pr.Files.NotFound)
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", markdownEscape(packageTitle(pr)),
+			markdownEscape(pr.LicenseConcluded), markdownEscape(pr.LicenseDeclared), files, markdownEscape(mismatch(pr)))
+	}
+	fmt.Fprintf(w, "\n## Licenses of all files\n\n")
+	fmt.Fprintf(w, "%d files, %d with a license found, %d without.\n", r.Total.Found+r.Total.NotFound, r.Total.Found, r.Total.NotFound)
+	if len(r.Total.Licenses) > 0 {
+		fmt.Fprintf(w, "\n| License | Files |\n| --- | --- |\n")
+		for _, lc := range r.Total.Licenses {
+			fmt.Fprintf(w, "| %s | %d |\n", markdownEscape(lc.License), lc.Count)
+		}
+	}
+	if len(r.InvalidExpressions) > 0 {
+		fmt.Fprintf(w, "\n## Invalid license expressions\n\n")
+		for _, expr := range r.InvalidExpressions {
+			fmt.Fprintf(w, "- `%s`\n", expr)
+		}
+	}
+	return nil
+}
+
+func packageTitle(pr PackageReport) string {
+	if pr.Version == "" {
+		return pr.Name
+	}
+	return pr.Name + " " + pr.Version
+}
+
+// mismatch describes the license IDs of a package concluded but not
+// declared, and declared but not concluded, if its concluded license is
+// not a choice of its declared license.
+func mismatch(pr PackageReport) string {
+	if !pr.LicenseMismatch {
+		return ""
+	}
+	parts := []string{}
+	if len(pr.ConcludedNotDeclared) > 0 {
+		parts = append(parts, "concluded but not declared: "+strings.Join(pr.ConcludedNotDeclared, ", "))
+	}
+	if len(pr.DeclaredNotConcluded) > 0 {
+		parts = append(parts, "declared but not concluded: "+strings.Join(pr.DeclaredNotConcluded, ", "))
+	}
+	if len(parts) == 0 {
+		return "concluded license is not a choice of the declared license"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
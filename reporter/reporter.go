@@ -1,6 +1,6 @@
 // Package reporter contains functions to generate a basic license count
 // report from an in-memory SPDX Package section whose Files have been
-// analyzed.
+// analyzed, or a license report of a whole Document.
 // SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
 package reporter
 
@@ -95,6 +95,7 @@ const (
 	RelationshipContainedBy               = common.TypeRelationshipContainedBy
 	RelationshipDependsOn                 = common.TypeRelationshipDependsOn
 	RelationshipDependencyOf              = common.TypeRelationshipDependencyOf
+	RelationshipDependencyManifestOf      = common.TypeRelationshipDependencyManifestOf
 	RelationshipBuildDependencyOf         = common.TypeRelationshipBuildDependencyOf
 	RelationshipDevDependencyOf           = common.TypeRelationshipDevDependencyOf
 	RelationshipOptionalDependencyOf      = common.TypeRelationshipOptionalDependencyOf
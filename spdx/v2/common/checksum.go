@@ -26,6 +26,42 @@ const (
 	ADLER32     ChecksumAlgorithm = "ADLER32"
 )
 
+// checksumAlgorithmsSince gives the SPDX minor version that introduced each
+// checksum algorithm.
+var checksumAlgorithmsSince = map[ChecksumAlgorithm]int{
+	SHA1:        1,
+	SHA256:      1,
+	MD5:         1,
+	SHA224:      2,
+	SHA384:      2,
+	SHA512:      2,
+	MD2:         2,
+	MD4:         2,
+	MD6:         2,
+	SHA3_256:    3,
+	SHA3_384:    3,
+	SHA3_512:    3,
+	BLAKE2b_256: 3,
+	BLAKE2b_384: 3,
+	BLAKE2b_512: 3,
+	BLAKE3:      3,
+	ADLER32:     3,
+}
+
+// AvailableIn reports whether a is a known checksum algorithm defined by
+// the given SPDX version, such as "SPDX-2.2" or "2.2".
+func (a ChecksumAlgorithm) AvailableIn(version string) bool {
+	since, ok := checksumAlgorithmsSince[a]
+	if !ok {
+		return false
+	}
+	v, err := parseSPDXVersion(version)
+	if err != nil {
+		return false
+	}
+	return v >= since
+}
+
 // Checksum provides a unique identifier to match analysis information on each specific file in a package.
 // The Algorithm field describes the ChecksumAlgorithm used and the Value represents the file checksum
 type Checksum struct {
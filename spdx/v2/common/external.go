@@ -30,49 +30,51 @@ const (
 	// F.5 Other
 	CategoryOther string = "OTHER"
 
-	// 11.1 Relationship field types
-	TypeRelationshipDescribe                  string = "DESCRIBES"
-	TypeRelationshipDescribeBy                string = "DESCRIBED_BY"
-	TypeRelationshipContains                  string = "CONTAINS"
-	TypeRelationshipContainedBy               string = "CONTAINED_BY"
-	TypeRelationshipDependsOn                 string = "DEPENDS_ON"
-	TypeRelationshipDependencyOf              string = "DEPENDENCY_OF"
-	TypeRelationshipBuildDependencyOf         string = "BUILD_DEPENDENCY_OF"
-	TypeRelationshipDevDependencyOf           string = "DEV_DEPENDENCY_OF"
-	TypeRelationshipOptionalDependencyOf      string = "OPTIONAL_DEPENDENCY_OF"
-	TypeRelationshipProvidedDependencyOf      string = "PROVIDED_DEPENDENCY_OF"
-	TypeRelationshipTestDependencyOf          string = "TEST_DEPENDENCY_OF"
-	TypeRelationshipRuntimeDependencyOf       string = "RUNTIME_DEPENDENCY_OF"
-	TypeRelationshipExampleOf                 string = "EXAMPLE_OF"
-	TypeRelationshipGenerates                 string = "GENERATES"
-	TypeRelationshipGeneratedFrom             string = "GENERATED_FROM"
-	TypeRelationshipAncestorOf                string = "ANCESTOR_OF"
-	TypeRelationshipDescendantOf              string = "DESCENDANT_OF"
-	TypeRelationshipVariantOf                 string = "VARIANT_OF"
-	TypeRelationshipDistributionArtifact      string = "DISTRIBUTION_ARTIFACT"
-	TypeRelationshipPatchFor                  string = "PATCH_FOR"
-	TypeRelationshipPatchApplied              string = "PATCH_APPLIED"
-	TypeRelationshipCopyOf                    string = "COPY_OF"
-	TypeRelationshipFileAdded                 string = "FILE_ADDED"
-	TypeRelationshipFileDeleted               string = "FILE_DELETED"
-	TypeRelationshipFileModified              string = "FILE_MODIFIED"
-	TypeRelationshipExpandedFromArchive       string = "EXPANDED_FROM_ARCHIVE"
-	TypeRelationshipDynamicLink               string = "DYNAMIC_LINK"
-	TypeRelationshipStaticLink                string = "STATIC_LINK"
-	TypeRelationshipDataFileOf                string = "DATA_FILE_OF"
-	TypeRelationshipTestCaseOf                string = "TEST_CASE_OF"
-	TypeRelationshipBuildToolOf               string = "BUILD_TOOL_OF"
-	TypeRelationshipDevToolOf                 string = "DEV_TOOL_OF"
-	TypeRelationshipTestOf                    string = "TEST_OF"
-	TypeRelationshipTestToolOf                string = "TEST_TOOL_OF"
-	TypeRelationshipDocumentationOf           string = "DOCUMENTATION_OF"
-	TypeRelationshipOptionalComponentOf       string = "OPTIONAL_COMPONENT_OF"
-	TypeRelationshipMetafileOf                string = "METAFILE_OF"
-	TypeRelationshipPackageOf                 string = "PACKAGE_OF"
-	TypeRelationshipAmends                    string = "AMENDS"
-	TypeRelationshipPrerequisiteFor           string = "PREREQUISITE_FOR"
-	TypeRelationshipHasPrerequisite           string = "HAS_PREREQUISITE"
-	TypeRelationshipRequirementDescriptionFor string = "REQUIREMENT_DESCRIPTION_FOR"
-	TypeRelationshipSpecificationFor          string = "SPECIFICATION_FOR"
-	TypeRelationshipOther                     string = "OTHER"
+	// 11.1 Relationship field types, untyped so that they are both strings
+	// and RelationshipTypes
+	TypeRelationshipDescribe                  = "DESCRIBES"
+	TypeRelationshipDescribeBy                = "DESCRIBED_BY"
+	TypeRelationshipContains                  = "CONTAINS"
+	TypeRelationshipContainedBy               = "CONTAINED_BY"
+	TypeRelationshipDependsOn                 = "DEPENDS_ON"
+	TypeRelationshipDependencyOf              = "DEPENDENCY_OF"
+	TypeRelationshipDependencyManifestOf      = "DEPENDENCY_MANIFEST_OF"
+	TypeRelationshipBuildDependencyOf         = "BUILD_DEPENDENCY_OF"
+	TypeRelationshipDevDependencyOf           = "DEV_DEPENDENCY_OF"
+	TypeRelationshipOptionalDependencyOf      = "OPTIONAL_DEPENDENCY_OF"
+	TypeRelationshipProvidedDependencyOf      = "PROVIDED_DEPENDENCY_OF"
+	TypeRelationshipTestDependencyOf          = "TEST_DEPENDENCY_OF"
+	TypeRelationshipRuntimeDependencyOf       = "RUNTIME_DEPENDENCY_OF"
+	TypeRelationshipExampleOf                 = "EXAMPLE_OF"
+	TypeRelationshipGenerates                 = "GENERATES"
+	TypeRelationshipGeneratedFrom             = "GENERATED_FROM"
+	TypeRelationshipAncestorOf                = "ANCESTOR_OF"
+	TypeRelationshipDescendantOf              = "DESCENDANT_OF"
+	TypeRelationshipVariantOf                 = "VARIANT_OF"
+	TypeRelationshipDistributionArtifact      = "DISTRIBUTION_ARTIFACT"
+	TypeRelationshipPatchFor                  = "PATCH_FOR"
+	TypeRelationshipPatchApplied              = "PATCH_APPLIED"
+	TypeRelationshipCopyOf                    = "COPY_OF"
+	TypeRelationshipFileAdded                 = "FILE_ADDED"
+	TypeRelationshipFileDeleted               = "FILE_DELETED"
+	TypeRelationshipFileModified              = "FILE_MODIFIED"
+	TypeRelationshipExpandedFromArchive       = "EXPANDED_FROM_ARCHIVE"
+	TypeRelationshipDynamicLink               = "DYNAMIC_LINK"
+	TypeRelationshipStaticLink                = "STATIC_LINK"
+	TypeRelationshipDataFileOf                = "DATA_FILE_OF"
+	TypeRelationshipTestCaseOf                = "TEST_CASE_OF"
+	TypeRelationshipBuildToolOf               = "BUILD_TOOL_OF"
+	TypeRelationshipDevToolOf                 = "DEV_TOOL_OF"
+	TypeRelationshipTestOf                    = "TEST_OF"
+	TypeRelationshipTestToolOf                = "TEST_TOOL_OF"
+	TypeRelationshipDocumentationOf           = "DOCUMENTATION_OF"
+	TypeRelationshipOptionalComponentOf       = "OPTIONAL_COMPONENT_OF"
+	TypeRelationshipMetafileOf                = "METAFILE_OF"
+	TypeRelationshipPackageOf                 = "PACKAGE_OF"
+	TypeRelationshipAmends                    = "AMENDS"
+	TypeRelationshipPrerequisiteFor           = "PREREQUISITE_FOR"
+	TypeRelationshipHasPrerequisite           = "HAS_PREREQUISITE"
+	TypeRelationshipRequirementDescriptionFor = "REQUIREMENT_DESCRIPTION_FOR"
+	TypeRelationshipSpecificationFor          = "SPECIFICATION_FOR"
+	TypeRelationshipOther                     = "OTHER"
 )
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RelationshipType is the type of a relationship, such as DEPENDS_ON; the
+// TypeRelationship constants are its values.
+type RelationshipType string
+
+// RelationshipCategory groups relationship types by what they describe.
+type RelationshipCategory string
+
+// Relationship type categories
+const (
+	RelationshipCategoryDescription   RelationshipCategory = "description"
+	RelationshipCategoryContainment   RelationshipCategory = "containment"
+	RelationshipCategoryDependency    RelationshipCategory = "dependency"
+	RelationshipCategoryBuild         RelationshipCategory = "build"
+	RelationshipCategoryDevelopment   RelationshipCategory = "development"
+	RelationshipCategoryTest          RelationshipCategory = "test"
+	RelationshipCategoryDocumentation RelationshipCategory = "documentation"
+	RelationshipCategoryData          RelationshipCategory = "data"
+	RelationshipCategoryPatch         RelationshipCategory = "patch"
+	RelationshipCategoryModification  RelationshipCategory = "modification"
+	RelationshipCategoryDerivation    RelationshipCategory = "derivation"
+	RelationshipCategoryLinking       RelationshipCategory = "linking"
+	RelationshipCategoryDistribution  RelationshipCategory = "distribution"
+	RelationshipCategoryOther         RelationshipCategory = "other"
+)
+
+// RelationshipTypeInfo describes a relationship type.
+type RelationshipTypeInfo struct {
+	Type     RelationshipType
+	Category RelationshipCategory
+	// Inverse is the type that relates the same elements the other way
+	// round, such as CONTAINED_BY for CONTAINS, or empty if there is none.
+	Inverse RelationshipType
+	// Reversed is set for the type of an inverse pair that is read from its
+	// second element to its first, such as CONTAINED_BY: "A CONTAINED_BY B"
+	// means "B CONTAINS A".
+	Reversed bool
+	// Implies is the more general type that a relationship of this type
+	// also states, such as DEPENDENCY_OF for BUILD_DEPENDENCY_OF, or empty.
+	Implies RelationshipType
+	// Directional is false for types that state the same thing when their
+	// elements are swapped, such as COPY_OF.
+	Directional bool
+	// Since is the SPDX version, such as "SPDX-2.1", that introduced the type.
+	Since string
+}
+
+// relationshipTypes lists each relationship type, in the order of the
+// TypeRelationship constants.
+var relationshipTypes = []RelationshipTypeInfo{
+	{TypeRelationshipDescribe, RelationshipCategoryDescription, TypeRelationshipDescribeBy, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDescribeBy, RelationshipCategoryDescription, TypeRelationshipDescribe, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipContains, RelationshipCategoryContainment, TypeRelationshipContainedBy, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipContainedBy, RelationshipCategoryContainment, TypeRelationshipContains, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipDependsOn, RelationshipCategoryDependency, TypeRelationshipDependencyOf, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDependencyOf, RelationshipCategoryDependency, TypeRelationshipDependsOn, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipDependencyManifestOf, RelationshipCategoryDependency, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipBuildDependencyOf, RelationshipCategoryBuild, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipDevDependencyOf, RelationshipCategoryDevelopment, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipOptionalDependencyOf, RelationshipCategoryDependency, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipProvidedDependencyOf, RelationshipCategoryDependency, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipTestDependencyOf, RelationshipCategoryTest, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipRuntimeDependencyOf, RelationshipCategoryDependency, "", false, TypeRelationshipDependencyOf, true, "SPDX-2.1"},
+	{TypeRelationshipExampleOf, RelationshipCategoryDocumentation, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipGenerates, RelationshipCategoryBuild, TypeRelationshipGeneratedFrom, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipGeneratedFrom, RelationshipCategoryBuild, TypeRelationshipGenerates, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipAncestorOf, RelationshipCategoryDerivation, TypeRelationshipDescendantOf, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDescendantOf, RelationshipCategoryDerivation, TypeRelationshipAncestorOf, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipVariantOf, RelationshipCategoryDerivation, "", false, "", false, "SPDX-2.1"},
+	{TypeRelationshipDistributionArtifact, RelationshipCategoryDistribution, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipPatchFor, RelationshipCategoryPatch, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipPatchApplied, RelationshipCategoryPatch, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipCopyOf, RelationshipCategoryDerivation, "", false, "", false, "SPDX-2.1"},
+	{TypeRelationshipFileAdded, RelationshipCategoryModification, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipFileDeleted, RelationshipCategoryModification, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipFileModified, RelationshipCategoryModification, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipExpandedFromArchive, RelationshipCategoryDerivation, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDynamicLink, RelationshipCategoryLinking, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipStaticLink, RelationshipCategoryLinking, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDataFileOf, RelationshipCategoryData, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipTestCaseOf, RelationshipCategoryTest, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipBuildToolOf, RelationshipCategoryBuild, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDevToolOf, RelationshipCategoryDevelopment, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipTestOf, RelationshipCategoryTest, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipTestToolOf, RelationshipCategoryTest, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipDocumentationOf, RelationshipCategoryDocumentation, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipOptionalComponentOf, RelationshipCategoryContainment, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipMetafileOf, RelationshipCategoryData, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipPackageOf, RelationshipCategoryContainment, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipAmends, RelationshipCategoryModification, "", false, "", true, "SPDX-2.1"},
+	{TypeRelationshipPrerequisiteFor, RelationshipCategoryDependency, TypeRelationshipHasPrerequisite, true, "", true, "SPDX-2.1"},
+	{TypeRelationshipHasPrerequisite, RelationshipCategoryDependency, TypeRelationshipPrerequisiteFor, false, "", true, "SPDX-2.1"},
+	{TypeRelationshipRequirementDescriptionFor, RelationshipCategoryDocumentation, "", false, "", true, "SPDX-2.3"},
+	{TypeRelationshipSpecificationFor, RelationshipCategoryDocumentation, "", false, "", true, "SPDX-2.3"},
+	{TypeRelationshipOther, RelationshipCategoryOther, "", false, "", false, "SPDX-2.1"},
+}
+
+var relationshipTypesByName = func() map[RelationshipType]RelationshipTypeInfo {
+	m := map[RelationshipType]RelationshipTypeInfo{}
+	for _, info := range relationshipTypes {
+		m[info.Type] = info
+	}
+	return m
+}()
+
+// RelationshipTypes returns the descriptions of all known relationship
+// types.
+func RelationshipTypes() []RelationshipTypeInfo {
+	return append([]RelationshipTypeInfo{}, relationshipTypes...)
+}
+
+// Info returns the description of t, and false if t is not a known type.
+func (t RelationshipType) Info() (RelationshipTypeInfo, bool) {
+	info, ok := relationshipTypesByName[t]
+	return info, ok
+}
+
+// Inverse returns the inverse of t, and false if it has none.
+func (t RelationshipType) Inverse() (RelationshipType, bool) {
+	info := relationshipTypesByName[t]
+	return info.Inverse, info.Inverse != ""
+}
+
+// Normalize returns the type in whose direction a relationship of type t
+// reads from its first element to its second, and whether the relationship's
+// elements must be swapped to read it so. More specific types are replaced
+// by the type they imply: "A BUILD_DEPENDENCY_OF B" normalizes to
+// "B DEPENDS_ON A". Unknown types are returned unchanged.
+func (t RelationshipType) Normalize() (RelationshipType, bool) {
+	info, ok := relationshipTypesByName[t]
+	if !ok {
+		return t, false
+	}
+	if info.Implies != "" {
+		info = relationshipTypesByName[info.Implies]
+	}
+	if info.Reversed {
+		return info.Inverse, true
+	}
+	return info.Type, false
+}
+
+// AvailableIn reports whether t is a known type defined by the given SPDX
+// version, such as "SPDX-2.2" or "2.2".
+func (t RelationshipType) AvailableIn(version string) bool {
+	info, ok := relationshipTypesByName[t]
+	if !ok {
+		return false
+	}
+	v, err := parseSPDXVersion(version)
+	if err != nil {
+		return false
+	}
+	since, _ := parseSPDXVersion(info.Since)
+	return v >= since
+}
+
+// ValidateRelationshipType returns an error if relType is not a known
+// relationship type, or, if version is not empty, was not yet defined by
+// that SPDX version.
+func ValidateRelationshipType(relType string, version string) error {
+	t := RelationshipType(relType)
+	info, ok := t.Info()
+	if !ok {
+		return fmt.Errorf("unknown relationship type %q", relType)
+	}
+	if version == "" {
+		return nil
+	}
+	if _, err := parseSPDXVersion(version); err != nil {
+		return err
+	}
+	if !t.AvailableIn(version) {
+		return fmt.Errorf("relationship type %s requires %s, not %s", relType, info.Since, version)
+	}
+	return nil
+}
+
+// parseSPDXVersion returns the minor version of an SPDX 2 version.
+func parseSPDXVersion(version string) (int, error) {
+	minor, ok := strings.CutPrefix(strings.TrimPrefix(version, "SPDX-"), "2.")
+	if !ok {
+		return 0, fmt.Errorf("unsupported SPDX version %q", version)
+	}
+	n, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported SPDX version %q", version)
+	}
+	return n, nil
+}
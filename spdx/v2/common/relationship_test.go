@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package common
+
+import (
+	"testing"
+)
+
+func Test_RelationshipTypesCoverConstants(t *testing.T) {
+	infos := RelationshipTypes()
+	if len(infos) != 45 {
+		t.Errorf("expected %d, got %d", 45, len(infos))
+	}
+	for _, info := range infos {
+		if info.Category == "" || info.Since == "" {
+			t.Errorf("%s: incomplete description %+v", info.Type, info)
+		}
+		if info.Inverse != "" {
+			inverse, ok := info.Inverse.Info()
+			if !ok || inverse.Inverse != info.Type || inverse.Reversed == info.Reversed {
+				t.Errorf("%s: inverse %s does not invert it", info.Type, info.Inverse)
+			}
+		}
+		if info.Implies != "" {
+			if _, ok := info.Implies.Info(); !ok {
+				t.Errorf("%s: implies unknown type %s", info.Type, info.Implies)
+			}
+		}
+	}
+}
+
+func Test_RelationshipTypeNormalize(t *testing.T) {
+	tests := []struct {
+		relType  RelationshipType
+		expected RelationshipType
+		reversed bool
+	}{
+		{TypeRelationshipDependsOn, TypeRelationshipDependsOn, false},
+		{TypeRelationshipDependencyOf, TypeRelationshipDependsOn, true},
+		{TypeRelationshipBuildDependencyOf, TypeRelationshipDependsOn, true},
+		{TypeRelationshipContainedBy, TypeRelationshipContains, true},
+		{TypeRelationshipDescribeBy, TypeRelationshipDescribe, true},
+		{TypeRelationshipPrerequisiteFor, TypeRelationshipHasPrerequisite, true},
+		{TypeRelationshipStaticLink, TypeRelationshipStaticLink, false},
+		{"NOT_A_TYPE", "NOT_A_TYPE", false},
+	}
+	for _, test := range tests {
+		got, reversed := test.relType.Normalize()
+		if got != test.expected || reversed != test.reversed {
+			t.Errorf("%s: expected %v %v, got %v %v", test.relType, test.expected, test.reversed, got, reversed)
+		}
+	}
+
+	if inverse, ok := RelationshipType(TypeRelationshipGenerates).Inverse(); !ok || inverse != TypeRelationshipGeneratedFrom {
+		t.Errorf("expected %v, got %v", TypeRelationshipGeneratedFrom, inverse)
+	}
+	if _, ok := RelationshipType(TypeRelationshipBuildDependencyOf).Inverse(); ok {
+		t.Errorf("expected no inverse")
+	}
+}
+
+func Test_ValidateRelationshipType(t *testing.T) {
+	tests := []struct {
+		relType string
+		version string
+		err     bool
+	}{
+		{TypeRelationshipDependsOn, "", false},
+		{TypeRelationshipDependsOn, "SPDX-2.1", false},
+		{TypeRelationshipSpecificationFor, "SPDX-2.3", false},
+		{TypeRelationshipSpecificationFor, "2.3", false},
+		{TypeRelationshipSpecificationFor, "SPDX-2.2", true},
+		{TypeRelationshipRequirementDescriptionFor, "SPDX-2.2", true},
+		{"depends_on", "", true},
+		{"NOT_A_TYPE", "SPDX-2.3", true},
+		{TypeRelationshipDependsOn, "SPDX-3.0", true},
+	}
+	for _, test := range tests {
+		err := ValidateRelationshipType(test.relType, test.version)
+		if (err != nil) != test.err {
+			t.Errorf("%s in %q: expected error %v, got %v", test.relType, test.version, test.err, err)
+		}
+	}
+}
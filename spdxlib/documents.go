@@ -10,7 +10,7 @@ import (
 
 // ValidateDocument returns an error if the Document is found to be invalid, or nil if the Document is valid.
 // Currently, this only verifies that all Element IDs mentioned in Relationships exist in the Document as either a
-// Package or an UnpackagedFile.
+// Package or an UnpackagedFile, and that each Relationship's type is known and defined by the Document's SPDXVersion.
 func ValidateDocument(doc *spdx.Document) error {
 	// cache a map of package IDs for quick lookups
 	validElementIDs := make(map[common.ElementID]bool)
@@ -26,6 +26,10 @@ func ValidateDocument(doc *spdx.Document) error {
 	validElementIDs[common.MakeDocElementID("", "DOCUMENT").ElementRefID] = true
 
 	for _, relationship := range doc.Relationships {
+		if err := common.ValidateRelationshipType(relationship.Relationship, doc.SPDXVersion); err != nil {
+			return err
+		}
+
 		if !validElementIDs[relationship.RefA.ElementRefID] {
 			return fmt.Errorf("%s used in relationship but no such package exists", string(relationship.RefA.ElementRefID))
 		}
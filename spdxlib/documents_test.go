@@ -115,3 +115,31 @@ func TestInvalidDocumentFailsValidation(t *testing.T) {
 		t.Fatalf("expected non-nil error, got nil")
 	}
 }
+
+func TestDocumentWithInvalidRelationshipTypeFailsValidation(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		relType string
+	}{
+		{spdx.Version, "USES"},
+		{"SPDX-2.2", common.TypeRelationshipSpecificationFor},
+	} {
+		doc := &spdx.Document{
+			SPDXVersion:    test.version,
+			SPDXIdentifier: common.ElementID("DOCUMENT"),
+			Packages: []*spdx.Package{
+				{PackageName: "pkg1", PackageSPDXIdentifier: "p1"},
+			},
+			Relationships: []*spdx.Relationship{
+				{
+					RefA:         common.MakeDocElementID("", "DOCUMENT"),
+					RefB:         common.MakeDocElementID("", "p1"),
+					Relationship: test.relType,
+				},
+			},
+		}
+		if err := ValidateDocument(doc); err == nil {
+			t.Errorf("%s in %s: expected non-nil error, got nil", test.relType, test.version)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spdx/tools-golang/graph"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// Extract returns a new Document with the elements rootIDs, and the
+// elements reachable from them by relationships of the given types. If no
+// types are given, CONTAINS and DEPENDS_ON are followed. Inverse and more
+// specific types are followed as well: DEPENDS_ON also follows
+// DEPENDENCY_OF and BUILD_DEPENDENCY_OF, as by graph.NormalizeType. A
+// package is always extracted with all of its Files, which its
+// verification code covers, a file with the package it belongs to, and a
+// snippet with its file.
+//
+// The new Document keeps the relationships and annotations between the
+// elements it has, and the OtherLicenses and ExternalDocumentReferences
+// they refer to. It DESCRIBES the roots, and has a new namespace made from that of
+// doc. doc is not changed.
+func Extract(doc *spdx.Document, rootIDs []common.ElementID, relationshipTypes []string) (*spdx.Document, error) {
+	if len(rootIDs) == 0 {
+		return nil, fmt.Errorf("no elements to extract")
+	}
+	idx := NewIndex(doc)
+	for _, id := range rootIDs {
+		e := idx.Lookup(id)
+		if e == nil || e.Kind == ElementDocument {
+			return nil, fmt.Errorf("%s is not an element of the document", id)
+		}
+	}
+
+	if len(relationshipTypes) == 0 {
+		relationshipTypes = []string{common.TypeRelationshipContains, common.TypeRelationshipDependsOn}
+	}
+	traversal := graph.Traversal{}
+	for _, relType := range relationshipTypes {
+		normalized, _ := graph.NormalizeType(relType)
+		traversal.Types = append(traversal.Types, normalized)
+	}
+
+	g := graph.New(doc)
+	included := map[common.ElementID]bool{}
+	for _, id := range rootIDs {
+		included[id] = true
+		for _, reached := range g.Reachable(common.MakeDocElementID("", string(id)), traversal) {
+			if reached.DocumentRefID == "" && reached.SpecialID == "" {
+				included[reached.ElementRefID] = true
+			}
+		}
+	}
+	// a snippet is extracted with its file
+	for id := range included {
+		if e := idx.Lookup(id); e != nil && e.Kind == ElementSnippet {
+			if e.ParentFile != nil {
+				included[e.ParentFile.FileSPDXIdentifier] = true
+			} else {
+				included[e.Snippet.SnippetFromFileSPDXIdentifier] = true
+			}
+		}
+	}
+	// a file is extracted with its package, and a package with its files
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		for _, f := range pkg.Files {
+			if f != nil && included[f.FileSPDXIdentifier] {
+				included[pkg.PackageSPDXIdentifier] = true
+			}
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if pkg == nil || !included[pkg.PackageSPDXIdentifier] {
+			continue
+		}
+		for _, f := range pkg.Files {
+			if f != nil {
+				included[f.FileSPDXIdentifier] = true
+			}
+		}
+	}
+	// snippets are part of their files
+	for _, s := range doc.Snippets {
+		if included[s.SnippetFromFileSPDXIdentifier] {
+			included[s.SnippetSPDXIdentifier] = true
+		}
+	}
+
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	out := &spdx.Document{
+		SPDXVersion:       doc.SPDXVersion,
+		DataLicense:       doc.DataLicense,
+		SPDXIdentifier:    doc.SPDXIdentifier,
+		DocumentName:      doc.DocumentName,
+		DocumentNamespace: doc.DocumentNamespace + "-" + uuid,
+		DocumentComment:   doc.DocumentComment,
+	}
+	if doc.CreationInfo != nil {
+		ci := *doc.CreationInfo
+		ci.Created = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+		out.CreationInfo = &ci
+	}
+
+	licenses := map[string]bool{}
+	docRefs := map[common.DocumentID]bool{}
+	useLicenses := func(exprs ...string) {
+		for _, expr := range exprs {
+			for _, ref := range licenseRefRegexp.FindAllStringSubmatch(expr, -1) {
+				if ref[1] == "" {
+					licenses[ref[0]] = true
+				} else {
+					docRefs[common.DocumentID(ref[1][len("DocumentRef-"):len(ref[1])-1])] = true
+				}
+			}
+		}
+	}
+	useDocElementID := func(id common.DocElementID) {
+		if id.DocumentRefID != "" {
+			docRefs[id.DocumentRefID] = true
+		}
+	}
+	// emitted holds the IDs of the elements of the new Document; an ID
+	// reached through a relationship need not be that of an element
+	emitted := map[common.ElementID]bool{doc.SPDXIdentifier: true}
+	copyFile := func(f *spdx.File) *spdx.File {
+		emitted[f.FileSPDXIdentifier] = true
+		copied := *f
+		if f.Snippets != nil {
+			copied.Snippets = map[common.ElementID]*spdx.Snippet{}
+			for id, s := range f.Snippets {
+				if s == nil {
+					continue
+				}
+				snippet := *s
+				copied.Snippets[id] = &snippet
+				emitted[s.SnippetSPDXIdentifier] = true
+				useLicenses(append([]string{s.SnippetLicenseConcluded}, s.LicenseInfoInSnippet...)...)
+			}
+		}
+		useLicenses(append([]string{f.LicenseConcluded}, f.LicenseInfoInFiles...)...)
+		for _, a := range f.Annotations {
+			useDocElementID(a.AnnotationSPDXIdentifier)
+		}
+		return &copied
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil || !included[pkg.PackageSPDXIdentifier] {
+			continue
+		}
+		emitted[pkg.PackageSPDXIdentifier] = true
+		copied := *pkg
+		copied.Files = nil
+		for _, f := range pkg.Files {
+			if f != nil && included[f.FileSPDXIdentifier] {
+				copied.Files = append(copied.Files, copyFile(f))
+			}
+		}
+		useLicenses(append([]string{pkg.PackageLicenseConcluded, pkg.PackageLicenseDeclared}, pkg.PackageLicenseInfoFromFiles...)...)
+		for _, a := range pkg.Annotations {
+			useDocElementID(a.AnnotationSPDXIdentifier)
+		}
+		out.Packages = append(out.Packages, &copied)
+	}
+	for _, f := range doc.Files {
+		if f != nil && included[f.FileSPDXIdentifier] {
+			out.Files = append(out.Files, copyFile(f))
+		}
+	}
+	for _, s := range doc.Snippets {
+		if included[s.SnippetSPDXIdentifier] {
+			useLicenses(append([]string{s.SnippetLicenseConcluded}, s.LicenseInfoInSnippet...)...)
+			emitted[s.SnippetSPDXIdentifier] = true
+			out.Snippets = append(out.Snippets, s)
+		}
+	}
+
+	// a relationship is kept if each of its elements is in the new
+	// Document, or is in another document, or is NONE or NOASSERTION
+	keeps := func(id common.DocElementID) bool {
+		return id.DocumentRefID != "" || id.SpecialID != "" || emitted[id.ElementRefID]
+	}
+	for _, id := range rootIDs {
+		out.Relationships = append(out.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", string(doc.SPDXIdentifier)),
+			RefB:         common.MakeDocElementID("", string(id)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+	for _, rln := range doc.Relationships {
+		if rln == nil || !keeps(rln.RefA) || !keeps(rln.RefB) {
+			continue
+		}
+		relType, reversed := graph.NormalizeType(rln.Relationship)
+		subject := rln.RefA
+		if reversed {
+			subject = rln.RefB
+		}
+		if relType == common.TypeRelationshipDescribe && subject.ElementRefID == doc.SPDXIdentifier && subject.DocumentRefID == "" {
+			// replaced by the relationships describing the roots
+			continue
+		}
+		copied := *rln
+		useDocElementID(copied.RefA)
+		useDocElementID(copied.RefB)
+		out.Relationships = append(out.Relationships, &copied)
+	}
+	for _, a := range doc.Annotations {
+		if a == nil || !keeps(a.AnnotationSPDXIdentifier) {
+			continue
+		}
+		copied := *a
+		useDocElementID(copied.AnnotationSPDXIdentifier)
+		out.Annotations = append(out.Annotations, &copied)
+	}
+
+	for _, lic := range doc.OtherLicenses {
+		if lic != nil && licenses[lic.LicenseIdentifier] {
+			copied := *lic
+			out.OtherLicenses = append(out.OtherLicenses, &copied)
+		}
+	}
+	for _, ref := range doc.ExternalDocumentReferences {
+		if docRefs[ref.DocumentRefID] {
+			out.ExternalDocumentReferences = append(out.ExternalDocumentReferences, ref)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// makeExtractTestDocument returns a document describing a product, which
+// contains app and docs; app depends on lib, and lib.c of lib has a
+// snippet.
+func makeExtractTestDocument() *spdx.Document {
+	rln := func(a, relType, b string) *spdx.Relationship {
+		return &spdx.Relationship{RefA: common.MakeDocElementID("", a), RefB: common.MakeDocElementID("", b), Relationship: relType}
+	}
+	return &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      "product",
+		DocumentNamespace: "https://example.com/product",
+		CreationInfo:      &spdx.CreationInfo{Creators: []common.Creator{{Creator: "test", CreatorType: "Tool"}}},
+		ExternalDocumentReferences: []spdx.ExternalDocumentRef{
+			{DocumentRefID: "zlib", URI: "https://example.com/zlib"},
+			{DocumentRefID: "unused", URI: "https://example.com/unused"},
+		},
+		Packages: []*spdx.Package{
+			{PackageName: "product", PackageSPDXIdentifier: "product"},
+			{PackageName: "app", PackageSPDXIdentifier: "app", PackageLicenseDeclared: "MIT AND LicenseRef-app"},
+			{PackageName: "lib", PackageSPDXIdentifier: "lib", Files: []*spdx.File{{
+				FileName:           "./lib.c",
+				FileSPDXIdentifier: "lib.c",
+				LicenseInfoInFiles: []string{"LicenseRef-lib"},
+			}}},
+			{PackageName: "docs", PackageSPDXIdentifier: "docs", PackageLicenseDeclared: "LicenseRef-docs"},
+		},
+		Snippets: []spdx.Snippet{
+			{SnippetSPDXIdentifier: "snippet", SnippetFromFileSPDXIdentifier: "lib.c"},
+		},
+		OtherLicenses: []*spdx.OtherLicense{
+			{LicenseIdentifier: "LicenseRef-app"},
+			{LicenseIdentifier: "LicenseRef-lib"},
+			{LicenseIdentifier: "LicenseRef-docs"},
+		},
+		Relationships: []*spdx.Relationship{
+			rln("DOCUMENT", common.TypeRelationshipDescribe, "product"),
+			rln("product", common.TypeRelationshipContains, "app"),
+			rln("docs", common.TypeRelationshipContainedBy, "product"),
+			rln("lib", common.TypeRelationshipDependencyOf, "app"),
+			{RefA: common.MakeDocElementID("", "lib"), RefB: common.MakeDocElementID("zlib", "zlib"), Relationship: common.TypeRelationshipDependsOn},
+			rln("docs", common.TypeRelationshipDocumentationOf, "app"),
+		},
+		Annotations: []*spdx.Annotation{
+			{AnnotationSPDXIdentifier: common.MakeDocElementID("", "lib"), AnnotationComment: "lib"},
+			{AnnotationSPDXIdentifier: common.MakeDocElementID("", "docs"), AnnotationComment: "docs"},
+		},
+	}
+}
+
+func TestExtractKeepsReachableElements(t *testing.T) {
+	doc := makeExtractTestDocument()
+	extracted, err := Extract(doc, []common.ElementID{"app"}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	ids := []string{}
+	for _, pkg := range extracted.Packages {
+		ids = append(ids, string(pkg.PackageSPDXIdentifier))
+	}
+	if strings.Join(ids, " ") != "app lib" {
+		t.Errorf("expected %v, got %v", "app lib", ids)
+	}
+	if len(extracted.Packages[1].Files) != 1 || len(extracted.Snippets) != 1 {
+		t.Errorf("expected lib.c and its snippet, got %+v and %+v", extracted.Packages[1].Files, extracted.Snippets)
+	}
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-app, SPDXRef-lib DEPENDENCY_OF SPDXRef-app, SPDXRef-lib DEPENDS_ON DocumentRef-zlib:SPDXRef-zlib"
+	if got := relationshipStrings(extracted.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(extracted.Annotations) != 1 || extracted.Annotations[0].AnnotationComment != "lib" {
+		t.Errorf("expected annotation of lib, got %+v", extracted.Annotations)
+	}
+	licenses := []string{}
+	for _, lic := range extracted.OtherLicenses {
+		licenses = append(licenses, lic.LicenseIdentifier)
+	}
+	if strings.Join(licenses, " ") != "LicenseRef-app LicenseRef-lib" {
+		t.Errorf("expected %v, got %v", "LicenseRef-app LicenseRef-lib", licenses)
+	}
+	if len(extracted.ExternalDocumentReferences) != 1 || extracted.ExternalDocumentReferences[0].DocumentRefID != "zlib" {
+		t.Errorf("expected reference to zlib, got %+v", extracted.ExternalDocumentReferences)
+	}
+	if extracted.DocumentNamespace == doc.DocumentNamespace || !strings.HasPrefix(extracted.DocumentNamespace, doc.DocumentNamespace) {
+		t.Errorf("expected new namespace, got %v", extracted.DocumentNamespace)
+	}
+
+	extracted.Packages[0].PackageName = "changed"
+	if doc.Packages[1].PackageName != "app" {
+		t.Errorf("expected input document not to change")
+	}
+}
+
+func TestExtractFollowsGivenTypes(t *testing.T) {
+	doc := makeExtractTestDocument()
+	extracted, err := Extract(doc, []common.ElementID{"product"}, []string{common.TypeRelationshipContainedBy})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ids := []string{}
+	for _, pkg := range extracted.Packages {
+		ids = append(ids, string(pkg.PackageSPDXIdentifier))
+	}
+	if strings.Join(ids, " ") != "product app docs" {
+		t.Errorf("expected %v, got %v", "product app docs", ids)
+	}
+
+	if _, err := Extract(doc, []common.ElementID{"missing"}, nil); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+	if _, err := Extract(doc, nil, nil); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
+
+func TestExtractKeepsPackagesWithTheirFiles(t *testing.T) {
+	doc := makeExtractTestDocument()
+	// app depends on lib.c, but not on lib
+	doc.Relationships[3] = &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", "app"),
+		RefB:         common.MakeDocElementID("", "lib.c"),
+		Relationship: common.TypeRelationshipDependsOn,
+	}
+	extracted, err := Extract(doc, []common.ElementID{"app"}, []string{common.TypeRelationshipDependsOn})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ids := []string{}
+	for _, pkg := range extracted.Packages {
+		ids = append(ids, string(pkg.PackageSPDXIdentifier))
+	}
+	if strings.Join(ids, " ") != "app lib" {
+		t.Errorf("expected %v, got %v", "app lib", ids)
+	}
+	if len(extracted.Packages) == 2 && len(extracted.Packages[1].Files) != 1 {
+		t.Errorf("expected lib.c in lib, got %+v", extracted.Packages[1].Files)
+	}
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-app, SPDXRef-app DEPENDS_ON SPDXRef-lib.c, SPDXRef-lib DEPENDS_ON DocumentRef-zlib:SPDXRef-zlib"
+	if got := relationshipStrings(extracted.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestExtractKeepsSnippetsWithTheirFiles(t *testing.T) {
+	doc := makeExtractTestDocument()
+	extracted, err := Extract(doc, []common.ElementID{"snippet"}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ids := []string{}
+	for _, pkg := range extracted.Packages {
+		ids = append(ids, string(pkg.PackageSPDXIdentifier))
+	}
+	if strings.Join(ids, " ") != "lib" {
+		t.Errorf("expected %v, got %v", "lib", ids)
+	}
+	if len(extracted.Packages) == 1 && len(extracted.Packages[0].Files) != 1 {
+		t.Errorf("expected lib.c in lib, got %+v", extracted.Packages[0].Files)
+	}
+	if len(extracted.Snippets) != 1 {
+		t.Errorf("expected snippet, got %+v", extracted.Snippets)
+	}
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-snippet, SPDXRef-lib DEPENDS_ON DocumentRef-zlib:SPDXRef-zlib"
+	if got := relationshipStrings(extracted.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestExtractResultIsValid(t *testing.T) {
+	rln := func(a, relType, b string) *spdx.Relationship {
+		return &spdx.Relationship{RefA: common.MakeDocElementID("", a), RefB: common.MakeDocElementID("", b), Relationship: relType}
+	}
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      "app",
+		DocumentNamespace: "https://example.com/app",
+		Packages: []*spdx.Package{
+			{
+				PackageName:             "app",
+				PackageSPDXIdentifier:   "app",
+				FilesAnalyzed:           true,
+				PackageVerificationCode: &common.PackageVerificationCode{Value: "d6a770ba38583ed4bb4525bd96e50461655d2758"},
+				Files: []*spdx.File{
+					{FileName: "./main.go", FileSPDXIdentifier: "main.go"},
+					{FileName: "./util.go", FileSPDXIdentifier: "util.go"},
+				},
+			},
+			{PackageName: "lib", PackageSPDXIdentifier: "lib"},
+			{PackageName: "tool", PackageSPDXIdentifier: "tool"},
+		},
+		Relationships: []*spdx.Relationship{
+			rln("DOCUMENT", common.TypeRelationshipDescribe, "app"),
+			rln("app", common.TypeRelationshipDependsOn, "lib"),
+			// a dangling reference, which is not an element of the document
+			rln("lib", common.TypeRelationshipDependsOn, "missing"),
+			rln("tool", common.TypeRelationshipDependsOn, "lib"),
+		},
+	}
+	if err := ValidateDocument(doc); err == nil {
+		t.Fatalf("expected the dangling reference to be invalid")
+	}
+
+	extracted, err := Extract(doc, []common.ElementID{"app"}, []string{common.TypeRelationshipDependsOn})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if err := ValidateDocument(extracted); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+	if len(extracted.Packages) != 2 || len(extracted.Packages[0].Files) != 2 {
+		t.Errorf("expected app with its files and lib, got %+v", extracted.Packages)
+	}
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-app, SPDXRef-app DEPENDS_ON SPDXRef-lib"
+	if got := relationshipStrings(extracted.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
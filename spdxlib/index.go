@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ElementKind is the kind of an element of a Document.
+type ElementKind string
+
+// Element kinds
+const (
+	ElementDocument ElementKind = "DOCUMENT"
+	ElementPackage  ElementKind = "PACKAGE"
+	ElementFile     ElementKind = "FILE"
+	ElementSnippet  ElementKind = "SNIPPET"
+)
+
+// Element is an element of a Document, found by an Index. Only the field
+// for its Kind is set, along with its parent, if any.
+type Element struct {
+	Kind     ElementKind
+	ID       common.ElementID
+	Document *spdx.Document
+	Package  *spdx.Package
+	File     *spdx.File
+	Snippet  *spdx.Snippet
+
+	// ParentPackage is the package whose Files include a file, or nil for
+	// one of the Document's Files.
+	ParentPackage *spdx.Package
+	// ParentFile is the file whose Snippets include a snippet, or nil for
+	// one of the Document's Snippets.
+	ParentFile *spdx.File
+}
+
+// Index looks up the elements of a Document by their identifiers. It
+// reflects the Document as it was when the Index was built; after
+// changing the Document, call Rebuild.
+type Index struct {
+	doc          *spdx.Document
+	elements     map[common.ElementID][]Element
+	filePackages map[common.ElementID][]*spdx.Package
+	checksums    map[common.Checksum][]*spdx.File
+}
+
+// NewIndex builds an Index of doc.
+func NewIndex(doc *spdx.Document) *Index {
+	idx := &Index{doc: doc}
+	idx.Rebuild()
+	return idx
+}
+
+// Rebuild indexes the Document again, after it has been changed.
+func (idx *Index) Rebuild() {
+	idx.elements = map[common.ElementID][]Element{}
+	idx.filePackages = map[common.ElementID][]*spdx.Package{}
+	idx.checksums = map[common.Checksum][]*spdx.File{}
+	doc := idx.doc
+	if doc == nil {
+		return
+	}
+
+	idx.add(Element{Kind: ElementDocument, ID: doc.SPDXIdentifier, Document: doc})
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		idx.add(Element{Kind: ElementPackage, ID: pkg.PackageSPDXIdentifier, Package: pkg})
+		for _, f := range pkg.Files {
+			if f != nil {
+				idx.addFile(f, pkg)
+			}
+		}
+	}
+	for _, f := range doc.Files {
+		if f != nil {
+			idx.addFile(f, nil)
+		}
+	}
+	for i := range doc.Snippets {
+		s := &doc.Snippets[i]
+		idx.add(Element{Kind: ElementSnippet, ID: s.SnippetSPDXIdentifier, Snippet: s})
+	}
+
+	// a file may also belong to a package by a CONTAINS or CONTAINED_BY
+	// relationship, rather than by being one of its Files
+	for _, rln := range doc.Relationships {
+		if rln == nil {
+			continue
+		}
+		relType, reversed := common.RelationshipType(rln.Relationship).Normalize()
+		if relType != common.TypeRelationshipContains {
+			continue
+		}
+		pkgRef, fileRef := rln.RefA, rln.RefB
+		if reversed {
+			pkgRef, fileRef = fileRef, pkgRef
+		}
+		pkg, file := idx.LookupDocElementID(pkgRef), idx.LookupDocElementID(fileRef)
+		if pkg == nil || pkg.Kind != ElementPackage || file == nil || file.Kind != ElementFile {
+			continue
+		}
+		idx.addFilePackage(file.ID, pkg.Package)
+	}
+}
+
+func (idx *Index) add(e Element) {
+	idx.elements[e.ID] = append(idx.elements[e.ID], e)
+}
+
+func (idx *Index) addFile(f *spdx.File, pkg *spdx.Package) {
+	// the same File may be listed by several packages
+	known := false
+	for _, e := range idx.elements[f.FileSPDXIdentifier] {
+		known = known || e.File == f
+	}
+	if !known {
+		idx.add(Element{Kind: ElementFile, ID: f.FileSPDXIdentifier, File: f, ParentPackage: pkg})
+		for _, c := range f.Checksums {
+			key := checksumKey(c)
+			idx.checksums[key] = append(idx.checksums[key], f)
+		}
+		// Snippets is a map, so add them in ID order
+		ids := []string{}
+		for id, s := range f.Snippets {
+			if s != nil {
+				ids = append(ids, string(id))
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			s := f.Snippets[common.ElementID(id)]
+			idx.add(Element{Kind: ElementSnippet, ID: s.SnippetSPDXIdentifier, Snippet: s, ParentFile: f})
+		}
+	}
+	if pkg != nil {
+		idx.addFilePackage(f.FileSPDXIdentifier, pkg)
+	}
+}
+
+func (idx *Index) addFilePackage(id common.ElementID, pkg *spdx.Package) {
+	for _, p := range idx.filePackages[id] {
+		if p == pkg {
+			return
+		}
+	}
+	idx.filePackages[id] = append(idx.filePackages[id], pkg)
+}
+
+// checksumKey normalizes the case of a checksum's hexadecimal value.
+func checksumKey(c common.Checksum) common.Checksum {
+	return common.Checksum{Algorithm: c.Algorithm, Value: strings.ToLower(c.Value)}
+}
+
+// Lookup returns the element with the given ID, or nil if there is none.
+// If several elements have the ID, the first, in document order, is
+// returned.
+func (idx *Index) Lookup(id common.ElementID) *Element {
+	if elements := idx.elements[id]; len(elements) > 0 {
+		e := elements[0]
+		return &e
+	}
+	return nil
+}
+
+// LookupDocElementID returns the element of the Document with the given
+// ID, as by Lookup. It returns nil for an element of another document, or
+// for NONE or NOASSERTION.
+func (idx *Index) LookupDocElementID(id common.DocElementID) *Element {
+	if id.DocumentRefID != "" || id.SpecialID != "" {
+		return nil
+	}
+	return idx.Lookup(id.ElementRefID)
+}
+
+// Package returns the package with the given ID, or nil if there is none.
+func (idx *Index) Package(id common.ElementID) *spdx.Package {
+	if e := idx.lookupKind(id, ElementPackage); e != nil {
+		return e.Package
+	}
+	return nil
+}
+
+// File returns the file with the given ID, or nil if there is none.
+func (idx *Index) File(id common.ElementID) *spdx.File {
+	if e := idx.lookupKind(id, ElementFile); e != nil {
+		return e.File
+	}
+	return nil
+}
+
+// Snippet returns the snippet with the given ID, or nil if there is none.
+func (idx *Index) Snippet(id common.ElementID) *spdx.Snippet {
+	if e := idx.lookupKind(id, ElementSnippet); e != nil {
+		return e.Snippet
+	}
+	return nil
+}
+
+func (idx *Index) lookupKind(id common.ElementID, kind ElementKind) *Element {
+	for _, e := range idx.elements[id] {
+		if e.Kind == kind {
+			return &e
+		}
+	}
+	return nil
+}
+
+// Duplicates returns the elements of each ID that is used by more than one
+// element, in document order.
+func (idx *Index) Duplicates() map[common.ElementID][]Element {
+	dups := map[common.ElementID][]Element{}
+	for id, elements := range idx.elements {
+		if len(elements) > 1 {
+			dups[id] = append([]Element{}, elements...)
+		}
+	}
+	return dups
+}
+
+// PackagesOfFile returns the packages that contain the file with the given
+// ID, either by listing it in their Files or by a CONTAINS (or
+// CONTAINED_BY) relationship.
+func (idx *Index) PackagesOfFile(id common.ElementID) []*spdx.Package {
+	return append([]*spdx.Package{}, idx.filePackages[id]...)
+}
+
+// FilesWithChecksum returns the files that have the given checksum. The
+// checksum's value is compared regardless of case.
+func (idx *Index) FilesWithChecksum(c common.Checksum) []*spdx.File {
+	return append([]*spdx.File{}, idx.checksums[checksumKey(c)]...)
+}
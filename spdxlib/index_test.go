@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func makeIndexTestDocument() *spdx.Document {
+	f1 := &spdx.File{
+		FileName:           "./f1.c",
+		FileSPDXIdentifier: "f1",
+		Checksums:          []common.Checksum{{Algorithm: common.SHA1, Value: "ABCD"}},
+		Snippets: map[common.ElementID]*spdx.Snippet{
+			"s1": {SnippetSPDXIdentifier: "s1"},
+		},
+	}
+	return &spdx.Document{
+		SPDXIdentifier: "DOCUMENT",
+		Packages: []*spdx.Package{
+			{PackageSPDXIdentifier: "p1", Files: []*spdx.File{f1}},
+			{PackageSPDXIdentifier: "p2", Files: []*spdx.File{f1}},
+			{PackageSPDXIdentifier: "p3"},
+		},
+		Files: []*spdx.File{
+			{
+				FileName:           "./f2.c",
+				FileSPDXIdentifier: "f2",
+				Checksums:          []common.Checksum{{Algorithm: common.SHA1, Value: "abcd"}},
+			},
+		},
+		Snippets: []spdx.Snippet{{SnippetSPDXIdentifier: "s2"}},
+		Relationships: []*spdx.Relationship{
+			{
+				RefA:         common.MakeDocElementID("", "f2"),
+				RefB:         common.MakeDocElementID("", "p3"),
+				Relationship: common.TypeRelationshipContainedBy,
+			},
+		},
+	}
+}
+
+func TestIndexLooksUpElements(t *testing.T) {
+	doc := makeIndexTestDocument()
+	idx := NewIndex(doc)
+
+	if e := idx.Lookup("DOCUMENT"); e == nil || e.Kind != ElementDocument || e.Document != doc {
+		t.Errorf("expected document, got %+v", e)
+	}
+	if pkg := idx.Package("p2"); pkg != doc.Packages[1] {
+		t.Errorf("expected %v, got %v", doc.Packages[1], pkg)
+	}
+	if e := idx.Lookup("f1"); e == nil || e.File != doc.Packages[0].Files[0] || e.ParentPackage != doc.Packages[0] {
+		t.Errorf("expected file of p1, got %+v", e)
+	}
+	if e := idx.Lookup("s1"); e == nil || e.Snippet != doc.Packages[0].Files[0].Snippets["s1"] || e.ParentFile != doc.Packages[0].Files[0] {
+		t.Errorf("expected snippet of f1, got %+v", e)
+	}
+	if s := idx.Snippet("s2"); s != &doc.Snippets[0] {
+		t.Errorf("expected %v, got %v", &doc.Snippets[0], s)
+	}
+	if e := idx.LookupDocElementID(common.MakeDocElementID("", "f2")); e == nil || e.File != doc.Files[0] || e.ParentPackage != nil {
+		t.Errorf("expected unpackaged file, got %+v", e)
+	}
+	if e := idx.LookupDocElementID(common.MakeDocElementID("other", "f2")); e != nil {
+		t.Errorf("expected nil for element of another document, got %+v", e)
+	}
+	if f := idx.File("p1"); f != nil {
+		t.Errorf("expected nil, got %+v", f)
+	}
+	if e := idx.Lookup("missing"); e != nil {
+		t.Errorf("expected nil, got %+v", e)
+	}
+	if dups := idx.Duplicates(); len(dups) != 0 {
+		t.Errorf("expected no duplicates, got %+v", dups)
+	}
+}
+
+func TestIndexFindsOwningPackagesAndChecksums(t *testing.T) {
+	doc := makeIndexTestDocument()
+	idx := NewIndex(doc)
+
+	if pkgs := idx.PackagesOfFile("f1"); len(pkgs) != 2 || pkgs[0] != doc.Packages[0] || pkgs[1] != doc.Packages[1] {
+		t.Errorf("expected p1 and p2, got %+v", pkgs)
+	}
+	if pkgs := idx.PackagesOfFile("f2"); len(pkgs) != 1 || pkgs[0] != doc.Packages[2] {
+		t.Errorf("expected p3, got %+v", pkgs)
+	}
+	files := idx.FilesWithChecksum(common.Checksum{Algorithm: common.SHA1, Value: "abcd"})
+	if len(files) != 2 || files[0].FileSPDXIdentifier != "f1" || files[1].FileSPDXIdentifier != "f2" {
+		t.Errorf("expected f1 and f2, got %+v", files)
+	}
+	if files := idx.FilesWithChecksum(common.Checksum{Algorithm: common.SHA256, Value: "abcd"}); len(files) != 0 {
+		t.Errorf("expected no files, got %+v", files)
+	}
+}
+
+func TestIndexListsDuplicatesAfterRebuild(t *testing.T) {
+	doc := makeIndexTestDocument()
+	idx := NewIndex(doc)
+	doc.Files = append(doc.Files, &spdx.File{FileSPDXIdentifier: "p1"})
+	if dups := idx.Duplicates(); len(dups) != 0 {
+		t.Errorf("expected no duplicates before rebuild, got %+v", dups)
+	}
+
+	idx.Rebuild()
+	dups := idx.Duplicates()
+	if len(dups) != 1 || len(dups["p1"]) != 2 {
+		t.Fatalf("expected duplicates of p1, got %+v", dups)
+	}
+	if dups["p1"][0].Kind != ElementPackage || dups["p1"][1].Kind != ElementFile {
+		t.Errorf("expected package then file, got %v and %v", dups["p1"][0].Kind, dups["p1"][1].Kind)
+	}
+	if f := idx.File("p1"); f != doc.Files[1] {
+		t.Errorf("expected %v, got %v", doc.Files[1], f)
+	}
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenselist"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// LicenseRefUse is a use of a LicenseRef in a license field.
+type LicenseRefUse struct {
+	// LicenseRef is the LicenseRef used, such as "LicenseRef-foo".
+	LicenseRef string
+	// DocumentRef is the ID of the external document defining the
+	// LicenseRef, or empty if it is defined by this document.
+	DocumentRef common.DocumentID
+	// ElementID is the SPDXID of the package, file or snippet using it.
+	ElementID common.ElementID
+	// Field is the name of the license field using it, such as
+	// "PackageLicenseDeclared".
+	Field string
+}
+
+// licenseField is a license field of an element of a document, which may
+// be changed through value.
+type licenseField struct {
+	id    common.ElementID
+	field string
+	value *string
+}
+
+// licenseFields returns the license fields of doc's packages, files and
+// snippets, in document order.
+func licenseFields(doc *spdx.Document) []licenseField {
+	fields := []licenseField{}
+	add := func(id common.ElementID, field string, values ...*string) {
+		for _, v := range values {
+			fields = append(fields, licenseField{id, field, v})
+		}
+	}
+	addAll := func(id common.ElementID, field string, values []string) {
+		for i := range values {
+			add(id, field, &values[i])
+		}
+	}
+	addSnippet := func(s *spdx.Snippet) {
+		add(s.SnippetSPDXIdentifier, "SnippetLicenseConcluded", &s.SnippetLicenseConcluded)
+		addAll(s.SnippetSPDXIdentifier, "LicenseInfoInSnippet", s.LicenseInfoInSnippet)
+	}
+	addFile := func(f *spdx.File) {
+		add(f.FileSPDXIdentifier, "LicenseConcluded", &f.LicenseConcluded)
+		addAll(f.FileSPDXIdentifier, "LicenseInfoInFiles", f.LicenseInfoInFiles)
+		ids := []string{}
+		for id, s := range f.Snippets {
+			if s != nil {
+				ids = append(ids, string(id))
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			addSnippet(f.Snippets[common.ElementID(id)])
+		}
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		add(pkg.PackageSPDXIdentifier, "PackageLicenseConcluded", &pkg.PackageLicenseConcluded)
+		add(pkg.PackageSPDXIdentifier, "PackageLicenseDeclared", &pkg.PackageLicenseDeclared)
+		addAll(pkg.PackageSPDXIdentifier, "PackageLicenseInfoFromFiles", pkg.PackageLicenseInfoFromFiles)
+		for _, f := range pkg.Files {
+			if f != nil {
+				addFile(f)
+			}
+		}
+	}
+	for _, f := range doc.Files {
+		if f != nil {
+			addFile(f)
+		}
+	}
+	for i := range doc.Snippets {
+		addSnippet(&doc.Snippets[i])
+	}
+	return fields
+}
+
+// FindLicenseRefs returns every use of a LicenseRef in the license fields
+// of doc's packages, files and snippets, in document order.
+func FindLicenseRefs(doc *spdx.Document) []LicenseRefUse {
+	uses := []LicenseRefUse{}
+	for _, f := range licenseFields(doc) {
+		for _, ref := range licenseRefRegexp.FindAllString(*f.value, -1) {
+			use := LicenseRefUse{LicenseRef: ref, ElementID: f.id, Field: f.field}
+			if docRef, licenseRef, ok := strings.Cut(ref, ":"); ok {
+				use.DocumentRef = common.DocumentID(strings.TrimPrefix(docRef, "DocumentRef-"))
+				use.LicenseRef = licenseRef
+			}
+			uses = append(uses, use)
+		}
+	}
+	return uses
+}
+
+// LicenseRefCheck is the result of CheckLicenseRefs.
+type LicenseRefCheck struct {
+	// Undefined are the LicenseRefs used but not defined by an
+	// OtherLicense, sorted.
+	Undefined []string
+	// Unused are the LicenseRefs of OtherLicenses that are not used,
+	// sorted.
+	Unused []string
+	// Listed maps the LicenseRefs of OtherLicenses whose extracted text is
+	// that of an SPDX-listed license, as by licenselist.Match, to the ID of
+	// that license, which could replace them.
+	Listed map[string]string
+}
+
+// CheckLicenseRefs compares the LicenseRefs used by doc with those its
+// OtherLicenses define. LicenseRefs of other documents are not checked.
+func CheckLicenseRefs(doc *spdx.Document) *LicenseRefCheck {
+	check := &LicenseRefCheck{Undefined: []string{}, Unused: []string{}, Listed: map[string]string{}}
+	used := map[string]bool{}
+	for _, use := range FindLicenseRefs(doc) {
+		if use.DocumentRef == "" {
+			used[use.LicenseRef] = true
+		}
+	}
+	defined := map[string]bool{}
+	for _, lic := range doc.OtherLicenses {
+		if lic == nil {
+			continue
+		}
+		defined[lic.LicenseIdentifier] = true
+		if !used[lic.LicenseIdentifier] {
+			check.Unused = append(check.Unused, lic.LicenseIdentifier)
+		}
+		if id, ok := licenselist.Match(lic.ExtractedText); ok {
+			check.Listed[lic.LicenseIdentifier] = id
+		}
+	}
+	for ref := range used {
+		if !defined[ref] {
+			check.Undefined = append(check.Undefined, ref)
+		}
+	}
+	sort.Strings(check.Undefined)
+	sort.Strings(check.Unused)
+	return check
+}
+
+// licenseRefIDRegexp matches a LicenseRef of the document itself.
+var licenseRefIDRegexp = regexp.MustCompile(`^LicenseRef-[A-Za-z0-9.\-]+$`)
+
+// RenameLicenseRef renames the LicenseRef from to to throughout doc: in
+// its license fields and in the OtherLicense defining it, if any. It fails
+// if to is not a valid LicenseRef or is already defined by an
+// OtherLicense. LicenseRefs of other documents are not changed.
+func RenameLicenseRef(doc *spdx.Document, from string, to string) error {
+	if !licenseRefIDRegexp.MatchString(to) {
+		return fmt.Errorf("%q is not a valid LicenseRef", to)
+	}
+	for _, lic := range doc.OtherLicenses {
+		if lic != nil && lic.LicenseIdentifier == to && from != to {
+			return fmt.Errorf("%s is already defined", to)
+		}
+	}
+	renameLicenseRefs(doc, map[string]string{from: to})
+	return nil
+}
+
+// renameLicenseRefs renames the document's own LicenseRefs in its license
+// fields and OtherLicenses.
+func renameLicenseRefs(doc *spdx.Document, renamed map[string]string) {
+	r := &renames{licenses: renamed}
+	for _, f := range licenseFields(doc) {
+		*f.value = r.licenseExpression(*f.value)
+	}
+	for _, lic := range doc.OtherLicenses {
+		if lic == nil {
+			continue
+		}
+		if to, ok := renamed[lic.LicenseIdentifier]; ok {
+			lic.LicenseIdentifier = to
+		}
+	}
+}
+
+// DeduplicateOtherLicenses removes the OtherLicenses of doc whose
+// ExtractedText is identical to that of an earlier one, changing the uses
+// of their LicenseRefs to that of the earlier one. It returns the
+// LicenseRefs removed, mapped to those replacing them.
+func DeduplicateOtherLicenses(doc *spdx.Document) map[string]string {
+	renamed := map[string]string{}
+	byText := map[string]string{}
+	kept := []*spdx.OtherLicense{}
+	for _, lic := range doc.OtherLicenses {
+		if lic == nil {
+			continue
+		}
+		if first, ok := byText[lic.ExtractedText]; ok {
+			if first != lic.LicenseIdentifier {
+				renamed[lic.LicenseIdentifier] = first
+			}
+			continue
+		}
+		byText[lic.ExtractedText] = lic.LicenseIdentifier
+		kept = append(kept, lic)
+	}
+	if len(kept) != len(doc.OtherLicenses) {
+		doc.OtherLicenses = kept
+	}
+	renameLicenseRefs(doc, renamed)
+	return renamed
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/licenselist"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func makeLicenseRefTestDocument() *spdx.Document {
+	mit, _ := licenselist.Text("MIT")
+	return &spdx.Document{
+		Packages: []*spdx.Package{
+			{
+				PackageSPDXIdentifier:       "Package-a",
+				PackageLicenseConcluded:     "MIT AND LicenseRef-a",
+				PackageLicenseDeclared:      "LicenseRef-a OR DocumentRef-ext:LicenseRef-x",
+				PackageLicenseInfoFromFiles: []string{"LicenseRef-a", "LicenseRef-b"},
+				Files: []*spdx.File{
+					{
+						FileSPDXIdentifier: "File0",
+						LicenseConcluded:   "LicenseRef-b",
+						LicenseInfoInFiles: []string{"LicenseRef-b"},
+						Snippets: map[common.ElementID]*spdx.Snippet{
+							"Snippet0": {SnippetSPDXIdentifier: "Snippet0", SnippetLicenseConcluded: "LicenseRef-missing"},
+						},
+					},
+				},
+			},
+		},
+		Files: []*spdx.File{
+			{FileSPDXIdentifier: "File1", LicenseConcluded: "LicenseRef-c", LicenseInfoInFiles: []string{"NOASSERTION"}},
+		},
+		OtherLicenses: []*spdx.OtherLicense{
+			{LicenseIdentifier: "LicenseRef-a", ExtractedText: "license a"},
+			{LicenseIdentifier: "LicenseRef-b", ExtractedText: "Copyright (c) B\n\n" + mit},
+			{LicenseIdentifier: "LicenseRef-c", ExtractedText: "license a"},
+			{LicenseIdentifier: "LicenseRef-unused", ExtractedText: "unused"},
+		},
+	}
+}
+
+// ===== LicenseRef tests =====
+func TestFindLicenseRefsFindsEveryUse(t *testing.T) {
+	uses := []string{}
+	for _, use := range FindLicenseRefs(makeLicenseRefTestDocument()) {
+		uses = append(uses, fmt.Sprintf("%s/%s/%s.%s", use.DocumentRef, use.LicenseRef, use.ElementID, use.Field))
+	}
+	want := "[/LicenseRef-a/Package-a.PackageLicenseConcluded " +
+		"/LicenseRef-a/Package-a.PackageLicenseDeclared " +
+		"ext/LicenseRef-x/Package-a.PackageLicenseDeclared " +
+		"/LicenseRef-a/Package-a.PackageLicenseInfoFromFiles " +
+		"/LicenseRef-b/Package-a.PackageLicenseInfoFromFiles " +
+		"/LicenseRef-b/File0.LicenseConcluded " +
+		"/LicenseRef-b/File0.LicenseInfoInFiles " +
+		"/LicenseRef-missing/Snippet0.SnippetLicenseConcluded " +
+		"/LicenseRef-c/File1.LicenseConcluded]"
+	if fmt.Sprint(uses) != want {
+		t.Errorf("expected %v, got %v", want, uses)
+	}
+}
+
+func TestCheckLicenseRefsReportsUndefinedUnusedAndListed(t *testing.T) {
+	check := CheckLicenseRefs(makeLicenseRefTestDocument())
+	if want := "[LicenseRef-missing]"; fmt.Sprint(check.Undefined) != want {
+		t.Errorf("expected %v, got %v", want, check.Undefined)
+	}
+	if want := "[LicenseRef-unused]"; fmt.Sprint(check.Unused) != want {
+		t.Errorf("expected %v, got %v", want, check.Unused)
+	}
+	if want := "map[LicenseRef-b:MIT]"; fmt.Sprint(check.Listed) != want {
+		t.Errorf("expected %v, got %v", want, check.Listed)
+	}
+}
+
+func TestRenameLicenseRefRenamesEverywhere(t *testing.T) {
+	doc := makeLicenseRefTestDocument()
+	if err := RenameLicenseRef(doc, "LicenseRef-a", "LicenseRef-renamed"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	pkg := doc.Packages[0]
+	if pkg.PackageLicenseConcluded != "MIT AND LicenseRef-renamed" ||
+		pkg.PackageLicenseDeclared != "LicenseRef-renamed OR DocumentRef-ext:LicenseRef-x" ||
+		fmt.Sprint(pkg.PackageLicenseInfoFromFiles) != "[LicenseRef-renamed LicenseRef-b]" {
+		t.Errorf("unexpected package licenses %+v", pkg)
+	}
+	if doc.OtherLicenses[0].LicenseIdentifier != "LicenseRef-renamed" {
+		t.Errorf("expected %v, got %v", "LicenseRef-renamed", doc.OtherLicenses[0].LicenseIdentifier)
+	}
+
+	if err := RenameLicenseRef(doc, "LicenseRef-b", "LicenseRef-c"); err == nil {
+		t.Errorf("expected non-nil error renaming to a defined LicenseRef, got nil")
+	}
+	if err := RenameLicenseRef(doc, "LicenseRef-b", "MIT"); err == nil {
+		t.Errorf("expected non-nil error renaming to an invalid LicenseRef, got nil")
+	}
+}
+
+func TestDeduplicateOtherLicensesMergesIdenticalTexts(t *testing.T) {
+	doc := makeLicenseRefTestDocument()
+	renamed := DeduplicateOtherLicenses(doc)
+	if want := "map[LicenseRef-c:LicenseRef-a]"; fmt.Sprint(renamed) != want {
+		t.Errorf("expected %v, got %v", want, renamed)
+	}
+	if len(doc.OtherLicenses) != 3 {
+		t.Errorf("expected %d, got %d", 3, len(doc.OtherLicenses))
+	}
+	if doc.Files[0].LicenseConcluded != "LicenseRef-a" {
+		t.Errorf("expected %v, got %v", "LicenseRef-a", doc.Files[0].LicenseConcluded)
+	}
+}
@@ -0,0 +1,537 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	// DocumentName is the name of the merged document. If empty, the
+	// names of the documents merged are joined with "+".
+	DocumentName string
+
+	// NamespacePrefix is the prefix of the merged document's namespace,
+	// which is followed by its name and a random UUID. If empty,
+	// "https://spdx.org/spdxdocs/" is used.
+	NamespacePrefix string
+
+	// Creators are added before the creators of the documents merged.
+	Creators []common.Creator
+
+	// Created is the creation time of the merged document, in the format
+	// YYYY-MM-DDThh:mm:ssZ. If empty, the current time is used.
+	Created string
+
+	// KeepDuplicatePackages copies every package of the documents merged,
+	// rather than merging the packages that have the same purl, the same
+	// name and version, or the same checksum.
+	KeepDuplicatePackages bool
+
+	// ReferenceInputs makes the merged document refer to each of the
+	// documents merged as an ExternalDocumentReference, describing the
+	// packages they describe, rather than copying their elements.
+	ReferenceInputs bool
+
+	// InputChecksum returns the checksum of a document referred to by
+	// ReferenceInputs, which should be the checksum of the file it was
+	// read from. If nil, the SHA1 of the document's JSON encoding is used.
+	InputChecksum func(doc *spdx.Document) (common.Checksum, error)
+}
+
+// Merge returns a new Document combining docs, which are not changed:
+//   - Each element is copied into the new Document. If its SPDXID is used
+//     by an element of an earlier document, the element is given a new
+//     SPDXID, with a suffix such as "-2", and references to it in its
+//     document are changed to match. Each document's own SPDXID refers to
+//     the new Document.
+//   - Packages with the same purl, the same name and version, or the same
+//     checksum as a package of an earlier document are merged into it,
+//     adding any files it lacks by name; see KeepDuplicatePackages.
+//   - OtherLicenses with the same extracted text are merged, and license
+//     identifiers are renamed like SPDXIDs if they clash.
+//   - External document references are merged by URI.
+//   - Creators, relationships and annotations are combined, leaving out
+//     duplicates, and the new Document DESCRIBES the packages each
+//     document describes.
+//
+// If opts.ReferenceInputs is set, the documents are instead referred to
+// as ExternalDocumentReferences.
+func Merge(docs []*spdx.Document, opts *MergeOptions) (*spdx.Document, error) {
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents to merge")
+	}
+
+	names := []string{}
+	for i, doc := range docs {
+		if doc == nil {
+			return nil, fmt.Errorf("document %d is nil", i)
+		}
+		names = append(names, doc.DocumentName)
+	}
+	name := opts.DocumentName
+	if name == "" {
+		name = strings.Join(names, "+")
+	}
+	prefix := opts.NamespacePrefix
+	if prefix == "" {
+		prefix = "https://spdx.org/spdxdocs/"
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	created := opts.Created
+	if created == "" {
+		created = time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	}
+
+	m := &merger{
+		opts: opts,
+		out: &spdx.Document{
+			SPDXVersion:       spdx.Version,
+			DataLicense:       spdx.DataLicense,
+			SPDXIdentifier:    "DOCUMENT",
+			DocumentName:      name,
+			DocumentNamespace: utils.MakeDocumentNamespace(prefix, name, uuid),
+			CreationInfo:      &spdx.CreationInfo{Created: created},
+		},
+		ids:            utils.NewElementIDGenerator(),
+		docRefIDs:      utils.NewElementIDGenerator(),
+		licenseIDs:     utils.NewElementIDGenerator(),
+		docRefsByURI:   map[string]common.DocumentID{},
+		licensesByText: map[string]string{},
+		packagesByKey:  map[string]*spdx.Package{},
+		seen:           map[interface{}]bool{},
+	}
+	m.ids.Reserve("DOCUMENT")
+	m.addCreators(opts.Creators)
+
+	for _, doc := range docs {
+		if doc.CreationInfo != nil {
+			m.addCreators(doc.CreationInfo.Creators)
+			if m.out.CreationInfo.LicenseListVersion == "" {
+				m.out.CreationInfo.LicenseListVersion = doc.CreationInfo.LicenseListVersion
+			}
+		}
+		if opts.ReferenceInputs {
+			err = m.referenceDocument(doc)
+		} else {
+			err = m.mergeDocument(doc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.out, nil
+}
+
+// merger holds the state of a Merge.
+type merger struct {
+	opts *MergeOptions
+	out  *spdx.Document
+
+	ids        *utils.ElementIDGenerator
+	docRefIDs  *utils.ElementIDGenerator
+	licenseIDs *utils.ElementIDGenerator
+
+	docRefsByURI   map[string]common.DocumentID
+	licensesByText map[string]string
+	packagesByKey  map[string]*spdx.Package
+	// seen holds the creators, relationships and annotations added
+	seen map[interface{}]bool
+}
+
+// renames maps the identifiers of one of the documents merged to those of
+// the merged document.
+type renames struct {
+	ids      map[common.ElementID]common.ElementID
+	docRefs  map[common.DocumentID]common.DocumentID
+	licenses map[string]string
+}
+
+// relationshipKey identifies a relationship regardless of its comment.
+type relationshipKey struct {
+	refA, refB   common.DocElementID
+	relationship string
+}
+
+func (m *merger) addCreators(creators []common.Creator) {
+	for _, c := range creators {
+		if !m.seen[c] {
+			m.seen[c] = true
+			m.out.CreationInfo.Creators = append(m.out.CreationInfo.Creators, c)
+		}
+	}
+}
+
+func (m *merger) addRelationship(rln *spdx.Relationship) {
+	key := relationshipKey{rln.RefA, rln.RefB, rln.Relationship}
+	if !m.seen[key] {
+		m.seen[key] = true
+		m.out.Relationships = append(m.out.Relationships, rln)
+	}
+}
+
+// allocate returns id if it is not yet used in the merged document, and
+// otherwise a new ID made from it.
+func allocate(gen *utils.ElementIDGenerator, id string) string {
+	if gen.Reserve(common.ElementID(id)) {
+		return id
+	}
+	return string(gen.Generate(id))
+}
+
+// describeDocument adds a DESCRIBES relationship from the merged document
+// to each package doc describes, in the document docRef (or in the merged
+// document itself, if docRef is empty).
+func (m *merger) describeDocument(doc *spdx.Document, docRef common.DocumentID, r *renames) {
+	described, err := GetDescribedPackageIDs(doc)
+	if err != nil {
+		// a document without packages describes only itself
+		if docRef != "" {
+			m.addRelationship(&spdx.Relationship{
+				RefA:         common.MakeDocElementID("", "DOCUMENT"),
+				RefB:         common.MakeDocElementID(string(docRef), string(doc.SPDXIdentifier)),
+				Relationship: common.TypeRelationshipDescribe,
+			})
+		}
+		return
+	}
+	for _, id := range described {
+		if r != nil {
+			id = r.id(id)
+		}
+		m.addRelationship(&spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID(string(docRef), string(id)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+}
+
+// referenceDocument adds doc as an ExternalDocumentReference.
+func (m *merger) referenceDocument(doc *spdx.Document) error {
+	if doc.DocumentNamespace == "" {
+		return fmt.Errorf("document %s has no namespace to refer to", doc.DocumentName)
+	}
+	docRef, ok := m.docRefsByURI[doc.DocumentNamespace]
+	if !ok {
+		checksum, err := m.inputChecksum(doc)
+		if err != nil {
+			return err
+		}
+		docRef = common.DocumentID(allocate(m.docRefIDs, utils.SanitizeElementID(doc.DocumentName)))
+		m.docRefsByURI[doc.DocumentNamespace] = docRef
+		m.out.ExternalDocumentReferences = append(m.out.ExternalDocumentReferences, spdx.ExternalDocumentRef{
+			DocumentRefID: docRef,
+			URI:           doc.DocumentNamespace,
+			Checksum:      checksum,
+		})
+	}
+	m.describeDocument(doc, docRef, nil)
+	return nil
+}
+
+func (m *merger) inputChecksum(doc *spdx.Document) (common.Checksum, error) {
+	if m.opts.InputChecksum != nil {
+		return m.opts.InputChecksum(doc)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return common.Checksum{}, err
+	}
+	return common.Checksum{Algorithm: common.SHA1, Value: fmt.Sprintf("%x", sha1.Sum(data))}, nil
+}
+
+// mergeDocument copies the elements of doc into the merged document.
+func (m *merger) mergeDocument(doc *spdx.Document) error {
+	r := &renames{
+		ids:      map[common.ElementID]common.ElementID{doc.SPDXIdentifier: "DOCUMENT"},
+		docRefs:  map[common.DocumentID]common.DocumentID{},
+		licenses: map[string]string{},
+	}
+
+	for _, ref := range doc.ExternalDocumentReferences {
+		if id, ok := m.docRefsByURI[ref.URI]; ok {
+			r.docRefs[ref.DocumentRefID] = id
+			continue
+		}
+		id := common.DocumentID(allocate(m.docRefIDs, string(ref.DocumentRefID)))
+		r.docRefs[ref.DocumentRefID] = id
+		m.docRefsByURI[ref.URI] = id
+		ref.DocumentRefID = id
+		m.out.ExternalDocumentReferences = append(m.out.ExternalDocumentReferences, ref)
+	}
+
+	for _, lic := range doc.OtherLicenses {
+		if lic == nil {
+			continue
+		}
+		if id, ok := m.licensesByText[lic.ExtractedText]; ok {
+			r.licenses[lic.LicenseIdentifier] = id
+			continue
+		}
+		copied := *lic
+		copied.LicenseIdentifier = allocate(m.licenseIDs, lic.LicenseIdentifier)
+		r.licenses[lic.LicenseIdentifier] = copied.LicenseIdentifier
+		m.licensesByText[lic.ExtractedText] = copied.LicenseIdentifier
+		m.out.OtherLicenses = append(m.out.OtherLicenses, &copied)
+	}
+
+	// first give every element its ID, so that references to elements
+	// later in the document can be changed
+	newPackages, newFiles, newSnippets := []*spdx.Package{}, []*spdx.File{}, []*spdx.Snippet{}
+	copyFile := func(f *spdx.File) *spdx.File {
+		copied := *f
+		copied.FileSPDXIdentifier = common.ElementID(allocate(m.ids, string(f.FileSPDXIdentifier)))
+		r.ids[f.FileSPDXIdentifier] = copied.FileSPDXIdentifier
+		if f.Snippets != nil {
+			copied.Snippets = map[common.ElementID]*spdx.Snippet{}
+			for _, id := range sortedSnippetIDs(f.Snippets) {
+				s := *f.Snippets[id]
+				s.SnippetSPDXIdentifier = common.ElementID(allocate(m.ids, string(id)))
+				r.ids[id] = s.SnippetSPDXIdentifier
+				copied.Snippets[s.SnippetSPDXIdentifier] = &s
+				newSnippets = append(newSnippets, &s)
+			}
+		}
+		newFiles = append(newFiles, &copied)
+		return &copied
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		keys := packageKeys(pkg)
+		if kept := m.findPackage(keys); kept != nil {
+			r.ids[pkg.PackageSPDXIdentifier] = kept.PackageSPDXIdentifier
+			m.mergePackageFiles(kept, pkg, r, copyFile)
+			continue
+		}
+		copied := *pkg
+		copied.PackageSPDXIdentifier = common.ElementID(allocate(m.ids, string(pkg.PackageSPDXIdentifier)))
+		r.ids[pkg.PackageSPDXIdentifier] = copied.PackageSPDXIdentifier
+		copied.Files = nil
+		for _, f := range pkg.Files {
+			if f != nil {
+				copied.Files = append(copied.Files, copyFile(f))
+			}
+		}
+		copied.PackageExternalReferences = nil
+		for _, ref := range pkg.PackageExternalReferences {
+			if ref != nil {
+				copiedRef := *ref
+				copied.PackageExternalReferences = append(copied.PackageExternalReferences, &copiedRef)
+			}
+		}
+		if !m.opts.KeepDuplicatePackages {
+			for _, key := range keys {
+				if m.packagesByKey[key] == nil {
+					m.packagesByKey[key] = &copied
+				}
+			}
+		}
+		newPackages = append(newPackages, &copied)
+		m.out.Packages = append(m.out.Packages, &copied)
+	}
+	for _, f := range doc.Files {
+		if f != nil {
+			m.out.Files = append(m.out.Files, copyFile(f))
+		}
+	}
+	firstSnippet := len(m.out.Snippets)
+	for _, s := range doc.Snippets {
+		id := common.ElementID(allocate(m.ids, string(s.SnippetSPDXIdentifier)))
+		r.ids[s.SnippetSPDXIdentifier] = id
+		s.SnippetSPDXIdentifier = id
+		m.out.Snippets = append(m.out.Snippets, s)
+	}
+	for i := firstSnippet; i < len(m.out.Snippets); i++ {
+		newSnippets = append(newSnippets, &m.out.Snippets[i])
+	}
+
+	// then change the references of the copies
+	for _, pkg := range newPackages {
+		pkg.PackageLicenseConcluded = r.licenseExpression(pkg.PackageLicenseConcluded)
+		pkg.PackageLicenseDeclared = r.licenseExpression(pkg.PackageLicenseDeclared)
+		pkg.PackageLicenseInfoFromFiles = r.licenseExpressions(pkg.PackageLicenseInfoFromFiles)
+		pkg.Annotations = r.annotations(pkg.Annotations)
+	}
+	for _, f := range newFiles {
+		f.LicenseConcluded = r.licenseExpression(f.LicenseConcluded)
+		f.LicenseInfoInFiles = r.licenseExpressions(f.LicenseInfoInFiles)
+		f.Annotations = r.annotations(f.Annotations)
+	}
+	for _, s := range newSnippets {
+		s.SnippetFromFileSPDXIdentifier = r.id(s.SnippetFromFileSPDXIdentifier)
+		s.SnippetLicenseConcluded = r.licenseExpression(s.SnippetLicenseConcluded)
+		s.LicenseInfoInSnippet = r.licenseExpressions(s.LicenseInfoInSnippet)
+	}
+
+	m.describeDocument(doc, "", r)
+	for _, rln := range doc.Relationships {
+		if rln == nil {
+			continue
+		}
+		copied := *rln
+		copied.RefA = r.docElementID(rln.RefA)
+		copied.RefB = r.docElementID(rln.RefB)
+		m.addRelationship(&copied)
+	}
+	for _, a := range doc.Annotations {
+		if a == nil {
+			continue
+		}
+		copied := *a
+		copied.AnnotationSPDXIdentifier = r.docElementID(a.AnnotationSPDXIdentifier)
+		if !m.seen[copied] {
+			m.seen[copied] = true
+			m.out.Annotations = append(m.out.Annotations, &copied)
+		}
+	}
+	return nil
+}
+
+// packageKeys returns the keys by which pkg is merged with packages of
+// other documents: its purls, its name and version, and its checksums.
+func packageKeys(pkg *spdx.Package) []string {
+	keys := []string{}
+	for _, ref := range pkg.PackageExternalReferences {
+		if ref != nil && ref.RefType == common.TypePackageManagerPURL {
+			keys = append(keys, "purl:"+ref.Locator)
+		}
+	}
+	if pkg.PackageVersion != "" {
+		keys = append(keys, "version:"+pkg.PackageName+"@"+pkg.PackageVersion)
+	}
+	for _, c := range pkg.PackageChecksums {
+		keys = append(keys, "checksum:"+string(c.Algorithm)+":"+strings.ToLower(c.Value))
+	}
+	return keys
+}
+
+func (m *merger) findPackage(keys []string) *spdx.Package {
+	if m.opts.KeepDuplicatePackages {
+		return nil
+	}
+	for _, key := range keys {
+		if pkg := m.packagesByKey[key]; pkg != nil {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// mergePackageFiles maps the files of pkg to the files of kept with the
+// same names, and adds those kept lacks.
+func (m *merger) mergePackageFiles(kept *spdx.Package, pkg *spdx.Package, r *renames, copyFile func(*spdx.File) *spdx.File) {
+	byName := map[string]*spdx.File{}
+	for _, f := range kept.Files {
+		byName[f.FileName] = f
+	}
+	for _, f := range pkg.Files {
+		if f == nil {
+			continue
+		}
+		if existing, ok := byName[f.FileName]; ok {
+			r.ids[f.FileSPDXIdentifier] = existing.FileSPDXIdentifier
+			for id := range f.Snippets {
+				// snippets of a file already copied are dropped, and
+				// referring to them refers to the file instead
+				r.ids[id] = existing.FileSPDXIdentifier
+			}
+			continue
+		}
+		kept.Files = append(kept.Files, copyFile(f))
+	}
+}
+
+func sortedSnippetIDs(snippets map[common.ElementID]*spdx.Snippet) []common.ElementID {
+	ids := []common.ElementID{}
+	for id, s := range snippets {
+		if s != nil {
+			ids = append(ids, id)
+		}
+	}
+	return SortElementIDs(ids)
+}
+
+func (r *renames) id(id common.ElementID) common.ElementID {
+	if renamed, ok := r.ids[id]; ok {
+		return renamed
+	}
+	return id
+}
+
+func (r *renames) docElementID(id common.DocElementID) common.DocElementID {
+	switch {
+	case id.SpecialID != "":
+	case id.DocumentRefID != "":
+		if renamed, ok := r.docRefs[id.DocumentRefID]; ok {
+			id.DocumentRefID = renamed
+		}
+	default:
+		id.ElementRefID = r.id(id.ElementRefID)
+	}
+	return id
+}
+
+func (r *renames) annotations(annotations []spdx.Annotation) []spdx.Annotation {
+	if annotations == nil {
+		return nil
+	}
+	copied := make([]spdx.Annotation, len(annotations))
+	for i, a := range annotations {
+		a.AnnotationSPDXIdentifier = r.docElementID(a.AnnotationSPDXIdentifier)
+		copied[i] = a
+	}
+	return copied
+}
+
+// licenseRefRegexp matches a LicenseRef, with the DocumentRef of the
+// document defining it, if given.
+var licenseRefRegexp = regexp.MustCompile(`(DocumentRef-[A-Za-z0-9.\-]+:)?LicenseRef-[A-Za-z0-9.\-]+`)
+
+// licenseExpression renames the LicenseRefs of the document's
+// OtherLicenses, and the DocumentRefs of others, in expr.
+func (r *renames) licenseExpression(expr string) string {
+	return licenseRefRegexp.ReplaceAllStringFunc(expr, func(ref string) string {
+		docRef, licenseRef, ok := strings.Cut(ref, ":")
+		if !ok {
+			if renamed, ok := r.licenses[ref]; ok {
+				return renamed
+			}
+			return ref
+		}
+		if renamed, ok := r.docRefs[common.DocumentID(strings.TrimPrefix(docRef, "DocumentRef-"))]; ok {
+			return "DocumentRef-" + string(renamed) + ":" + licenseRef
+		}
+		return ref
+	})
+}
+
+func (r *renames) licenseExpressions(exprs []string) []string {
+	if exprs == nil {
+		return nil
+	}
+	renamed := make([]string, len(exprs))
+	for i, expr := range exprs {
+		renamed[i] = r.licenseExpression(expr)
+	}
+	return renamed
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+func makeMergeTestDocument(name string, pkgs ...*spdx.Package) *spdx.Document {
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      name,
+		DocumentNamespace: "https://example.com/" + name,
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{{Creator: name + "-tool", CreatorType: "Tool"}},
+		},
+		Packages: pkgs,
+	}
+	for _, pkg := range pkgs {
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+	return doc
+}
+
+func relationshipStrings(rlns []*spdx.Relationship) string {
+	s := []string{}
+	for _, rln := range rlns {
+		s = append(s, fmt.Sprintf("%s %s %s", common.RenderDocElementID(rln.RefA), rln.Relationship, common.RenderDocElementID(rln.RefB)))
+	}
+	return strings.Join(s, ", ")
+}
+
+func TestMergeRenamesConflictingIDs(t *testing.T) {
+	doc1 := makeMergeTestDocument("app", &spdx.Package{
+		PackageName:           "app",
+		PackageSPDXIdentifier: "Package",
+		Files:                 []*spdx.File{{FileName: "./main.go", FileSPDXIdentifier: "File0"}},
+	})
+	doc2 := makeMergeTestDocument("lib", &spdx.Package{
+		PackageName:            "lib",
+		PackageSPDXIdentifier:  "Package",
+		PackageLicenseDeclared: "LicenseRef-custom",
+		Files: []*spdx.File{{
+			FileName:           "./lib.go",
+			FileSPDXIdentifier: "File0",
+			LicenseConcluded:   "MIT OR LicenseRef-custom",
+			Annotations: []spdx.Annotation{{
+				AnnotationSPDXIdentifier: common.MakeDocElementID("", "File0"),
+				AnnotationComment:        "reviewed",
+			}},
+		}},
+	})
+	doc2.Relationships = append(doc2.Relationships, &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", "File0"),
+		RefB:         common.MakeDocElementID("", "Package"),
+		Relationship: common.TypeRelationshipContainedBy,
+	})
+	doc1.OtherLicenses = []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-custom", ExtractedText: "one"}}
+	doc2.OtherLicenses = []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-custom", ExtractedText: "two"}}
+
+	merged, err := Merge([]*spdx.Document{doc1, doc2}, &MergeOptions{
+		DocumentName:    "product",
+		NamespacePrefix: "https://example.com/merged/",
+		Created:         "2024-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if merged.DocumentName != "product" || !strings.HasPrefix(merged.DocumentNamespace, "https://example.com/merged/product-") {
+		t.Errorf("unexpected name %v and namespace %v", merged.DocumentName, merged.DocumentNamespace)
+	}
+	if len(merged.Packages) != 2 || merged.Packages[0].PackageSPDXIdentifier != "Package" || merged.Packages[1].PackageSPDXIdentifier != "Package-2" {
+		t.Fatalf("expected Package and Package-2, got %+v", merged.Packages)
+	}
+	lib := merged.Packages[1]
+	if lib.Files[0].FileSPDXIdentifier != "File0-2" || lib.Files[0].Annotations[0].AnnotationSPDXIdentifier.ElementRefID != "File0-2" {
+		t.Errorf("expected File0-2, got %+v", lib.Files[0])
+	}
+	if lib.PackageLicenseDeclared != "LicenseRef-custom-2" || lib.Files[0].LicenseConcluded != "MIT OR LicenseRef-custom-2" {
+		t.Errorf("expected renamed license, got %v and %v", lib.PackageLicenseDeclared, lib.Files[0].LicenseConcluded)
+	}
+	if len(merged.OtherLicenses) != 2 || merged.OtherLicenses[1].LicenseIdentifier != "LicenseRef-custom-2" {
+		t.Errorf("expected two licenses, got %+v", merged.OtherLicenses)
+	}
+
+	want := "SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package, SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-2, SPDXRef-File0-2 CONTAINED_BY SPDXRef-Package-2"
+	if got := relationshipStrings(merged.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(merged.CreationInfo.Creators) != 2 || merged.CreationInfo.Created != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected creation info %+v", merged.CreationInfo)
+	}
+	if doc2.Packages[0].PackageSPDXIdentifier != "Package" || doc2.Packages[0].Files[0].FileSPDXIdentifier != "File0" {
+		t.Errorf("expected input document not to change")
+	}
+}
+
+func TestMergeDeduplicatesPackagesAndLicenses(t *testing.T) {
+	purl := &spdx.PackageExternalReference{Category: common.CategoryPackageManager, RefType: common.TypePackageManagerPURL, Locator: "pkg:golang/example.com/lib@v1.0.0"}
+	doc1 := makeMergeTestDocument("a",
+		&spdx.Package{PackageName: "lib", PackageSPDXIdentifier: "lib", PackageExternalReferences: []*spdx.PackageExternalReference{purl},
+			Files: []*spdx.File{{FileName: "./lib.go", FileSPDXIdentifier: "lib.go"}}},
+		&spdx.Package{PackageName: "zlib", PackageVersion: "1.3", PackageSPDXIdentifier: "zlib"},
+		&spdx.Package{PackageName: "tar", PackageSPDXIdentifier: "tar", PackageChecksums: []common.Checksum{{Algorithm: common.SHA256, Value: "ABC"}}},
+	)
+	doc2 := makeMergeTestDocument("b",
+		&spdx.Package{PackageName: "golib", PackageSPDXIdentifier: "golib", PackageExternalReferences: []*spdx.PackageExternalReference{purl},
+			Files: []*spdx.File{{FileName: "./lib.go", FileSPDXIdentifier: "f1"}, {FileName: "./extra.go", FileSPDXIdentifier: "f2"}}},
+		&spdx.Package{PackageName: "zlib", PackageVersion: "1.3", PackageSPDXIdentifier: "z"},
+		&spdx.Package{PackageName: "tarball", PackageSPDXIdentifier: "t", PackageChecksums: []common.Checksum{{Algorithm: common.SHA256, Value: "abc"}}},
+		&spdx.Package{PackageName: "zlib", PackageVersion: "1.2", PackageSPDXIdentifier: "zlib"},
+	)
+	doc2.Relationships = append(doc2.Relationships, &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", "golib"),
+		RefB:         common.MakeDocElementID("", "z"),
+		Relationship: common.TypeRelationshipDependsOn,
+	})
+	doc1.OtherLicenses = []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-a", ExtractedText: "same"}}
+	doc2.OtherLicenses = []*spdx.OtherLicense{{LicenseIdentifier: "LicenseRef-b", ExtractedText: "same"}}
+	doc2.Packages[3].PackageLicenseConcluded = "LicenseRef-b"
+
+	merged, err := Merge([]*spdx.Document{doc1, doc2}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ids := []string{}
+	for _, pkg := range merged.Packages {
+		ids = append(ids, string(pkg.PackageSPDXIdentifier))
+	}
+	if want := "[lib zlib tar zlib-2]"; fmt.Sprint(ids) != want {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+	if files := merged.Packages[0].Files; len(files) != 2 || files[1].FileSPDXIdentifier != "f2" {
+		t.Errorf("expected extra file to be added, got %+v", files)
+	}
+	if want := "SPDXRef-lib DEPENDS_ON SPDXRef-zlib"; !strings.Contains(relationshipStrings(merged.Relationships), want) {
+		t.Errorf("expected %v, got %v", want, relationshipStrings(merged.Relationships))
+	}
+	if len(merged.OtherLicenses) != 1 || merged.Packages[3].PackageLicenseConcluded != "LicenseRef-a" {
+		t.Errorf("expected licenses to be merged, got %+v and %v", merged.OtherLicenses, merged.Packages[3].PackageLicenseConcluded)
+	}
+	if merged.DocumentName != "a+b" {
+		t.Errorf("expected %v, got %v", "a+b", merged.DocumentName)
+	}
+
+	merged, err = Merge([]*spdx.Document{doc1, doc2}, &MergeOptions{KeepDuplicatePackages: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(merged.Packages) != 7 {
+		t.Errorf("expected %d, got %d", 7, len(merged.Packages))
+	}
+}
+
+func TestMergeCanReferenceInputs(t *testing.T) {
+	doc1 := makeMergeTestDocument("app", &spdx.Package{PackageName: "app", PackageSPDXIdentifier: "Package"})
+	doc2 := makeMergeTestDocument("lib", &spdx.Package{PackageName: "lib", PackageSPDXIdentifier: "Package"})
+	merged, err := Merge([]*spdx.Document{doc1, doc2}, &MergeOptions{
+		ReferenceInputs: true,
+		InputChecksum: func(doc *spdx.Document) (common.Checksum, error) {
+			return common.Checksum{Algorithm: common.SHA1, Value: doc.DocumentName}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(merged.Packages) != 0 || len(merged.ExternalDocumentReferences) != 2 {
+		t.Fatalf("expected only external references, got %+v", merged)
+	}
+	ref := merged.ExternalDocumentReferences[1]
+	if ref.DocumentRefID != "lib" || ref.URI != "https://example.com/lib" || ref.Checksum.Value != "lib" {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+	want := "SPDXRef-DOCUMENT DESCRIBES DocumentRef-app:SPDXRef-Package, SPDXRef-DOCUMENT DESCRIBES DocumentRef-lib:SPDXRef-Package"
+	if got := relationshipStrings(merged.Relationships); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	doc2.DocumentNamespace = ""
+	if _, err := Merge([]*spdx.Document{doc1, doc2}, &MergeOptions{ReferenceInputs: true}); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
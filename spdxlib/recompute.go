@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+// RecomputeOptions configures RecomputePackages.
+type RecomputeOptions struct {
+	// ConcludeLicenses sets the PackageLicenseConcluded of each package
+	// whose concluded license is empty or NOASSERTION to the proposed one.
+	ConcludeLicenses bool
+
+	// DryRun computes the results without changing the document, to find
+	// the packages whose fields are stale.
+	DryRun bool
+}
+
+// RecomputeResult is the result of recomputing the fields of a Package.
+type RecomputeResult struct {
+	Package *spdx.Package
+
+	// Changed names the fields that were, or with DryRun would be,
+	// changed: PackageLicenseInfoFromFiles, PackageVerificationCode or
+	// PackageLicenseConcluded.
+	Changed []string
+
+	// ProposedConcluded is a license expression joining with AND the
+	// distinct concluded licenses of the package's files, or NOASSERTION
+	// if none of them is known.
+	ProposedConcluded string
+
+	// Uncovered are the license IDs found in the package's files that its
+	// declared license does not mention. It is empty if the declared
+	// license is empty, NONE, NOASSERTION or not a valid expression.
+	Uncovered []string
+}
+
+// RecomputePackages recomputes the fields of doc's packages that derive
+// from their files, for each package whose files have been analyzed:
+//   - PackageLicenseInfoFromFiles is set to the sorted, distinct license
+//     IDs of the LicenseInfoInFiles of its files and the
+//     LicenseInfoInSnippet of their snippets, or to NONE or NOASSERTION if
+//     there are none.
+//   - PackageVerificationCode is recomputed with utils.GetVerificationCode,
+//     leaving out the files it already excludes.
+//   - PackageLicenseConcluded is set to the proposed license if
+//     opts.ConcludeLicenses is set and it is not already concluded.
+//
+// It returns a result for each of those packages, in document order.
+func RecomputePackages(doc *spdx.Document, opts *RecomputeOptions) ([]RecomputeResult, error) {
+	if opts == nil {
+		opts = &RecomputeOptions{}
+	}
+	docSnippets := map[common.ElementID][]spdx.Snippet{}
+	for _, s := range doc.Snippets {
+		docSnippets[s.SnippetFromFileSPDXIdentifier] = append(docSnippets[s.SnippetFromFileSPDXIdentifier], s)
+	}
+
+	results := []RecomputeResult{}
+	for _, pkg := range doc.Packages {
+		if pkg == nil || !pkg.FilesAnalyzed {
+			continue
+		}
+		result := RecomputeResult{Package: pkg}
+
+		info := &licenseInfo{ids: map[string]bool{}}
+		concluded := []string{}
+		for _, f := range pkg.Files {
+			if f == nil {
+				continue
+			}
+			info.add(f.LicenseInfoInFiles...)
+			for _, s := range f.Snippets {
+				if s != nil {
+					info.add(s.LicenseInfoInSnippet...)
+				}
+			}
+			for _, s := range docSnippets[f.FileSPDXIdentifier] {
+				info.add(s.LicenseInfoInSnippet...)
+			}
+			if isKnownLicense(f.LicenseConcluded) {
+				concluded = appendLicenseExpression(concluded, f.LicenseConcluded)
+			}
+		}
+		fromFiles := info.values()
+		if !reflect.DeepEqual(fromFiles, pkg.PackageLicenseInfoFromFiles) {
+			result.Changed = append(result.Changed, "PackageLicenseInfoFromFiles")
+			if !opts.DryRun {
+				pkg.PackageLicenseInfoFromFiles = fromFiles
+			}
+		}
+
+		code, err := verificationCode(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.PackageVerificationCode == nil || pkg.PackageVerificationCode.Value != code.Value {
+			result.Changed = append(result.Changed, "PackageVerificationCode")
+			if !opts.DryRun {
+				pkg.PackageVerificationCode = &code
+			}
+		}
+
+		result.ProposedConcluded = "NOASSERTION"
+		if len(concluded) > 0 {
+			result.ProposedConcluded = joinLicenseExpressions(concluded)
+		}
+		if opts.ConcludeLicenses && !isKnownLicense(pkg.PackageLicenseConcluded) && pkg.PackageLicenseConcluded != result.ProposedConcluded {
+			result.Changed = append(result.Changed, "PackageLicenseConcluded")
+			if !opts.DryRun {
+				pkg.PackageLicenseConcluded = result.ProposedConcluded
+			}
+		}
+
+		if isKnownLicense(pkg.PackageLicenseDeclared) {
+			if declared, err := licenseexpr.Parse(pkg.PackageLicenseDeclared); err == nil {
+				mentioned := map[string]bool{}
+				for _, id := range declared.Licenses() {
+					mentioned[id] = true
+				}
+				for _, id := range fromFiles {
+					if isKnownLicense(id) && !mentioned[id] {
+						result.Uncovered = append(result.Uncovered, id)
+					}
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// licenseInfo collects the license IDs of license info fields.
+type licenseInfo struct {
+	ids               map[string]bool
+	none, noAssertion bool
+}
+
+func (l *licenseInfo) add(values ...string) {
+	for _, v := range values {
+		switch v {
+		case "", "NOASSERTION":
+			l.noAssertion = true
+			continue
+		case "NONE":
+			l.none = true
+			continue
+		}
+		// license info should only list IDs, but allow for expressions
+		if e, err := licenseexpr.Parse(v); err == nil {
+			for _, id := range e.Licenses() {
+				l.ids[id] = true
+			}
+		} else {
+			l.ids[v] = true
+		}
+	}
+}
+
+// values returns the sorted license IDs, or NONE if the only values were
+// NONE, or else NOASSERTION if there were none.
+func (l *licenseInfo) values() []string {
+	if len(l.ids) == 0 {
+		if l.none && !l.noAssertion {
+			return []string{"NONE"}
+		}
+		return []string{"NOASSERTION"}
+	}
+	values := []string{}
+	for id := range l.ids {
+		values = append(values, id)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// verificationCode computes the verification code of pkg, excluding the
+// same files as its current one.
+func verificationCode(pkg *spdx.Package) (common.PackageVerificationCode, error) {
+	var excluded []string
+	if pkg.PackageVerificationCode != nil {
+		excluded = pkg.PackageVerificationCode.ExcludedFiles
+	}
+	files := []*spdx.File{}
+	for _, f := range pkg.Files {
+		if f != nil && !containsString(excluded, f.FileName) {
+			files = append(files, f)
+		}
+	}
+	code, err := utils.GetVerificationCode(files, "")
+	if err != nil {
+		return code, err
+	}
+	code.ExcludedFiles = excluded
+	return code, nil
+}
+
+// appendLicenseExpression appends expr to exprs, normalized by
+// licenseexpr if it is valid, unless it is already there.
+func appendLicenseExpression(exprs []string, expr string) []string {
+	if e, err := licenseexpr.Parse(expr); err == nil {
+		expr = e.String()
+	}
+	if containsString(exprs, expr) {
+		return exprs
+	}
+	return append(exprs, expr)
+}
+
+// joinLicenseExpressions joins exprs with AND, parenthesizing those that
+// are not single licenses or AND expressions.
+func joinLicenseExpressions(exprs []string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	parts := []string{}
+	for _, expr := range exprs {
+		if e, err := licenseexpr.Parse(expr); err != nil || e.Op == licenseexpr.Or {
+			expr = "(" + expr + ")"
+		}
+		parts = append(parts, expr)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func isKnownLicense(expr string) bool {
+	return expr != "" && expr != "NONE" && expr != "NOASSERTION"
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
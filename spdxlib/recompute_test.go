@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/utils"
+)
+
+func makeRecomputeTestDocument() *spdx.Document {
+	sha1 := func(value string) []common.Checksum {
+		return []common.Checksum{{Algorithm: common.SHA1, Value: value}}
+	}
+	return &spdx.Document{
+		Packages: []*spdx.Package{
+			{
+				PackageSPDXIdentifier:       "Package-a",
+				FilesAnalyzed:               true,
+				PackageLicenseConcluded:     "NOASSERTION",
+				PackageLicenseDeclared:      "MIT OR Apache-2.0",
+				PackageLicenseInfoFromFiles: []string{"MIT"},
+				PackageVerificationCode:     &common.PackageVerificationCode{Value: "stale", ExcludedFiles: []string{"./a.spdx"}},
+				Files: []*spdx.File{
+					{
+						FileSPDXIdentifier: "File0",
+						FileName:           "./a.c",
+						Checksums:          sha1("aaaa"),
+						LicenseConcluded:   "MIT",
+						LicenseInfoInFiles: []string{"MIT"},
+						Snippets: map[common.ElementID]*spdx.Snippet{
+							"Snippet0": {SnippetSPDXIdentifier: "Snippet0", LicenseInfoInSnippet: []string{"BSD-3-Clause"}},
+						},
+					},
+					{
+						FileSPDXIdentifier: "File1",
+						FileName:           "./b.c",
+						Checksums:          sha1("bbbb"),
+						LicenseConcluded:   "MIT OR Apache-2.0",
+						LicenseInfoInFiles: []string{"Apache-2.0", "MIT"},
+					},
+					{
+						FileSPDXIdentifier: "File2",
+						FileName:           "./c.c",
+						Checksums:          sha1("cccc"),
+						LicenseConcluded:   "MIT",
+						LicenseInfoInFiles: []string{"NOASSERTION"},
+					},
+					{FileSPDXIdentifier: "File3", FileName: "./a.spdx", Checksums: sha1("dddd")},
+				},
+			},
+			{
+				PackageSPDXIdentifier:   "Package-b",
+				FilesAnalyzed:           true,
+				PackageLicenseConcluded: "NOASSERTION",
+				PackageLicenseDeclared:  "NOASSERTION",
+				Files: []*spdx.File{
+					{FileSPDXIdentifier: "File4", FileName: "./d.c", Checksums: sha1("eeee"), LicenseInfoInFiles: []string{"NONE"}},
+				},
+			},
+			{PackageSPDXIdentifier: "Package-c", FilesAnalyzed: false},
+		},
+		Snippets: []spdx.Snippet{
+			{SnippetSPDXIdentifier: "Snippet1", SnippetFromFileSPDXIdentifier: "File2", LicenseInfoInSnippet: []string{"ISC"}},
+		},
+	}
+}
+
+// ===== Recompute tests =====
+func TestRecomputePackagesUpdatesFieldsFromFiles(t *testing.T) {
+	doc := makeRecomputeTestDocument()
+	results, err := RecomputePackages(doc, &RecomputeOptions{ConcludeLicenses: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected %d, got %d", 2, len(results))
+	}
+
+	a := doc.Packages[0]
+	if want := "[Apache-2.0 BSD-3-Clause ISC MIT]"; fmt.Sprint(a.PackageLicenseInfoFromFiles) != want {
+		t.Errorf("expected %v, got %v", want, a.PackageLicenseInfoFromFiles)
+	}
+	want, _ := utils.GetVerificationCode(a.Files[:3], "")
+	if a.PackageVerificationCode.Value != want.Value || fmt.Sprint(a.PackageVerificationCode.ExcludedFiles) != "[./a.spdx]" {
+		t.Errorf("expected %v, got %v", want.Value, a.PackageVerificationCode)
+	}
+	if a.PackageLicenseConcluded != "MIT AND (MIT OR Apache-2.0)" || results[0].ProposedConcluded != a.PackageLicenseConcluded {
+		t.Errorf("unexpected concluded license %v", a.PackageLicenseConcluded)
+	}
+	if want := "[PackageLicenseInfoFromFiles PackageVerificationCode PackageLicenseConcluded]"; fmt.Sprint(results[0].Changed) != want {
+		t.Errorf("expected %v, got %v", want, results[0].Changed)
+	}
+	if want := "[BSD-3-Clause ISC]"; fmt.Sprint(results[0].Uncovered) != want {
+		t.Errorf("expected %v, got %v", want, results[0].Uncovered)
+	}
+
+	b := doc.Packages[1]
+	if fmt.Sprint(b.PackageLicenseInfoFromFiles) != "[NONE]" || b.PackageLicenseConcluded != "NOASSERTION" || results[1].Uncovered != nil {
+		t.Errorf("unexpected package %+v, %+v", b, results[1])
+	}
+
+	// a second run finds nothing stale
+	results, _ = RecomputePackages(doc, nil)
+	for _, r := range results {
+		if len(r.Changed) != 0 {
+			t.Errorf("%s: expected no changes, got %v", r.Package.PackageSPDXIdentifier, r.Changed)
+		}
+	}
+}
+
+func TestRecomputePackagesDryRunLeavesDocument(t *testing.T) {
+	doc := makeRecomputeTestDocument()
+	results, err := RecomputePackages(doc, &RecomputeOptions{ConcludeLicenses: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(results[0].Changed) != 3 {
+		t.Errorf("expected %d, got %v", 3, results[0].Changed)
+	}
+	a := doc.Packages[0]
+	if fmt.Sprint(a.PackageLicenseInfoFromFiles) != "[MIT]" || a.PackageVerificationCode.Value != "stale" || a.PackageLicenseConcluded != "NOASSERTION" {
+		t.Errorf("expected package to be unchanged, got %+v", a)
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"github.com/spdx/tools-golang/licenseexpr"
+	"github.com/spdx/tools-golang/licenselist"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// LicenseRewrite is a license field rewritten by UpdateLicenses.
+type LicenseRewrite struct {
+	// ElementID is the SPDXID of the package, file or snippet rewritten.
+	ElementID common.ElementID
+	// Field is the name of the license field, such as "LicenseConcluded".
+	Field string
+	Old   string
+	New   string
+	// Changes describe each license changed, as by licenselist.Update.
+	Changes []string
+}
+
+// InvalidLicense is a license field that is not a valid license
+// expression.
+type InvalidLicense struct {
+	ElementID common.ElementID
+	Field     string
+	Value     string
+	Err       error
+}
+
+// LicenseUpdate is the result of UpdateLicenses.
+type LicenseUpdate struct {
+	Rewrites []LicenseRewrite
+	// Invalid are the license fields left unchanged because they could
+	// not be parsed.
+	Invalid []InvalidLicense
+}
+
+// UpdateLicenses rewrites the license fields of doc's packages, files and
+// snippets as by licenselist.Update: deprecated license IDs, such as
+// GPL-2.0 or GPL-2.0-with-classpath-exception, are replaced by their
+// current equivalents, and license and exception IDs are given the case
+// of the SPDX License List. A field is only rewritten if one of its
+// licenses changes, and is then also formatted as by licenseexpr. NONE,
+// NOASSERTION and empty fields are left as they are.
+func UpdateLicenses(doc *spdx.Document) *LicenseUpdate {
+	update := &LicenseUpdate{Rewrites: []LicenseRewrite{}, Invalid: []InvalidLicense{}}
+	for _, f := range licenseFields(doc) {
+		value := *f.value
+		if !isKnownLicense(value) {
+			continue
+		}
+		e, err := licenseexpr.Parse(value)
+		if err != nil {
+			update.Invalid = append(update.Invalid, InvalidLicense{ElementID: f.id, Field: f.field, Value: value, Err: err})
+			continue
+		}
+		changes := licenselist.Update(e)
+		if len(changes) == 0 {
+			continue
+		}
+		*f.value = e.String()
+		update.Rewrites = append(update.Rewrites, LicenseRewrite{
+			ElementID: f.id,
+			Field:     f.field,
+			Old:       value,
+			New:       *f.value,
+			Changes:   changes,
+		})
+	}
+	return update
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package spdxlib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx"
+)
+
+// ===== License update tests =====
+func TestUpdateLicensesRewritesDeprecatedIDs(t *testing.T) {
+	doc := &spdx.Document{
+		Packages: []*spdx.Package{
+			{
+				PackageSPDXIdentifier:       "Package-a",
+				PackageLicenseConcluded:     "GPL-2.0 and (mit OR lgpl-2.1+)",
+				PackageLicenseDeclared:      "GPL-2.0-with-classpath-exception",
+				PackageLicenseInfoFromFiles: []string{"GPL-2.0", "MIT", "NOASSERTION"},
+				Files: []*spdx.File{
+					{FileSPDXIdentifier: "File0", LicenseConcluded: "Apache-2.0 OR MIT", LicenseInfoInFiles: []string{"LicenseRef-x"}},
+					{FileSPDXIdentifier: "File1", LicenseConcluded: "MIT AND (", LicenseInfoInFiles: []string{"gpl-2.0-only with linux-syscall-note"}},
+				},
+			},
+		},
+		Snippets: []spdx.Snippet{
+			{SnippetSPDXIdentifier: "Snippet0", SnippetLicenseConcluded: "wxWindows"},
+		},
+	}
+	update := UpdateLicenses(doc)
+
+	rewrites := []string{}
+	for _, r := range update.Rewrites {
+		rewrites = append(rewrites, fmt.Sprintf("%s.%s: %s => %s %v", r.ElementID, r.Field, r.Old, r.New, r.Changes))
+	}
+	want := []string{
+		"Package-a.PackageLicenseConcluded: GPL-2.0 and (mit OR lgpl-2.1+) => GPL-2.0-only AND (MIT OR LGPL-2.1-or-later) [GPL-2.0 -> GPL-2.0-only mit -> MIT lgpl-2.1+ -> LGPL-2.1-or-later]",
+		"Package-a.PackageLicenseDeclared: GPL-2.0-with-classpath-exception => GPL-2.0-only WITH Classpath-exception-2.0 [GPL-2.0-with-classpath-exception -> GPL-2.0-only WITH Classpath-exception-2.0]",
+		"Package-a.PackageLicenseInfoFromFiles: GPL-2.0 => GPL-2.0-only [GPL-2.0 -> GPL-2.0-only]",
+		"File1.LicenseInfoInFiles: gpl-2.0-only with linux-syscall-note => GPL-2.0-only WITH Linux-syscall-note [gpl-2.0-only WITH linux-syscall-note -> GPL-2.0-only WITH Linux-syscall-note]",
+		"Snippet0.SnippetLicenseConcluded: wxWindows => GPL-2.0-or-later WITH WxWindows-exception-3.1 [wxWindows -> GPL-2.0-or-later WITH WxWindows-exception-3.1]",
+	}
+	if fmt.Sprint(rewrites) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, rewrites)
+	}
+
+	pkg := doc.Packages[0]
+	if fmt.Sprint(pkg.PackageLicenseInfoFromFiles) != "[GPL-2.0-only MIT NOASSERTION]" {
+		t.Errorf("unexpected license info %v", pkg.PackageLicenseInfoFromFiles)
+	}
+	if pkg.Files[0].LicenseConcluded != "Apache-2.0 OR MIT" {
+		t.Errorf("expected %v, got %v", "Apache-2.0 OR MIT", pkg.Files[0].LicenseConcluded)
+	}
+	if len(update.Invalid) != 1 || update.Invalid[0].Value != "MIT AND (" || update.Invalid[0].ElementID != "File1" {
+		t.Errorf("unexpected invalid licenses %+v", update.Invalid)
+	}
+}
@@ -17,10 +17,30 @@ import (
 	v2_3_writer "github.com/spdx/tools-golang/spdx/v2/v2_3/tagvalue/writer"
 )
 
+// WriteOption configures Write; see convert.WriteConfig.
+type WriteOption = convert.WriteOption
+
+// TargetVersion converts the document to the given SPDX version, such as
+// "SPDX-2.2", before writing it, as by convert.ToVersion.
+func TargetVersion(version string) WriteOption {
+	return convert.TargetVersion(version)
+}
+
+// DroppedFields calls report with the fields of the document dropped by
+// TargetVersion because the target version cannot represent them, if
+// there are any.
+func DroppedFields(report func(fields []string)) WriteOption {
+	return convert.DroppedFields(report)
+}
+
 // Write takes an io.Writer and an SPDX Document,
 // and writes it to the writer in tag-value format. It returns error
 // if any error is encountered.
-func Write(doc common.AnyDocument, w io.Writer) error {
+func Write(doc common.AnyDocument, w io.Writer, opts ...WriteOption) error {
+	doc, err := convert.ApplyWriteOptions(doc, opts...)
+	if err != nil {
+		return err
+	}
 	doc = convert.FromPtr(doc)
 	switch doc := doc.(type) {
 	case v2_1.Document:
@@ -0,0 +1,39 @@
+package tagvalue_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	spdx "github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"github.com/spdx/tools-golang/tagvalue"
+)
+
+func Test_WriteTargetVersion(t *testing.T) {
+	doc := spdx.Document{
+		SPDXVersion:  spdx.Version,
+		DocumentName: "test_doc",
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{{Creator: "test", CreatorType: "Tool"}},
+		},
+		Packages: []*spdx.Package{
+			{PackageName: "p", PackageSPDXIdentifier: "p", PrimaryPackagePurpose: "LIBRARY"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	var dropped []string
+	err := tagvalue.Write(doc, buf, tagvalue.TargetVersion("SPDX-2.2"), tagvalue.DroppedFields(func(fields []string) {
+		dropped = fields
+	}))
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "SPDXVersion: SPDX-2.2\n")
+	assert.Contains(t, buf.String(), "PackageName: p\n")
+	assert.NotContains(t, buf.String(), "LIBRARY")
+	assert.Equal(t, []string{"packages[0].primaryPackagePurpose"}, dropped)
+
+	err = tagvalue.Write(doc, buf, tagvalue.TargetVersion("SPDX-9.9"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,3 @@
+int greet(void);
+int missing(void);
+int main(void) { return greet() + missing(); }
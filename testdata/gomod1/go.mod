@@ -0,0 +1,20 @@
+module example.com/gomod1
+
+go 1.21
+
+require (
+	github.com/google/go-cmp v0.7.0
+	github.com/stretchr/testify v1.11.1
+	example.com/replaced v1.0.0
+	example.com/local v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace example.com/replaced v1.0.0 => example.com/fork v1.0.1
+
+replace example.com/local => ../local
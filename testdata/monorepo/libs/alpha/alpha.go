@@ -0,0 +1,2 @@
+// SPDX-License-Identifier: MIT
+package alpha
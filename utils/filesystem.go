@@ -19,49 +19,167 @@ import (
 // path patterns to ignore), and returns a slice of relative paths to all files
 // in that directory and its subdirectories (excluding those that are ignored).
 // These paths are always normalized to use URI-like forward-slashes but begin with /
+// Symbolic links are skipped.
 func GetAllFilePaths(dirRoot string, pathsIgnored []string) ([]string, error) {
-	// paths is a _pointer_ to a slice -- not just a slice.
-	// this is so that it can be appropriately modified by append
-	// in the sub-function.
-	paths := &[]string{}
+	paths, _, err := GetAllFilePathsWithSymlinks(dirRoot, pathsIgnored, SymlinksSkip)
+	return paths, err
+}
+
+// SymlinkPolicy selects how GetAllFilePathsWithSymlinks handles symbolic
+// links.
+type SymlinkPolicy int
+
+const (
+	// SymlinksSkip omits symbolic links.
+	SymlinksSkip SymlinkPolicy = iota
+
+	// SymlinksFollow treats a symbolic link as the file or directory it
+	// resolves to, so that a linked file, or the files within a linked
+	// directory, are returned under the link's path. Links resolving
+	// outside of the directory, or to nothing, are omitted, as are links
+	// to a directory containing the link, which would form a cycle.
+	SymlinksFollow
+
+	// SymlinksRecord returns symbolic links separately from the files,
+	// without following them.
+	SymlinksRecord
+)
+
+// Symlink is a symbolic link found by GetAllFilePathsWithSymlinks.
+type Symlink struct {
+	// Path is the link's path, in the form returned by GetAllFilePaths.
+	Path string
+	// Target is the link's target, as stored in the link.
+	Target string
+	// Resolved is the path, in the same form, of the file or directory
+	// the link resolves to, or "" if it resolves outside of the directory
+	// or to nothing.
+	Resolved string
+	// IsDir records whether the link resolves to a directory.
+	IsDir bool
+}
+
+// fileWalker collects the files and symbolic links within root, the real
+// path of a directory.
+type fileWalker struct {
+	root         string
+	pathsIgnored []string
+	policy       SymlinkPolicy
+	paths        []string
+	links        []Symlink
+}
+
+// GetAllFilePathsWithSymlinks is like GetAllFilePaths, but handles symbolic
+// links according to policy. With SymlinksRecord, the links that are not
+// ignored are returned separately; otherwise, the returned slice of links is
+// empty. Symbolic links are never followed outside of dirRoot.
+func GetAllFilePathsWithSymlinks(dirRoot string, pathsIgnored []string, policy SymlinkPolicy) ([]string, []Symlink, error) {
 	absRoot, err := filepath.Abs(dirRoot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	root, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, nil, err
 	}
-	dirRoot = absRoot
-	prefix := strings.TrimSuffix(filepath.ToSlash(dirRoot), "/")
-
-	err = filepath.Walk(dirRoot, func(path string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		// don't include path if it's a directory
-		if fi.IsDir() {
-			return nil
-		}
-		// don't include path if it's a symbolic link
-		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
-			return nil
-		}
 
-		path = filepath.ToSlash(path)
-		shortPath := strings.TrimPrefix(path, prefix)
+	w := &fileWalker{
+		root:         root,
+		pathsIgnored: pathsIgnored,
+		policy:       policy,
+		paths:        []string{},
+		links:        []Symlink{},
+	}
+	err = w.walk(root, "", map[string]bool{root: true})
+	return w.paths, w.links, err
+}
 
-		if !strings.HasPrefix(shortPath, "/") {
-			shortPath = "/" + shortPath
+// walk collects the entries of dir, a real path, whose path relative to
+// the root is shortDir. ancestors holds the real paths of the directories
+// being walked, to detect cycles through symbolic links.
+func (w *fileWalker) walk(dir string, shortDir string, ancestors map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		shortPath := shortDir + "/" + e.Name()
+
+		switch {
+		case e.Type()&os.ModeSymlink != 0:
+			if w.policy == SymlinksSkip {
+				continue
+			}
+			link, real := w.resolve(p, shortPath)
+			if w.policy == SymlinksRecord {
+				if !ShouldIgnore(shortPath, w.pathsIgnored) {
+					w.links = append(w.links, link)
+				}
+				continue
+			}
+			if link.Resolved == "" {
+				continue
+			}
+			if !link.IsDir {
+				w.addFile(shortPath)
+				continue
+			}
+			if ancestors[real] {
+				continue
+			}
+			if err := w.walkDir(real, shortPath, ancestors); err != nil {
+				return err
+			}
+		case e.IsDir():
+			if err := w.walkDir(p, shortPath, ancestors); err != nil {
+				return err
+			}
+		default:
+			w.addFile(shortPath)
 		}
+	}
+	return nil
+}
 
-		// don't include path if it should be ignored
-		if pathsIgnored != nil && ShouldIgnore(shortPath, pathsIgnored) {
-			return nil
-		}
+func (w *fileWalker) walkDir(dir string, shortDir string, ancestors map[string]bool) error {
+	ancestors[dir] = true
+	defer delete(ancestors, dir)
+	return w.walk(dir, shortDir, ancestors)
+}
 
-		// if we got here, record the path
-		*paths = append(*paths, shortPath)
-		return nil
-	})
+func (w *fileWalker) addFile(shortPath string) {
+	if w.pathsIgnored != nil && ShouldIgnore(shortPath, w.pathsIgnored) {
+		return
+	}
+	w.paths = append(w.paths, shortPath)
+}
+
+// resolve reads the symbolic link at p, returning it and the real path it
+// resolves to within the root, if any.
+func (w *fileWalker) resolve(p string, shortPath string) (Symlink, string) {
+	link := Symlink{Path: shortPath}
+	link.Target, _ = os.Readlink(p)
 
-	return *paths, err
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		// a dangling link, or a loop of links
+		return link, ""
+	}
+	rel, err := filepath.Rel(w.root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return link, ""
+	}
+	fi, err := os.Stat(real)
+	if err != nil {
+		return link, ""
+	}
+	link.Resolved = "/" + filepath.ToSlash(rel)
+	if rel == "." {
+		link.Resolved = "/"
+	}
+	link.IsDir = fi.IsDir()
+	return link, real
 }
 
 // GetHashesForFilePath takes a path to a file on disk, and returns
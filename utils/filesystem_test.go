@@ -4,6 +4,8 @@ package utils
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -138,6 +140,89 @@ func TestFilesystemCanIgnoreFilesWhenGettingFilePaths(t *testing.T) {
 
 }
 
+// makeSymlinkTestDir creates a directory within a temporary directory,
+// with symbolic links to a file, to a directory, to the directory itself,
+// to a file outside of it and to nothing, and returns its path.
+func makeSymlinkTestDir(t *testing.T) string {
+	t.Helper()
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "root")
+	for p, contents := range map[string]string{
+		"root/a.txt":     "a",
+		"root/sub/b.txt": "b",
+		"outside.txt":    "outside",
+	} {
+		full := filepath.Join(parent, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	for link, target := range map[string]string{
+		"file-link":     "a.txt",
+		"dir-link":      "sub",
+		"sub/loop":      "..",
+		"outside-link":  "../outside.txt",
+		"dangling-link": "missing.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Skipf("cannot create symbolic links: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestFilesystemSkipsSymlinksByDefault(t *testing.T) {
+	dir := makeSymlinkTestDir(t)
+	filePaths, links, err := GetAllFilePathsWithSymlinks(dir, nil, SymlinksSkip)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := []string{"/a.txt", "/sub/b.txt"}
+	if !reflect.DeepEqual(filePaths, want) {
+		t.Errorf("expected %v, got %v", want, filePaths)
+	}
+	if len(links) != 0 {
+		t.Errorf("expected no links, got %v", links)
+	}
+}
+
+func TestFilesystemCanFollowSymlinksWithinRoot(t *testing.T) {
+	dir := makeSymlinkTestDir(t)
+	filePaths, _, err := GetAllFilePathsWithSymlinks(dir, []string{"/dir-link/ignored.txt"}, SymlinksFollow)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// the loop back to the root, and the links outside of it or to nothing,
+	// are not followed
+	want := []string{"/a.txt", "/dir-link/b.txt", "/file-link", "/sub/b.txt"}
+	if !reflect.DeepEqual(filePaths, want) {
+		t.Errorf("expected %v, got %v", want, filePaths)
+	}
+}
+
+func TestFilesystemCanRecordSymlinks(t *testing.T) {
+	dir := makeSymlinkTestDir(t)
+	filePaths, links, err := GetAllFilePathsWithSymlinks(dir, []string{"/dangling-link"}, SymlinksRecord)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if want := []string{"/a.txt", "/sub/b.txt"}; !reflect.DeepEqual(filePaths, want) {
+		t.Errorf("expected %v, got %v", want, filePaths)
+	}
+	want := []Symlink{
+		{Path: "/dir-link", Target: "sub", Resolved: "/sub", IsDir: true},
+		{Path: "/file-link", Target: "a.txt", Resolved: "/a.txt"},
+		{Path: "/outside-link", Target: "../outside.txt"},
+		{Path: "/sub/loop", Target: "..", Resolved: "/", IsDir: true},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("expected %+v, got %+v", want, links)
+	}
+}
+
 // FIXME add test to make sure we get an error for a directory without
 // FIXME appropriate permissions to read its (sub)contents
 
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// SanitizeElementID replaces each character of s that is not permitted in
+// an SPDX identifier (letters, numbers, "." and "-") with a dash.
+func SanitizeElementID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// ElementIDGenerator generates ElementIDs that are unique among those it
+// has generated or reserved.
+type ElementIDGenerator struct {
+	used map[common.ElementID]bool
+}
+
+// NewElementIDGenerator returns an ElementIDGenerator with no IDs in use.
+func NewElementIDGenerator() *ElementIDGenerator {
+	return &ElementIDGenerator{used: map[common.ElementID]bool{}}
+}
+
+// Reserve marks id as in use, returning false if it already was.
+func (g *ElementIDGenerator) Reserve(id common.ElementID) bool {
+	if g.used[id] {
+		return false
+	}
+	g.used[id] = true
+	return true
+}
+
+// Generate joins the non-empty parts with dashes and sanitizes them into
+// an ElementID, as by SanitizeElementID. If that ID is already in use, a
+// suffix "-2", "-3" and so on is added to make it unique.
+func (g *ElementIDGenerator) Generate(parts ...string) common.ElementID {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	base := SanitizeElementID(strings.Join(nonEmpty, "-"))
+	id := common.ElementID(base)
+	for n := 2; !g.Reserve(id); n++ {
+		id = common.ElementID(fmt.Sprintf("%s-%d", base, n))
+	}
+	return id
+}
+
+// NewUUID returns a random (version 4) UUID.
+func NewUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// MakeDocumentNamespace returns a DocumentNamespace made of prefix, the
+// document name and a suffix such as a UUID or checksum, joined by a dash.
+// Each slash-separated segment of the name is escaped, so that the
+// namespace is a valid URI even if the name contains spaces or other
+// characters not permitted in URIs.
+func MakeDocumentNamespace(prefix string, name string, suffix string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return prefix + strings.Join(segments, "/") + "-" + suffix
+}
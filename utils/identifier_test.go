@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package utils
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// ===== Identifier tests =====
+func TestSanitizeElementID(t *testing.T) {
+	if got := SanitizeElementID("@scope/my pkg_1.0+büld"); got != "-scope-my-pkg-1.0-b-ld" {
+		t.Errorf("expected %v, got %v", "-scope-my-pkg-1.0-b-ld", got)
+	}
+}
+
+func TestElementIDGeneratorMakesUniqueIDs(t *testing.T) {
+	g := NewElementIDGenerator()
+	if !g.Reserve("File-a") {
+		t.Errorf("expected first reservation to succeed")
+	}
+	if g.Reserve("File-a") {
+		t.Errorf("expected second reservation to fail")
+	}
+	want := []common.ElementID{"File-a-2", "File-a-3", "File-b-c"}
+	got := []common.ElementID{g.Generate("File", "a"), g.Generate("File", "", "a"), g.Generate("File", "b/c")}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestNewUUIDIsVersion4(t *testing.T) {
+	uuid, err := NewUUID()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid) {
+		t.Errorf("unexpected UUID %v", uuid)
+	}
+	if other, _ := NewUUID(); other == uuid {
+		t.Errorf("expected different UUIDs, got %v twice", uuid)
+	}
+}
+
+func TestMakeDocumentNamespaceEscapesName(t *testing.T) {
+	got := MakeDocumentNamespace("https://example.com/spdx/", "@scope/my pkg", "abc")
+	if got != "https://example.com/spdx/@scope/my%20pkg-abc" {
+		t.Errorf("expected %v, got %v", "https://example.com/spdx/@scope/my%20pkg-abc", got)
+	}
+}
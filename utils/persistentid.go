@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GitBlob identifies the contents of a file as a git blob object, from
+// which its gitoids and its SWHID are formed.
+type GitBlob struct {
+	// SHA1 and SHA256 are the hex hashes of the blob object, as used in
+	// "gitoid:blob:sha1:<SHA1>", "gitoid:blob:sha256:<SHA256>" and
+	// "swh:1:cnt:<SHA1>".
+	SHA1   string
+	SHA256 string
+
+	// Executable records whether any execute permission bit is set, which
+	// determines the file's mode within a git tree.
+	Executable bool
+
+	// Symlink records that the blob is a symbolic link, whose contents are
+	// the link's target.
+	Symlink bool
+}
+
+// GetGitBlobForSymlink returns the git blob for a symbolic link with the
+// given target, which git stores as the blob's contents.
+func GetGitBlobForSymlink(target string) GitBlob {
+	header := fmt.Sprintf("blob %d\x00", len(target))
+	hSHA1 := sha1.Sum([]byte(header + target))
+	hSHA256 := sha256.Sum256([]byte(header + target))
+	return GitBlob{
+		SHA1:    hex.EncodeToString(hSHA1[:]),
+		SHA256:  hex.EncodeToString(hSHA256[:]),
+		Symlink: true,
+	}
+}
+
+// GetGitBlobForFilePath takes a path to a file on disk, and returns its
+// SHA-1 and SHA-256 hashes as a git blob.
+func GetGitBlobForFilePath(p string) (GitBlob, error) {
+	f, err := os.Open(filepath.FromSlash(p))
+	if err != nil {
+		return GitBlob{}, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return GitBlob{}, err
+	}
+
+	// a blob is hashed with a header giving its size
+	header := fmt.Sprintf("blob %d\x00", fi.Size())
+	hSHA1, hSHA256 := sha1.New(), sha256.New()
+	w := io.MultiWriter(hSHA1, hSHA256)
+	io.WriteString(w, header)
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return GitBlob{}, err
+	}
+	if n != fi.Size() {
+		return GitBlob{}, fmt.Errorf("file %s changed size while hashing", p)
+	}
+
+	return GitBlob{
+		SHA1:       hex.EncodeToString(hSHA1.Sum(nil)),
+		SHA256:     hex.EncodeToString(hSHA256.Sum(nil)),
+		Executable: fi.Mode()&0111 != 0,
+	}, nil
+}
+
+// GetGitTreeHashes takes a map from file paths to their git blobs, and
+// returns the SHA-1 git tree hash, which is also the hash in the SWHID
+// "swh:1:dir:<hash>", of each directory containing those files. Paths are
+// slash-separated and relative to a common root, optionally beginning with
+// "/" as returned by GetAllFilePaths. The returned map is keyed by
+// directory path, without leading or trailing slashes, with "" for the
+// root; the root is always present. As in git, directories without files
+// are not represented.
+func GetGitTreeHashes(blobs map[string]GitBlob) (map[string]string, error) {
+	// collect the entries of each directory
+	type entry struct {
+		name  string
+		isDir bool
+		blob  GitBlob
+	}
+	dirs := map[string]map[string]entry{"": {}}
+	for p, blob := range blobs {
+		p = strings.Trim(p, "/")
+		if p == "" || path.Clean(p) != p || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("invalid file path %q", p)
+		}
+		dir, name := path.Split(p)
+		dir = strings.TrimSuffix(dir, "/")
+		if dirs[dir] == nil {
+			dirs[dir] = map[string]entry{}
+		}
+		if _, ok := dirs[dir][name]; ok {
+			return nil, fmt.Errorf("duplicate file path %q", p)
+		}
+		dirs[dir][name] = entry{name: name, blob: blob}
+
+		// record each enclosing directory in its parent
+		for dir != "" {
+			parent, name := path.Split(dir)
+			parent = strings.TrimSuffix(parent, "/")
+			if dirs[parent] == nil {
+				dirs[parent] = map[string]entry{}
+			}
+			if e, ok := dirs[parent][name]; ok {
+				if !e.isDir {
+					return nil, fmt.Errorf("path %q is both a file and a directory", dir)
+				}
+				break
+			}
+			dirs[parent][name] = entry{name: name, isDir: true}
+			dir = parent
+		}
+	}
+
+	// hash directories deepest first, so subtrees are available
+	paths := make([]string, 0, len(dirs))
+	for d := range dirs {
+		paths = append(paths, d)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "/"), strings.Count(paths[j], "/")
+		if paths[i] == "" || paths[j] == "" {
+			return paths[j] == ""
+		}
+		if di != dj {
+			return di > dj
+		}
+		return paths[i] < paths[j]
+	})
+
+	hashes := map[string]string{}
+	for _, d := range paths {
+		entries := make([]entry, 0, len(dirs[d]))
+		for _, e := range dirs[d] {
+			entries = append(entries, e)
+		}
+		// git sorts directories as if their names ended with "/"
+		sortKey := func(e entry) string {
+			if e.isDir {
+				return e.name + "/"
+			}
+			return e.name
+		}
+		sort.Slice(entries, func(i, j int) bool { return sortKey(entries[i]) < sortKey(entries[j]) })
+
+		var buf bytes.Buffer
+		for _, e := range entries {
+			mode, hash := "100644", e.blob.SHA1
+			switch {
+			case e.isDir:
+				mode, hash = "40000", hashes[path.Join(d, e.name)]
+			case e.blob.Symlink:
+				mode = "120000"
+			case e.blob.Executable:
+				mode = "100755"
+			}
+			raw, err := hex.DecodeString(hash)
+			if err != nil || len(raw) != sha1.Size {
+				return nil, fmt.Errorf("invalid SHA1 %q for %s", hash, path.Join(d, e.name))
+			}
+			fmt.Fprintf(&buf, "%s %s\x00", mode, e.name)
+			buf.Write(raw)
+		}
+
+		h := sha1.New()
+		fmt.Fprintf(h, "tree %d\x00", buf.Len())
+		h.Write(buf.Bytes())
+		hashes[d] = hex.EncodeToString(h.Sum(nil))
+	}
+	return hashes, nil
+}
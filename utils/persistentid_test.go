@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0 OR GPL-2.0-or-later
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ===== Persistent identifier tests =====
+func TestPersistentIDCanGetGitBlobForFilePath(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(p, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	blob, err := GetGitBlobForFilePath(p)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// as given by "git hash-object"
+	if blob.SHA1 != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Errorf("expected %v, got %v", "ce013625030ba8dba906f756967f9e9ca394464a", blob.SHA1)
+	}
+	if blob.SHA256 != "2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4" {
+		t.Errorf("expected %v, got %v", "2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4", blob.SHA256)
+	}
+	if blob.Executable {
+		t.Errorf("expected non-executable file")
+	}
+}
+
+func TestPersistentIDGitBlobFailsWithInvalidFilePath(t *testing.T) {
+	if _, err := GetGitBlobForFilePath("./oops/nonexistent"); err == nil {
+		t.Errorf("expected non-nil error, got nil")
+	}
+}
+
+func TestPersistentIDCanGetGitTreeHashes(t *testing.T) {
+	dirRoot := "../testdata/project1/"
+	filePaths, err := GetAllFilePaths(dirRoot, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	blobs := map[string]GitBlob{}
+	for _, p := range filePaths {
+		if blobs[p], err = GetGitBlobForFilePath(filepath.Join(dirRoot, p)); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		// modes in a checkout may vary; the tree hashes below assume 0644
+		blobs[p] = GitBlob{SHA1: blobs[p].SHA1, SHA256: blobs[p].SHA256}
+	}
+
+	hashes, err := GetGitTreeHashes(blobs)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// as given by "git write-tree"
+	if hashes[""] != "25a3213da89744785a56c2fd5745563fa3904b48" {
+		t.Errorf("expected %v, got %v", "25a3213da89744785a56c2fd5745563fa3904b48", hashes[""])
+	}
+	if hashes["folder1"] != "3e499ae765e4720193b180281ac92dfb0f3c4172" {
+		t.Errorf("expected %v, got %v", "3e499ae765e4720193b180281ac92dfb0f3c4172", hashes["folder1"])
+	}
+}
+
+func TestPersistentIDGitTreeHashesSortAndRecordModes(t *testing.T) {
+	hello := GitBlob{SHA1: "ce013625030ba8dba906f756967f9e9ca394464a"}
+	exec := hello
+	exec.Executable = true
+
+	hashes, err := GetGitTreeHashes(map[string]GitBlob{
+		"run.sh":  exec,
+		"a.txt":   hello,
+		"a/b.txt": hello,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if hashes[""] != "b9c945c42fecd871aef4015e3afb3dbb28d4f31c" {
+		t.Errorf("expected %v, got %v", "b9c945c42fecd871aef4015e3afb3dbb28d4f31c", hashes[""])
+	}
+
+	hashes, err = GetGitTreeHashes(map[string]GitBlob{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if hashes[""] != "4b825dc642cb6eb9a060e54bf8d69288fbee4904" {
+		t.Errorf("expected empty tree, got %v", hashes[""])
+	}
+}
+
+func TestPersistentIDGitTreeHashesFailForInvalidPaths(t *testing.T) {
+	hello := GitBlob{SHA1: "ce013625030ba8dba906f756967f9e9ca394464a"}
+	for _, blobs := range []map[string]GitBlob{
+		{"../x": hello},
+		{"a/./b": hello},
+		{"a": hello, "a/b": hello},
+		{"a": {SHA1: "oops"}},
+	} {
+		if _, err := GetGitTreeHashes(blobs); err == nil {
+			t.Errorf("expected non-nil error for %v, got nil", blobs)
+		}
+	}
+}
@@ -7,11 +7,33 @@ import (
 
 	"sigs.k8s.io/yaml"
 
+	"github.com/spdx/tools-golang/convert"
 	"github.com/spdx/tools-golang/spdx/common"
 )
 
+// WriteOption configures Write; see convert.WriteConfig.
+type WriteOption = convert.WriteOption
+
+// TargetVersion converts the document to the given SPDX version, such as
+// "SPDX-2.2", before writing it, as by convert.ToVersion.
+func TargetVersion(version string) WriteOption {
+	return convert.TargetVersion(version)
+}
+
+// DroppedFields calls report with the fields of the document dropped by
+// TargetVersion because the target version cannot represent them, if
+// there are any.
+func DroppedFields(report func(fields []string)) WriteOption {
+	return convert.DroppedFields(report)
+}
+
 // Write takes an SPDX Document and an io.Writer, and writes the document to the writer in YAML format.
-func Write(doc common.AnyDocument, w io.Writer) error {
+func Write(doc common.AnyDocument, w io.Writer, opts ...WriteOption) error {
+	doc, err := convert.ApplyWriteOptions(doc, opts...)
+	if err != nil {
+		return err
+	}
+
 	buf, err := yaml.Marshal(doc)
 	if err != nil {
 		return err
@@ -0,0 +1,35 @@
+package yaml_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	spdx "github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"github.com/spdx/tools-golang/yaml"
+)
+
+func Test_WriteTargetVersion(t *testing.T) {
+	doc := spdx.Document{
+		SPDXVersion:  spdx.Version,
+		DocumentName: "test_doc",
+		Packages: []*spdx.Package{
+			{PackageName: "p", PackageSPDXIdentifier: "p", PrimaryPackagePurpose: "LIBRARY"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	var dropped []string
+	err := yaml.Write(doc, buf, yaml.TargetVersion("SPDX-2.2"), yaml.DroppedFields(func(fields []string) {
+		dropped = fields
+	}))
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "spdxVersion: SPDX-2.2\n")
+	assert.Contains(t, buf.String(), "name: p\n")
+	assert.NotContains(t, buf.String(), "LIBRARY")
+	assert.Equal(t, []string{"packages[0].primaryPackagePurpose"}, dropped)
+
+	err = yaml.Write(doc, buf, yaml.TargetVersion("SPDX-9.9"))
+	assert.Error(t, err)
+}